@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"go_web/internal/config"
+	"go_web/internal/logger"
+	"go_web/internal/repository"
+	"go_web/internal/service"
+	"go_web/pkg/dig"
+)
+
+const auditUsage = `用法:
+  server audit export  [--format csv|ndjson] [--output 文件] [--table 表名] [--record-id ID]
+                       [--action 操作] [--user-id ID] [--actor 来源] [--request-id ID] [--start 时间] [--end 时间]
+  server audit archive [--days 保留天数]`
+
+// runAuditCommand 审计日志相关命令
+func runAuditCommand(container *dig.Container, args []string) error {
+	if len(args) == 0 {
+		return errors.New(auditUsage)
+	}
+
+	switch args[0] {
+	case "export":
+		return runAuditExport(container, args[1:])
+	case "archive":
+		return runAuditArchive(container, args[1:])
+	default:
+		return fmt.Errorf("未知的 audit 子命令: %s\n%s", args[0], auditUsage)
+	}
+}
+
+// runAuditExport 导出审计日志到文件或标准输出
+func runAuditExport(container *dig.Container, args []string) error {
+	fs := flag.NewFlagSet("audit export", flag.ContinueOnError)
+	format := fs.String("format", service.ExportFormatCSV, "导出格式（csv/ndjson）")
+	output := fs.String("output", "-", "输出文件路径，- 表示标准输出")
+	tableName := fs.String("table", "", "表名")
+	recordID := fs.Uint("record-id", 0, "记录ID")
+	action := fs.String("action", "", "操作类型（create/update/delete）")
+	userID := fs.Uint("user-id", 0, "操作者用户ID")
+	actor := fs.String("actor", "", "操作来源（http/cli）")
+	requestID := fs.String("request-id", "", "HTTP 请求ID")
+	start := fs.String("start", "", "起始时间（RFC3339 或 2006-01-02，包含）")
+	end := fs.String("end", "", "结束时间（RFC3339 或 2006-01-02，不包含）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	filter := repository.AuditLogFilter{
+		TableName: *tableName,
+		RecordID:  *recordID,
+		Action:    *action,
+		UserID:    *userID,
+		Actor:     *actor,
+		RequestID: *requestID,
+	}
+	var err error
+	if filter.StartTime, err = service.ParseFilterTime(*start); err != nil {
+		return err
+	}
+	if filter.EndTime, err = service.ParseFilterTime(*end); err != nil {
+		return err
+	}
+
+	return container.Invoke(func(log *logger.Logger, auditLogService service.AuditLogService) error {
+		var w io.Writer = os.Stdout
+		if *output == "-" {
+			// 导出内容占用标准输出，日志改为输出到标准错误
+			log.SetOutput(os.Stderr)
+		} else {
+			f, err := os.Create(*output)
+			if err != nil {
+				return fmt.Errorf("创建导出文件失败: %v", err)
+			}
+			defer f.Close()
+			w = f
+		}
+
+		if err := auditLogService.ExportAuditLogs(commandContext(), filter, *format, w); err != nil {
+			return fmt.Errorf("导出审计日志失败: %v", err)
+		}
+		if *output != "-" {
+			log.Infof("审计日志已导出到 %s", *output)
+		}
+		return nil
+	})
+}
+
+// runAuditArchive 归档并删除过期的审计日志
+func runAuditArchive(container *dig.Container, args []string) error {
+	return container.Invoke(func(cfg *config.Config, log *logger.Logger, auditLogService service.AuditLogService) error {
+		fs := flag.NewFlagSet("audit archive", flag.ContinueOnError)
+		days := fs.Int("days", cfg.Audit.RetentionDays, "保留天数，早于该天数的审计日志会被归档并删除")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+
+		result, err := auditLogService.ArchiveAuditLogs(commandContext(), *days)
+		if err != nil {
+			return fmt.Errorf("归档审计日志失败: %v", err)
+		}
+		if result.Count == 0 {
+			log.Infof("没有早于 %s 的审计日志需要归档", result.Cutoff.Format(time.DateTime))
+			return nil
+		}
+		log.Infof("已归档 %d 条审计日志到 %s，删除 %d 条", result.Count, result.File, result.Deleted)
+		return nil
+	})
+}
+
+// runAuditArchiver 按配置的间隔在服务内定期归档审计日志
+func runAuditArchiver(ctx context.Context, cfg *config.Config, log *logger.Logger, auditLogService service.AuditLogService) {
+	ticker := time.NewTicker(time.Duration(cfg.Audit.ArchiveInterval) * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := auditLogService.ArchiveAuditLogs(ctx, cfg.Audit.RetentionDays)
+			if err != nil {
+				log.Errorf("自动归档审计日志失败: %v", err)
+				continue
+			}
+			if result.Count > 0 {
+				log.Infof("已自动归档 %d 条审计日志到 %s", result.Count, result.File)
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"go_web/internal/config"
+	"go_web/pkg/dig"
+)
+
+const configUsage = `用法:
+  server [全局参数] config print    [--format yaml|toml]
+  server [全局参数] config validate
+  server [全局参数] config encrypt   从标准输入读取明文，使用主密钥（CONFIG_MASTER_KEY）加密为 enc: 引用`
+
+// runConfigCommand 配置相关命令
+func runConfigCommand(container *dig.Container, args []string) error {
+	if len(args) == 0 {
+		return errors.New(configUsage)
+	}
+
+	switch args[0] {
+	case "print":
+		return runConfigPrint(container, args[1:])
+	case "validate":
+		// 配置在创建容器前已完成加载和校验，能执行到这里说明配置有效
+		fmt.Println("配置有效")
+		return nil
+	case "encrypt":
+		return runConfigEncrypt()
+	default:
+		return fmt.Errorf("未知的 config 子命令: %s\n%s", args[0], configUsage)
+	}
+}
+
+// runConfigPrint 输出最终生效的配置（敏感字段已脱敏）
+func runConfigPrint(container *dig.Container, args []string) error {
+	fs := flag.NewFlagSet("config print", flag.ContinueOnError)
+	format := fs.String("format", "yaml", "输出格式（yaml/toml）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	return container.Invoke(func(cfg *config.Config) error {
+		return cfg.Print(os.Stdout, *format)
+	})
+}
+
+// runConfigEncrypt 加密从标准输入读取的明文，输出可写入配置的 enc: 引用
+// 明文不通过命令行参数传入，避免留在 shell 历史中
+func runConfigEncrypt() error {
+	reader := bufio.NewReader(os.Stdin)
+	plaintext, err := reader.ReadString('\n')
+	if err != nil && plaintext == "" {
+		return fmt.Errorf("读取明文失败: %v", err)
+	}
+
+	encrypted, err := config.EncryptSecret(strings.TrimRight(plaintext, "\r\n"))
+	if err != nil {
+		return fmt.Errorf("加密失败: %v", err)
+	}
+	fmt.Println(encrypted)
+	return nil
+}
@@ -19,28 +19,52 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"go_web/docs/swagger" // Swagger 文档
 	"go_web/internal/config"
+	"go_web/internal/database"
 	"go_web/internal/logger"
+	"go_web/internal/service"
 	"go_web/pkg/dig"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 func main() {
 	// 初始化 Swagger 文档（导入 docs/swagger 包会自动执行 init 函数）
 	_ = swagger.SwaggerInfo
 
+	// 解析全局参数（需写在子命令之前），如 --config、--port
+	opts := config.BindFlags(flag.CommandLine)
+	flag.Usage = usage
+	flag.Parse()
+
+	// 加载配置，校验失败时列出全部问题并退出
+	cfg, err := config.LoadConfig(*opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
 	// 创建依赖注入容器
-	container := dig.NewContainer()
+	container := dig.NewContainer(config.NewManager(cfg, *opts))
+
+	// 带参数时执行命令行子命令（serve 与不带参数相同，启动服务）
+	if args := flag.Args(); len(args) > 0 && args[0] != "serve" {
+		if err := runCommand(container, args[0], args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	// 启动服务
 	if err := container.Invoke(startServer); err != nil {
@@ -48,25 +72,104 @@ func main() {
 	}
 }
 
+// runCommand 执行命令行子命令，结束前刷新审计队列
+func runCommand(container *dig.Container, name string, args []string) error {
+	var err error
+	switch name {
+	case "audit":
+		err = runAuditCommand(container, args)
+	case "config":
+		err = runConfigCommand(container, args)
+	case "migrate":
+		err = runMigrateCommand(container, args)
+	case "seed":
+		err = runSeedCommand(container, args)
+	case "user":
+		err = runUserCommand(container, args)
+	case "role":
+		err = runRoleCommand(container, args)
+	case "permission":
+		err = runPermissionCommand(container, args)
+	case "token":
+		err = runTokenCommand(container, args)
+	default:
+		return fmt.Errorf("未知命令: %s", name)
+	}
+
+	// 刷新审计队列中剩余的日志（写入器未启动时直接返回）
+	_ = container.Invoke(func(auditWriter *database.AuditWriter) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = auditWriter.Close(ctx)
+	})
+
+	return err
+}
+
+// commandContext 子命令使用的 context，数据库写入的审计日志操作来源记为 cli
+func commandContext() context.Context {
+	return context.WithValue(context.Background(), database.AuditActorKey, database.AuditActorCLI)
+}
+
+// usage 打印命令行用法
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintln(out, `用法:
+  server [全局参数] [serve]             启动服务
+  server [全局参数] audit <子命令>       审计日志导出与归档
+  server [全局参数] config <子命令>      查看和校验配置
+  server [全局参数] migrate <子命令>     数据库迁移
+  server [全局参数] seed [参数]          同步权限、角色和初始管理员
+  server [全局参数] user <子命令>        创建、禁用用户和重置密码
+  server [全局参数] role <子命令>        授予和撤销角色的用户与权限
+  server [全局参数] permission <子命令>  查看权限
+  server [全局参数] token <子命令>       签发 JWT Token
+
+全局参数:`)
+	flag.PrintDefaults()
+}
+
 func startServer(
 	cfg *config.Config,
+	configManager *config.Manager,
 	log *logger.Logger,
-	db *gorm.DB,
+	migrator *database.Migrator,
+	auditWriter *database.AuditWriter,
+	auditLogService service.AuditLogService,
 	r *gin.Engine,
 ) error {
 	// Gin模式已在router.SetupRouter中设置
 
-	// 初始化数据库表（只在配置允许时执行）
-	// 生产环境应设置 DB_AUTO_MIGRATE=false，使用专门的迁移工具
+	// 执行数据库迁移（只在配置允许时执行），否则检查数据库结构是否为最新
+	// 生产环境应设置 DB_AUTO_MIGRATE=false，在发布流程中执行 server migrate up
 	if cfg.Database.AutoMigrate {
-		if err := dig.InitializeDatabase(db, cfg); err != nil {
-			return fmt.Errorf("初始化数据库失败: %v", err)
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			return fmt.Errorf("执行数据库迁移失败: %v", err)
+		}
+		for _, migration := range applied {
+			log.Infof("已执行数据库迁移 %d_%s", migration.Version, migration.Name)
 		}
-		log.Info("数据库表初始化成功！")
-	} else {
-		log.Info("跳过数据库自动迁移（生产环境模式，请使用专门的迁移工具）")
+	} else if err := migrator.Check(context.Background()); err != nil {
+		return fmt.Errorf("%v，请先执行 server migrate up", err)
 	}
 
+	// 定期归档过期的审计日志（只在配置了保留天数和归档间隔时执行）
+	archiveCtx, stopArchiver := context.WithCancel(context.Background())
+	defer stopArchiver()
+	if cfg.Audit.RetentionDays > 0 && cfg.Audit.ArchiveInterval > 0 {
+		go runAuditArchiver(archiveCtx, cfg, log, auditLogService)
+	}
+
+	// 监听配置文件变更和 SIGHUP 信号，热更新运行时安全的配置
+	reloadCtx, stopReload := context.WithCancel(context.Background())
+	defer stopReload()
+	go func() {
+		if err := configManager.Watch(reloadCtx, logConfigReload(log)); err != nil {
+			log.Errorf("配置热更新监听失败: %v", err)
+		}
+	}()
+
 	// 创建HTTP服务器
 	srv := &http.Server{
 		Addr:    cfg.Server.Host + ":" + cfg.Server.Port,
@@ -87,16 +190,46 @@ func startServer(
 	<-quit
 
 	log.Info("正在关闭服务器...")
+	stopArchiver()
+	stopReload()
 
 	// 设置5秒的超时时间用于关闭服务器
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("服务器强制关闭: %v", err)
+		log.Errorf("服务器强制关闭: %v", err)
 	}
 
+	// 刷新审计队列中剩余的日志（使用独立的超时，避免服务器关闭超时后来不及写入）
+	flushCtx, flushCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer flushCancel()
+
+	if err := auditWriter.Close(flushCtx); err != nil {
+		log.Errorf("审计日志刷新超时: %v", err)
+	}
+	stats := auditWriter.Stats()
+	log.Infof("审计日志已刷新（写入 %d 条，丢弃 %d 条，失败 %d 条）", stats.Written, stats.Dropped, stats.Failed)
+
 	log.Info("服务器已退出！")
 
 	return nil
 }
+
+// logConfigReload 记录配置热更新结果
+func logConfigReload(log *logger.Logger) func(result *config.ReloadResult, err error) {
+	return func(result *config.ReloadResult, err error) {
+		if err != nil {
+			log.Errorf("重新加载配置失败，继续使用原配置: %v", err)
+			return
+		}
+		for _, key := range result.Rejected {
+			log.Warnf("配置项 %s 不支持热更新，已忽略，需要重启服务才能生效", key)
+		}
+		if len(result.Applied) > 0 {
+			log.Infof("配置已热更新: %s", strings.Join(result.Applied, ", "))
+		} else if len(result.Rejected) == 0 {
+			log.Info("重新加载配置完成，没有变化")
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"go_web/internal/database"
+	"go_web/internal/logger"
+	"go_web/pkg/dig"
+)
+
+const migrateUsage = `用法:
+  server migrate up          执行全部未执行的迁移
+  server migrate down [N]    回滚最近执行的 N 个迁移（默认 1）
+  server migrate to <版本>   迁移到指定版本（高于当前版本时升级，低于时回滚，0 表示回滚全部）
+  server migrate status      查看迁移状态`
+
+// runMigrateCommand 数据库迁移相关命令
+func runMigrateCommand(container *dig.Container, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	return container.Invoke(func(log *logger.Logger, migrator *database.Migrator) error {
+		ctx := commandContext()
+		switch args[0] {
+		case "up":
+			applied, err := migrator.Up(ctx)
+			logMigrations(log, "已执行", applied)
+			if err == nil && len(applied) == 0 {
+				log.Info("数据库结构已是最新")
+			}
+			return err
+		case "down":
+			steps := 1
+			if len(args) > 1 {
+				n, err := strconv.Atoi(args[1])
+				if err != nil || n <= 0 {
+					return fmt.Errorf("无效的回滚数量: %s", args[1])
+				}
+				steps = n
+			}
+			reverted, err := migrator.Down(ctx, steps)
+			logMigrations(log, "已回滚", reverted)
+			return err
+		case "to":
+			if len(args) < 2 {
+				return errors.New(migrateUsage)
+			}
+			version, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil || version < 0 {
+				return fmt.Errorf("无效的版本号: %s", args[1])
+			}
+			done, err := migrator.To(ctx, version)
+			for _, migration := range done {
+				// 高于目标版本的是回滚，其余是升级
+				if migration.Version > version {
+					logMigrations(log, "已回滚", []database.Migration{migration})
+				} else {
+					logMigrations(log, "已执行", []database.Migration{migration})
+				}
+			}
+			return err
+		case "status":
+			return printMigrationStatus(ctx, migrator)
+		default:
+			return fmt.Errorf("未知的 migrate 子命令: %s\n%s", args[0], migrateUsage)
+		}
+	})
+}
+
+// logMigrations 输出本次执行或回滚的迁移（出错时也会输出已完成的部分）
+func logMigrations(log *logger.Logger, verb string, migrations []database.Migration) {
+	for _, migration := range migrations {
+		log.Infof("%s迁移 %d_%s", verb, migration.Version, migration.Name)
+	}
+}
+
+// printMigrationStatus 以表格形式输出迁移状态
+func printMigrationStatus(ctx context.Context, migrator *database.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "版本\t名称\t状态\t执行时间")
+	for _, status := range statuses {
+		state, appliedAt := "未执行", ""
+		switch {
+		case status.Dirty:
+			state = "未完成"
+		case status.Unknown:
+			state = "已执行（程序中不存在）"
+		case status.Applied:
+			state = "已执行"
+		}
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Local().Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// minPasswordLength 密码最小长度（与创建用户接口一致）
+const minPasswordLength = 6
+
+// readPassword 获取用户密码：指定时自动生成或从标准输入读取；
+// 否则在终端中提示输入（留空自动生成），非终端环境自动生成
+func readPassword(email string, generate, fromStdin bool) (password string, generated bool, err error) {
+	switch {
+	case generate:
+		password, err = generatePassword()
+		return password, true, err
+	case fromStdin:
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", false, fmt.Errorf("读取密码失败: %v", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	case isTerminal(os.Stdin):
+		if password, err = promptPassword(email); err != nil {
+			return "", false, err
+		}
+		if password == "" {
+			password, err = generatePassword()
+			return password, true, err
+		}
+	default:
+		password, err = generatePassword()
+		return password, true, err
+	}
+
+	if len(password) < minPasswordLength {
+		return "", false, fmt.Errorf("密码不能少于 %d 位", minPasswordLength)
+	}
+	return password, false, nil
+}
+
+// promptPassword 在终端中关闭回显后输入两次密码
+func promptPassword(email string) (string, error) {
+	// 关闭终端回显（stty 不可用时仍可输入，只是会显示明文）
+	if err := stty("-echo"); err == nil {
+		defer stty("echo")
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	read := func(prompt string) (string, error) {
+		fmt.Fprint(os.Stderr, prompt)
+		line, err := reader.ReadString('\n')
+		fmt.Fprintln(os.Stderr)
+		if err != nil && line == "" {
+			return "", fmt.Errorf("读取密码失败: %v", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	password, err := read(fmt.Sprintf("请输入用户 %s 的密码（留空自动生成）: ", email))
+	if err != nil || password == "" {
+		return "", err
+	}
+	confirm, err := read("请再次输入密码: ")
+	if err != nil {
+		return "", err
+	}
+	if password != confirm {
+		return "", errors.New("两次输入的密码不一致")
+	}
+	return password, nil
+}
+
+// generatePassword 生成 24 位随机密码
+func generatePassword() (string, error) {
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("生成密码失败: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// isTerminal 判断标准输入是否为终端（/dev/null 也是字符设备，需要再用 stty 确认）
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0 && stty("-g") == nil
+}
+
+func stty(arg string) error {
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"go_web/internal/logger"
+	"go_web/internal/model"
+	"go_web/internal/query"
+	"go_web/internal/service"
+	"go_web/pkg/dig"
+)
+
+const permissionUsage = `用法:
+  server permission list [--role 角色 | --user 邮箱]`
+
+// runPermissionCommand 权限相关命令
+func runPermissionCommand(container *dig.Container, args []string) error {
+	if len(args) == 0 {
+		return errors.New(permissionUsage)
+	}
+
+	switch args[0] {
+	case "list":
+		return runPermissionList(container, args[1:])
+	default:
+		return fmt.Errorf("未知的 permission 子命令: %s\n%s", args[0], permissionUsage)
+	}
+}
+
+// runPermissionList 列出全部权限、角色的权限或用户通过角色获得的权限
+func runPermissionList(container *dig.Container, args []string) error {
+	fs := flag.NewFlagSet("permission list", flag.ContinueOnError)
+	roleName := fs.String("role", "", "只列出该角色的权限")
+	email := fs.String("user", "", "列出该用户通过角色获得的权限")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *roleName != "" && *email != "" {
+		return errors.New("--role 和 --user 不能同时使用")
+	}
+
+	// 列表占用标准输出，日志改为输出到标准错误
+	if err := container.Invoke(func(log *logger.Logger) { log.SetOutput(os.Stderr) }); err != nil {
+		return err
+	}
+
+	return container.Invoke(func(
+		userService service.UserService,
+		roleService service.RoleService,
+		permissionService service.PermissionService,
+	) error {
+		ctx := commandContext()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+		switch {
+		case *email != "":
+			user, err := findUser(ctx, userService, *email)
+			if err != nil {
+				return err
+			}
+			permissions, sources, err := userPermissions(ctx, userService, roleService, user.ID)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(w, "名称\t显示名称\t状态\t来源角色")
+			for _, p := range permissions {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Name, p.DisplayName, statusText(p.Status), strings.Join(sources[p.ID], ", "))
+			}
+		case *roleName != "":
+			roles, err := findRoles(ctx, roleService, []string{*roleName})
+			if err != nil {
+				return err
+			}
+			permissions, err := roleService.GetRolePermissions(ctx, roles[0].ID)
+			if err != nil {
+				return fmt.Errorf("查询角色权限失败: %v", err)
+			}
+			sortPermissions(permissions)
+			printPermissions(w, permissions)
+		default:
+			var permissions []*model.Permission
+			page := query.Page{Size: query.MaxPageSize, Keyset: true}
+			for {
+				batch, info, err := permissionService.ListPermissions(ctx, nil, page)
+				if err != nil {
+					return fmt.Errorf("查询权限失败: %v", err)
+				}
+				permissions = append(permissions, batch...)
+				if info.NextCursor == "" {
+					break
+				}
+				page.Cursor = info.NextCursor
+			}
+			sortPermissions(permissions)
+			printPermissions(w, permissions)
+		}
+
+		return w.Flush()
+	})
+}
+
+// userPermissions 汇总用户各角色的权限，返回权限及其来源角色（已禁用的角色会标注）
+func userPermissions(ctx context.Context, userService service.UserService, roleService service.RoleService, userID uint) ([]*model.Permission, map[uint][]string, error) {
+	roles, err := userService.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("查询用户角色失败: %v", err)
+	}
+
+	var permissions []*model.Permission
+	sources := make(map[uint][]string)
+	for _, role := range roles {
+		rolePermissions, err := roleService.GetRolePermissions(ctx, role.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("查询角色权限失败: %v", err)
+		}
+		source := role.Name
+		if role.Status != 1 {
+			source += "（已禁用）"
+		}
+		for _, p := range rolePermissions {
+			if _, ok := sources[p.ID]; !ok {
+				permissions = append(permissions, p)
+			}
+			sources[p.ID] = append(sources[p.ID], source)
+		}
+	}
+
+	sortPermissions(permissions)
+	return permissions, sources, nil
+}
+
+func printPermissions(w *tabwriter.Writer, permissions []*model.Permission) {
+	fmt.Fprintln(w, "ID\t名称\t显示名称\t状态")
+	for _, p := range permissions {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", p.ID, p.Name, p.DisplayName, statusText(p.Status))
+	}
+}
+
+func sortPermissions(permissions []*model.Permission) {
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i].ID < permissions[j].ID })
+}
+
+func statusText(status int) string {
+	if status == 1 {
+		return "启用"
+	}
+	return "禁用"
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"go_web/internal/logger"
+	"go_web/internal/model"
+	"go_web/internal/service"
+	"go_web/pkg/dig"
+
+	"gorm.io/gorm"
+)
+
+const roleUsage = `用法:
+  server role grant  <角色> [--users 邮箱,...] [--permissions 权限,...]
+  server role revoke <角色> [--users 邮箱,...] [--permissions 权限,...]`
+
+// runRoleCommand 角色授权相关命令
+func runRoleCommand(container *dig.Container, args []string) error {
+	if len(args) == 0 {
+		return errors.New(roleUsage)
+	}
+
+	switch args[0] {
+	case "grant":
+		return runRoleGrant(container, args[1:], true)
+	case "revoke":
+		return runRoleGrant(container, args[1:], false)
+	default:
+		return fmt.Errorf("未知的 role 子命令: %s\n%s", args[0], roleUsage)
+	}
+}
+
+// runRoleGrant 将角色授予用户、将权限授予角色（grant 为 false 时撤销）
+func runRoleGrant(container *dig.Container, args []string, grant bool) error {
+	roleName, args := splitPositional(args)
+	fs := flag.NewFlagSet("role", flag.ContinueOnError)
+	users := fs.String("users", "", "用户邮箱，多个用逗号分隔")
+	permissions := fs.String("permissions", "", "权限名称，多个用逗号分隔")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if roleName == "" || fs.NArg() > 0 {
+		return errors.New(roleUsage)
+	}
+	emails, permissionNames := splitList(*users), splitList(*permissions)
+	if len(emails) == 0 && len(permissionNames) == 0 {
+		return errors.New("--users 和 --permissions 至少指定一个")
+	}
+
+	return container.Invoke(func(
+		log *logger.Logger,
+		userService service.UserService,
+		roleService service.RoleService,
+		permissionService service.PermissionService,
+	) error {
+		ctx := commandContext()
+		roles, err := findRoles(ctx, roleService, []string{roleName})
+		if err != nil {
+			return err
+		}
+		role := roles[0]
+
+		// 先解析全部用户和权限，任何一个不存在都不做修改
+		userIDs := make([]uint, 0, len(emails))
+		for _, email := range emails {
+			user, err := findUser(ctx, userService, email)
+			if err != nil {
+				return err
+			}
+			userIDs = append(userIDs, user.ID)
+		}
+		permissionIDs := make([]uint, 0, len(permissionNames))
+		for _, name := range permissionNames {
+			permission, err := permissionService.GetPermissionByName(ctx, name)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("权限不存在: %s", name)
+			}
+			if err != nil {
+				return fmt.Errorf("查询权限失败: %v", err)
+			}
+			permissionIDs = append(permissionIDs, permission.ID)
+		}
+
+		if grant {
+			if len(userIDs) > 0 {
+				if err := roleService.AssignUsers(ctx, role.ID, userIDs); err != nil {
+					return fmt.Errorf("授予角色失败: %v", err)
+				}
+				log.Infof("已将角色 %s 授予用户 %s", role.Name, strings.Join(emails, ", "))
+			}
+			if len(permissionIDs) > 0 {
+				if err := roleService.AssignPermissions(ctx, role.ID, permissionIDs); err != nil {
+					return fmt.Errorf("授予权限失败: %v", err)
+				}
+				log.Infof("已将权限 %s 授予角色 %s", strings.Join(permissionNames, ", "), role.Name)
+			}
+			return nil
+		}
+
+		if len(userIDs) > 0 {
+			if err := roleService.RemoveUsers(ctx, role.ID, userIDs); err != nil {
+				return fmt.Errorf("撤销角色失败: %v", err)
+			}
+			log.Infof("已撤销用户 %s 的角色 %s", strings.Join(emails, ", "), role.Name)
+		}
+		if len(permissionIDs) > 0 {
+			if err := roleService.RemovePermissions(ctx, role.ID, permissionIDs); err != nil {
+				return fmt.Errorf("撤销权限失败: %v", err)
+			}
+			log.Infof("已撤销角色 %s 的权限 %s", role.Name, strings.Join(permissionNames, ", "))
+		}
+		return nil
+	})
+}
+
+// findRoles 按名称查找角色，任何一个不存在时返回错误
+func findRoles(ctx context.Context, roleService service.RoleService, names []string) ([]*model.Role, error) {
+	roles := make([]*model.Role, 0, len(names))
+	for _, name := range names {
+		role, err := roleService.GetRoleByName(ctx, name)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("角色不存在: %s", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("查询角色失败: %v", err)
+		}
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"go_web/internal/database"
+	"go_web/internal/logger"
+	"go_web/pkg/dig"
+)
+
+const seedUsage = `用法:
+  server seed [--file 文件] [--dry-run] [--prune] [--reset-admin-password]
+              [--generate-password | --password-stdin]`
+
+// runSeedCommand 按种子数据文件同步权限、角色、角色权限和初始管理员
+func runSeedCommand(container *dig.Container, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	file := fs.String("file", "sql/seed.yaml", "种子数据文件（YAML）")
+	dryRun := fs.Bool("dry-run", false, "只输出变更，不写入数据库")
+	prune := fs.Bool("prune", false, "删除文件中未声明的权限、角色，以及已声明角色和管理员上多余的关联")
+	resetPassword := fs.Bool("reset-admin-password", false, "管理员已存在时重置密码")
+	generatePassword := fs.Bool("generate-password", false, "自动生成管理员密码（不提示输入）")
+	passwordStdin := fs.Bool("password-stdin", false, "从标准输入读取管理员密码")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), seedUsage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *generatePassword && *passwordStdin {
+		return errors.New("--generate-password 和 --password-stdin 不能同时使用")
+	}
+
+	data, err := database.LoadSeedData(*file)
+	if err != nil {
+		return err
+	}
+
+	return container.Invoke(func(log *logger.Logger, seeder *database.Seeder) error {
+		ctx := commandContext()
+		opts := database.SeedOptions{
+			DryRun:             true,
+			Prune:              *prune,
+			ResetAdminPassword: *resetPassword,
+		}
+
+		changes, err := seeder.Sync(ctx, data, opts)
+		if err != nil {
+			return fmt.Errorf("读取数据库失败: %v", err)
+		}
+		if len(changes) == 0 {
+			log.Info("种子数据已是最新")
+			return nil
+		}
+		if *dryRun {
+			printSeedChanges(changes)
+			return nil
+		}
+
+		// 只在需要创建管理员或重置密码时获取密码
+		generated := false
+		for _, change := range changes {
+			if change.NeedsPassword {
+				opts.AdminPassword, generated, err = readPassword(data.Admin.Email, *generatePassword, *passwordStdin)
+				if err != nil {
+					return err
+				}
+				break
+			}
+		}
+
+		opts.DryRun = false
+		changes, err = seeder.Sync(ctx, data, opts)
+		if err != nil {
+			return fmt.Errorf("写入种子数据失败: %v", err)
+		}
+		printSeedChanges(changes)
+		if generated {
+			fmt.Printf("已生成管理员 %s 的密码（只显示一次，请妥善保存）: %s\n", data.Admin.Email, opts.AdminPassword)
+		}
+		return nil
+	})
+}
+
+// printSeedChanges 以 diff 的形式输出变更和汇总
+func printSeedChanges(changes []database.SeedChange) {
+	counts := make(map[string]int)
+	for _, change := range changes {
+		fmt.Println(change)
+		counts[change.Action]++
+	}
+	fmt.Printf("共 %d 项变更（新增 %d，更新 %d，删除 %d）\n", len(changes),
+		counts[database.SeedActionCreate], counts[database.SeedActionUpdate], counts[database.SeedActionDelete])
+}
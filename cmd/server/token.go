@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"go_web/internal/config"
+	"go_web/internal/logger"
+	"go_web/internal/service"
+	"go_web/internal/util"
+	"go_web/pkg/dig"
+)
+
+const tokenUsage = `用法:
+  server token issue <邮箱> [--ttl 有效期]`
+
+// runTokenCommand Token 相关命令
+func runTokenCommand(container *dig.Container, args []string) error {
+	if len(args) == 0 {
+		return errors.New(tokenUsage)
+	}
+
+	switch args[0] {
+	case "issue":
+		return runTokenIssue(container, args[1:])
+	default:
+		return fmt.Errorf("未知的 token 子命令: %s\n%s", args[0], tokenUsage)
+	}
+}
+
+// runTokenIssue 为用户签发 JWT Token，供脚本和自动化任务调用接口，Token 输出到标准输出
+func runTokenIssue(container *dig.Container, args []string) error {
+	email, args := splitPositional(args)
+
+	// Token 占用标准输出，日志改为输出到标准错误（需在连接数据库前设置）
+	if err := container.Invoke(func(log *logger.Logger) { log.SetOutput(os.Stderr) }); err != nil {
+		return err
+	}
+
+	return container.Invoke(func(cfg *config.Config, log *logger.Logger, userService service.UserService) error {
+		fs := flag.NewFlagSet("token issue", flag.ContinueOnError)
+		ttl := fs.Duration("ttl", time.Duration(cfg.JWT.ExpireTime)*time.Minute, "有效期（如 30m、12h），默认为 jwt.expire_time")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if email == "" || fs.NArg() > 0 {
+			return errors.New(tokenUsage)
+		}
+		if *ttl <= 0 {
+			return fmt.Errorf("无效的有效期: %s", *ttl)
+		}
+
+		user, err := findUser(commandContext(), userService, email)
+		if err != nil {
+			return err
+		}
+		if user.Status != 1 {
+			return fmt.Errorf("用户已被禁用: %s", user.Email)
+		}
+
+		token, err := util.GenerateTokenWithTTL(cfg, user.ID, user.Email, *ttl)
+		if err != nil {
+			return fmt.Errorf("生成 token 失败: %v", err)
+		}
+		log.Infof("已为用户 %s 签发 token，有效期 %s", user.Email, *ttl)
+		fmt.Println(token)
+		return nil
+	})
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"go_web/internal/logger"
+	"go_web/internal/model"
+	"go_web/internal/service"
+	"go_web/pkg/dig"
+
+	"gorm.io/gorm"
+)
+
+const userUsage = `用法:
+  server user create --email 邮箱 --name 姓名 [--roles 角色,...] [--generate-password | --password-stdin]
+  server user disable <邮箱>
+  server user enable <邮箱>
+  server user reset-password <邮箱> [--generate-password | --password-stdin]`
+
+// runUserCommand 用户管理相关命令
+func runUserCommand(container *dig.Container, args []string) error {
+	if len(args) == 0 {
+		return errors.New(userUsage)
+	}
+
+	switch args[0] {
+	case "create":
+		return runUserCreate(container, args[1:])
+	case "disable":
+		return runUserSetStatus(container, args[1:], 0)
+	case "enable":
+		return runUserSetStatus(container, args[1:], 1)
+	case "reset-password":
+		return runUserResetPassword(container, args[1:])
+	default:
+		return fmt.Errorf("未知的 user 子命令: %s\n%s", args[0], userUsage)
+	}
+}
+
+// runUserCreate 创建用户，可同时授予角色
+func runUserCreate(container *dig.Container, args []string) error {
+	fs := flag.NewFlagSet("user create", flag.ContinueOnError)
+	email := fs.String("email", "", "用户邮箱")
+	name := fs.String("name", "", "用户姓名")
+	roles := fs.String("roles", "", "授予的角色名称，多个用逗号分隔")
+	generate := fs.Bool("generate-password", false, "自动生成密码（不提示输入）")
+	fromStdin := fs.Bool("password-stdin", false, "从标准输入读取密码")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *email == "" || *name == "" {
+		return errors.New("--email 和 --name 不能为空")
+	}
+	if *generate && *fromStdin {
+		return errors.New("--generate-password 和 --password-stdin 不能同时使用")
+	}
+
+	return container.Invoke(func(log *logger.Logger, userService service.UserService, roleService service.RoleService) error {
+		ctx := commandContext()
+
+		// 先确认角色存在，避免创建用户后授予角色失败
+		roleList, err := findRoles(ctx, roleService, splitList(*roles))
+		if err != nil {
+			return err
+		}
+
+		password, generated, err := readPassword(*email, *generate, *fromStdin)
+		if err != nil {
+			return err
+		}
+		user, err := userService.CreateUser(ctx, *name, *email, password)
+		if err != nil {
+			return fmt.Errorf("创建用户失败: %v", err)
+		}
+		log.Infof("已创建用户 %s（ID %d）", user.Email, user.ID)
+
+		for _, role := range roleList {
+			if err := roleService.AssignUsers(ctx, role.ID, []uint{user.ID}); err != nil {
+				return fmt.Errorf("授予角色 %s 失败: %v", role.Name, err)
+			}
+			log.Infof("已授予用户 %s 角色 %s", user.Email, role.Name)
+		}
+
+		if generated {
+			fmt.Printf("已生成用户 %s 的密码（只显示一次，请妥善保存）: %s\n", user.Email, password)
+		}
+		return nil
+	})
+}
+
+// runUserSetStatus 启用或禁用用户
+func runUserSetStatus(container *dig.Container, args []string, status int) error {
+	if len(args) != 1 {
+		return errors.New(userUsage)
+	}
+
+	return container.Invoke(func(log *logger.Logger, userService service.UserService) error {
+		ctx := commandContext()
+		user, err := findUser(ctx, userService, args[0])
+		if err != nil {
+			return err
+		}
+		if _, err := userService.UpdateUser(ctx, user.ID, 0, "", status, ""); err != nil {
+			return fmt.Errorf("更新用户状态失败: %v", err)
+		}
+		if status == 1 {
+			log.Infof("已启用用户 %s", user.Email)
+		} else {
+			log.Infof("已禁用用户 %s", user.Email)
+		}
+		return nil
+	})
+}
+
+// runUserResetPassword 重置用户密码
+func runUserResetPassword(container *dig.Container, args []string) error {
+	email, args := splitPositional(args)
+	fs := flag.NewFlagSet("user reset-password", flag.ContinueOnError)
+	generate := fs.Bool("generate-password", false, "自动生成密码（不提示输入）")
+	fromStdin := fs.Bool("password-stdin", false, "从标准输入读取密码")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if email == "" || fs.NArg() > 0 {
+		return errors.New(userUsage)
+	}
+	if *generate && *fromStdin {
+		return errors.New("--generate-password 和 --password-stdin 不能同时使用")
+	}
+
+	return container.Invoke(func(log *logger.Logger, userService service.UserService) error {
+		ctx := commandContext()
+		user, err := findUser(ctx, userService, email)
+		if err != nil {
+			return err
+		}
+
+		password, generated, err := readPassword(user.Email, *generate, *fromStdin)
+		if err != nil {
+			return err
+		}
+		if err := userService.ResetPassword(ctx, user.ID, password); err != nil {
+			return fmt.Errorf("重置密码失败: %v", err)
+		}
+		log.Infof("已重置用户 %s 的密码", user.Email)
+		if user.Status != 1 {
+			log.Warnf("用户 %s 已被禁用，需要执行 server user enable %s 后才能登录", user.Email, user.Email)
+		}
+
+		if generated {
+			fmt.Printf("已生成用户 %s 的密码（只显示一次，请妥善保存）: %s\n", user.Email, password)
+		}
+		return nil
+	})
+}
+
+// findUser 按邮箱查找用户
+func findUser(ctx context.Context, userService service.UserService, email string) (*model.User, error) {
+	user, err := userService.GetUserByEmail(ctx, email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("用户不存在: %s", email)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询用户失败: %v", err)
+	}
+	return user, nil
+}
+
+// splitPositional 取出第一个位置参数，其余参数交给 FlagSet 解析（flag 包遇到位置参数会停止解析）
+func splitPositional(args []string) (string, []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return "", args
+	}
+	return args[0], args[1:]
+}
+
+// splitList 拆分逗号分隔的列表，忽略空白项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
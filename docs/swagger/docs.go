@@ -24,6 +24,268 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/audit-logs/export": {
+            "get": {
+                "description": "按条件流式导出审计日志，支持 CSV 和 NDJSON 格式",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "审计日志"
+                ],
+                "summary": "导出审计日志",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "csv",
+                        "description": "导出格式（csv/ndjson）",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "表名",
+                        "name": "table_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "记录ID",
+                        "name": "record_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "操作类型（create/update/delete）",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "操作者用户ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "操作来源（http/cli）",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "HTTP 请求ID",
+                        "name": "request_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "起始时间（RFC3339 或 2006-01-02，包含）",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "结束时间（RFC3339 或 2006-01-02，不包含）",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/audit-logs/requests/{request_id}": {
+            "get": {
+                "description": "根据请求ID获取 HTTP 审计事件及该请求产生的数据变更记录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "审计日志"
+                ],
+                "summary": "获取请求审计详情",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "请求ID（响应头 X-Request-ID）",
+                        "name": "request_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.RequestAudit"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/audit-logs/{id}/revert": {
+            "post": {
+                "description": "使用审计日志中的旧值快照恢复已软删除的用户/角色/权限，或将记录回滚到该次更新前的状态，或重新建立被解除的用户角色、角色权限关联，回滚本身记录为 revert 操作",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "审计日志"
+                ],
+                "summary": "根据审计日志回滚记录",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "审计日志ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.RevertResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    }
+                }
+            }
+        },
         "/login": {
             "post": {
                 "description": "用户登录接口，验证用户名密码后返回 JWT Token",
@@ -84,7 +346,7 @@ const docTemplate = `{
         },
         "/permissions": {
             "get": {
-                "description": "分页获取权限列表",
+                "description": "分页获取权限列表，支持过滤、排序和搜索。过滤参数格式为 filter[字段]=值 或 filter[字段][操作符]=值，操作符为 eq、ne、gt、gte、lt、lte、like、in（in 的多个值用逗号分隔）；可过滤和排序的字段: id、name、display_name、resource、action、status、created_at、updated_at；deleted_at 只能过滤，需配合 include_deleted 使用",
                 "consumes": [
                     "application/json"
                 ],
@@ -106,10 +368,53 @@ const docTemplate = `{
                     {
                         "type": "integer",
                         "default": 10,
-                        "description": "每页数量",
+                        "description": "每页数量（最大 100）",
                         "name": "page_size",
                         "in": "query"
                     },
+                    {
+                        "type": "string",
+                        "description": "游标分页：为空表示第一页，之后使用响应中的 next_cursor/prev_cursor，不能与 page 同时使用",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "-created_at,name",
+                        "description": "排序字段，多个用逗号分隔，前缀 - 表示降序",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "关键字，模糊匹配 name、display_name、description",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "true",
+                            "false",
+                            "only"
+                        ],
+                        "type": "string",
+                        "description": "包含已软删除的记录：true 包含，only 只返回已删除的记录",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,name",
+                        "description": "只返回这些字段，逗号分隔，id 总是返回；可选: id、name、display_name、description、resource、action、status、version、created_at、updated_at、deleted_at",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "展开的关联，逗号分隔，为空表示不展开；可选: roles。未指定 fields 和 include 时展开 roles",
+                        "name": "include",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "default": "Bearer",
@@ -130,10 +435,22 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/handler.PermissionResponse"
-                                            }
+                                            "allOf": [
+                                                {
+                                                    "$ref": "#/definitions/util.PageData"
+                                                },
+                                                {
+                                                    "type": "object",
+                                                    "properties": {
+                                                        "list": {
+                                                            "type": "array",
+                                                            "items": {
+                                                                "$ref": "#/definitions/handler.PermissionResponse"
+                                                            }
+                                                        }
+                                                    }
+                                                }
+                                            ]
                                         }
                                     }
                                 }
@@ -246,6 +563,19 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "example": "id,name",
+                        "description": "只返回这些字段，逗号分隔，id 总是返回；可选: id、name、display_name、description、resource、action、status、version、created_at、updated_at、deleted_at",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "展开的关联，逗号分隔，为空表示不展开；可选: roles。未指定 fields 和 include 时展开 roles",
+                        "name": "include",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "default": "Bearer",
@@ -272,6 +602,12 @@ const docTemplate = `{
                                     }
                                 }
                             ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "权限的版本号，更新或删除时通过 If-Match 带回"
+                            }
                         }
                     },
                     "400": {
@@ -330,8 +666,14 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/handler.UpdatePermissionRequest"
                         }
-                    }
-                ],
+                    },
+                    {
+                        "type": "string",
+                        "description": "GET 返回的 ETag，版本不一致时返回 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -349,6 +691,12 @@ const docTemplate = `{
                                     }
                                 }
                             ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "更新后的版本号"
+                            }
                         }
                     },
                     "400": {
@@ -363,6 +711,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/util.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -372,7 +738,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "根据权限ID删除权限",
+                "description": "根据权限ID删除权限（软删除）。仍有角色使用该权限时返回 409 和这些角色，force=true 时同时从这些角色中移除该权限",
                 "consumes": [
                     "application/json"
                 ],
@@ -398,6 +764,19 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "GET 返回的 ETag，版本不一致时返回 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "同时从角色中移除该权限",
+                        "name": "force",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -419,54 +798,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/util.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/util.Response"
                         }
-                    }
-                }
-            }
-        },
-        "/roles": {
-            "get": {
-                "description": "分页获取角色列表",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "角色管理"
-                ],
-                "summary": "获取角色列表",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "页码",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "每页数量",
-                        "name": "page_size",
-                        "in": "query"
                     },
-                    {
-                        "type": "string",
-                        "default": "Bearer",
-                        "description": "Bearer {token}",
-                        "name": "Authorization",
-                        "in": "header"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "allOf": [
                                 {
@@ -476,43 +815,48 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/handler.RoleResponse"
-                                            }
+                                            "$ref": "#/definitions/service.Dependents"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "412": {
+                        "description": "Precondition Failed",
                         "schema": {
                             "$ref": "#/definitions/util.Response"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/util.Response"
                         }
                     }
                 }
             },
-            "post": {
-                "description": "创建一个新角色",
+            "patch": {
+                "description": "按 RFC 7396 合并修改权限：请求中没有的字段不修改，description 为 null 或空字符串时清空描述，display_name、status 不能为 null。\n合并后的结果需通过校验：display_name 不能为空，status 为 0 或 1。只写入有变化的字段，审计日志只记录这些字段；没有变化时不写入，版本号不变",
                 "consumes": [
+                    "application/merge-patch+json",
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "角色管理"
+                    "权限管理"
                 ],
-                "summary": "创建角色",
+                "summary": "部分更新权限（JSON Merge Patch）",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "权限ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "string",
                         "default": "Bearer",
@@ -522,18 +866,24 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "角色信息",
-                        "name": "role",
+                        "description": "要修改的字段",
+                        "name": "permission",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.CreateRoleRequest"
+                            "$ref": "#/definitions/handler.UpdatePermissionRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "GET 返回的 ETag，版本不一致时返回 412",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -543,11 +893,17 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/handler.RoleResponse"
+                                            "$ref": "#/definitions/handler.PermissionResponse"
                                         }
                                     }
                                 }
                             ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "更新后的版本号"
+                            }
                         }
                     },
                     "400": {
@@ -562,6 +918,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/util.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -571,9 +951,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/roles/{id}": {
-            "get": {
-                "description": "根据角色ID获取角色详细信息",
+        "/permissions/{id}/purge": {
+            "delete": {
+                "description": "从数据库中删除已软删除的权限及其关联，不可恢复；未删除的权限需要先删除，否则返回 409",
                 "consumes": [
                     "application/json"
                 ],
@@ -581,13 +961,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "角色管理"
+                    "权限管理"
                 ],
-                "summary": "获取角色详情",
+                "summary": "彻底删除权限",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "角色ID",
+                        "description": "权限ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -605,19 +985,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/util.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/handler.RoleResponse"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/util.Response"
                         }
                     },
                     "400": {
@@ -637,11 +1005,25 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/util.Response"
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
                     }
                 }
-            },
-            "put": {
-                "description": "根据角色ID更新角色信息（支持部分更新）",
+            }
+        },
+        "/permissions/{id}/restore": {
+            "post": {
+                "description": "恢复已软删除的权限，名称已被其他权限使用时返回 409",
                 "consumes": [
                     "application/json"
                 ],
@@ -649,13 +1031,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "角色管理"
+                    "权限管理"
                 ],
-                "summary": "更新角色",
+                "summary": "恢复已删除的权限",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "角色ID",
+                        "description": "权限ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -669,13 +1051,10 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "角色信息",
-                        "name": "role",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.UpdateRoleRequest"
-                        }
+                        "type": "string",
+                        "description": "GET 返回的 ETag，版本不一致时返回 412",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -690,7 +1069,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/handler.RoleResponse"
+                                            "$ref": "#/definitions/handler.PermissionResponse"
                                         }
                                     }
                                 }
@@ -709,58 +1088,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/util.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/util.Response"
-                        }
-                    }
-                }
-            },
-            "delete": {
-                "description": "根据角色ID删除角色",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "角色管理"
-                ],
-                "summary": "删除角色",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "角色ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "default": "Bearer",
-                        "description": "Bearer {token}",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/util.Response"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/util.Response"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "412": {
+                        "description": "Precondition Failed",
                         "schema": {
                             "$ref": "#/definitions/util.Response"
                         }
@@ -774,9 +1115,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/roles/{id}/permissions": {
-            "get": {
-                "description": "获取角色拥有的所有权限",
+        "/permissions:batch": {
+            "post": {
+                "description": "一次请求执行多个创建、更新、删除操作，每个操作的 data 与单个创建、更新接口的请求体相同，每个操作单独记录审计日志。\n需要请求中出现的各操作类型对应的权限（permission:create/permission:update/permission:delete）。\natomic 模式（默认）在同一个事务中执行，任一操作失败时全部回滚，响应状态码为失败操作的状态码，其他操作的 status 为 424；\nbest_effort 模式逐个执行，互不影响，始终返回 200，通过每个操作的 status 和 error 判断结果",
                 "consumes": [
                     "application/json"
                 ],
@@ -784,17 +1125,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "角色管理"
+                    "权限管理"
                 ],
-                "summary": "获取角色的权限列表",
+                "summary": "批量创建、更新、删除权限",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "角色ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "type": "string",
                         "default": "Bearer",
@@ -802,6 +1136,15 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "description": "批量操作",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.BatchRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -816,10 +1159,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/handler.PermissionResponse"
-                                            }
+                                            "$ref": "#/definitions/handler.BatchResponse"
                                         }
                                     }
                                 }
@@ -829,19 +1169,117 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/util.Response"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/util.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.BatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.BatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.BatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.BatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.BatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "为角色分配权限",
+            }
+        },
+        "/roles": {
+            "get": {
+                "description": "分页获取角色列表，支持过滤、排序和搜索。过滤参数格式为 filter[字段]=值 或 filter[字段][操作符]=值，操作符为 eq、ne、gt、gte、lt、lte、like、in（in 的多个值用逗号分隔）；可过滤和排序的字段: id、name、display_name、status、created_at、updated_at；deleted_at 只能过滤，需配合 include_deleted 使用",
                 "consumes": [
                     "application/json"
                 ],
@@ -851,15 +1289,134 @@ const docTemplate = `{
                 "tags": [
                     "角色管理"
                 ],
-                "summary": "分配权限给角色",
+                "summary": "获取角色列表",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "角色ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量（最大 100）",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "游标分页：为空表示第一页，之后使用响应中的 next_cursor/prev_cursor，不能与 page 同时使用",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "-created_at,name",
+                        "description": "排序字段，多个用逗号分隔，前缀 - 表示降序",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "关键字，模糊匹配 name、display_name、description",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "true",
+                            "false",
+                            "only"
+                        ],
+                        "type": "string",
+                        "description": "包含已软删除的记录：true 包含，only 只返回已删除的记录",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,name",
+                        "description": "只返回这些字段，逗号分隔，id 总是返回；可选: id、name、display_name、description、status、version、created_at、updated_at、deleted_at",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "展开的关联，逗号分隔，为空表示不展开；可选: permissions、users。未指定 fields 和 include 时展开 permissions",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "allOf": [
+                                                {
+                                                    "$ref": "#/definitions/util.PageData"
+                                                },
+                                                {
+                                                    "type": "object",
+                                                    "properties": {
+                                                        "list": {
+                                                            "type": "array",
+                                                            "items": {
+                                                                "$ref": "#/definitions/handler.RoleResponse"
+                                                            }
+                                                        }
+                                                    }
+                                                }
+                                            ]
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "创建一个新角色",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "角色管理"
+                ],
+                "summary": "创建角色",
+                "parameters": [
                     {
                         "type": "string",
                         "default": "Bearer",
@@ -869,20 +1426,32 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "权限ID列表",
-                        "name": "body",
+                        "description": "角色信息",
+                        "name": "role",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.AssignPermissionsRequest"
+                            "$ref": "#/definitions/handler.CreateRoleRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/util.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.RoleResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -904,26 +1473,1512 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "从角色中移除权限",
+            }
+        },
+        "/roles/{id}": {
+            "get": {
+                "description": "根据角色ID获取角色详细信息",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json"
+                ],
+                "tags": [
+                    "角色管理"
+                ],
+                "summary": "获取角色详情",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "角色ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,name",
+                        "description": "只返回这些字段，逗号分隔，id 总是返回；可选: id、name、display_name、description、status、version、created_at、updated_at、deleted_at",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "展开的关联，逗号分隔，为空表示不展开；可选: permissions、users。未指定 fields 和 include 时展开 permissions、users",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.RoleResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "角色的版本号，更新或删除时通过 If-Match 带回"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "根据角色ID更新角色信息（支持部分更新）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "角色管理"
+                ],
+                "summary": "更新角色",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "角色ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "角色信息",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateRoleRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "GET 返回的 ETag，版本不一致时返回 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.RoleResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "更新后的版本号"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "根据角色ID删除角色（软删除）。仍有用户拥有该角色时返回 409 和这些用户，force=true 时同时解除该角色与所有用户、权限的关联",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "角色管理"
+                ],
+                "summary": "删除角色",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "角色ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "GET 返回的 ETag，版本不一致时返回 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "同时解除该角色与所有用户、权限的关联",
+                        "name": "force",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.Dependents"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "按 RFC 7396 合并修改角色：请求中没有的字段不修改，description 为 null 或空字符串时清空描述，display_name、status 不能为 null。\n合并后的结果需通过校验：display_name 不能为空，status 为 0 或 1。只写入有变化的字段，审计日志只记录这些字段；没有变化时不写入，版本号不变",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "角色管理"
+                ],
+                "summary": "部分更新角色（JSON Merge Patch）",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "角色ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "要修改的字段",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateRoleRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "GET 返回的 ETag，版本不一致时返回 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.RoleResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "更新后的版本号"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{id}/permissions": {
+            "get": {
+                "description": "获取角色拥有的所有权限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "角色管理"
+                ],
+                "summary": "获取角色的权限列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "角色ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handler.PermissionResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "为角色分配权限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "角色管理"
+                ],
+                "summary": "分配权限给角色",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "角色ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "权限ID列表",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.AssignPermissionsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "从角色中移除权限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "角色管理"
+                ],
+                "summary": "移除角色的权限",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "角色ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "权限ID列表",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.AssignPermissionsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{id}/purge": {
+            "delete": {
+                "description": "从数据库中删除已软删除的角色及其关联，不可恢复；未删除的角色需要先删除，否则返回 409",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "角色管理"
+                ],
+                "summary": "彻底删除角色",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "角色ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{id}/restore": {
+            "post": {
+                "description": "恢复已软删除的角色，名称已被其他角色使用时返回 409",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "角色管理"
+                ],
+                "summary": "恢复已删除的角色",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "角色ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "GET 返回的 ETag，版本不一致时返回 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.RoleResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{id}/users": {
+            "get": {
+                "description": "获取拥有该角色的所有用户",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "角色管理"
+                ],
+                "summary": "获取角色的用户列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "角色ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handler.UserResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "为角色分配用户",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "角色管理"
+                ],
+                "summary": "分配用户给角色",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "角色ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "用户ID列表",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.AssignUsersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "从角色中移除用户",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "角色管理"
+                ],
+                "summary": "移除角色的用户",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "角色ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "用户ID列表",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.AssignUsersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles:batch": {
+            "post": {
+                "description": "一次请求执行多个创建、更新、删除操作，每个操作的 data 与单个创建、更新接口的请求体相同，每个操作单独记录审计日志。\n需要请求中出现的各操作类型对应的权限（role:create/role:update/role:delete）。\natomic 模式（默认）在同一个事务中执行，任一操作失败时全部回滚，响应状态码为失败操作的状态码，其他操作的 status 为 424；\nbest_effort 模式逐个执行，互不影响，始终返回 200，通过每个操作的 status 和 error 判断结果",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "角色管理"
+                ],
+                "summary": "批量创建、更新、删除角色",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "批量操作",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.BatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.BatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.BatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.BatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.BatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.BatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.BatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/security-events": {
+            "get": {
+                "description": "按条件分页查询登录失败、无效 Token、未登录访问、权限不足等安全事件",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "安全事件"
+                ],
+                "summary": "获取安全事件列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "事件类型（login_failed/invalid_token/unauthenticated/forbidden）",
+                        "name": "event_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "用户ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "用户邮箱",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "客户端IP",
+                        "name": "ip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "起始时间（RFC3339 或 2006-01-02，包含）",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "结束时间（RFC3339 或 2006-01-02，不包含）",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量（最大 100）",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "游标分页：为空表示第一页，之后使用响应中的 next_cursor/prev_cursor，不能与 page 同时使用",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "allOf": [
+                                                {
+                                                    "$ref": "#/definitions/util.PageData"
+                                                },
+                                                {
+                                                    "type": "object",
+                                                    "properties": {
+                                                        "list": {
+                                                            "type": "array",
+                                                            "items": {
+                                                                "$ref": "#/definitions/model.SecurityEvent"
+                                                            }
+                                                        }
+                                                    }
+                                                }
+                                            ]
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "分页获取用户列表，支持过滤、排序和搜索。过滤参数格式为 filter[字段]=值 或 filter[字段][操作符]=值，操作符为 eq、ne、gt、gte、lt、lte、like、in（in 的多个值用逗号分隔）；可过滤和排序的字段: id、name、email、status、created_at、updated_at；deleted_at 只能过滤，需配合 include_deleted 使用",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "获取用户列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量（最大 100）",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "游标分页：为空表示第一页，之后使用响应中的 next_cursor/prev_cursor，不能与 page 同时使用",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "-created_at,name",
+                        "description": "排序字段，多个用逗号分隔，前缀 - 表示降序",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "关键字，模糊匹配 name、email",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "true",
+                            "false",
+                            "only"
+                        ],
+                        "type": "string",
+                        "description": "包含已软删除的记录：true 包含，only 只返回已删除的记录",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,name",
+                        "description": "只返回这些字段，逗号分隔，id 总是返回；可选: id、name、email、status、version、language、created_at、updated_at、deleted_at",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "展开的关联，逗号分隔，为空表示不展开；可选: roles、roles.permissions。未指定 fields 和 include 时展开 roles",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "allOf": [
+                                                {
+                                                    "$ref": "#/definitions/util.PageData"
+                                                },
+                                                {
+                                                    "type": "object",
+                                                    "properties": {
+                                                        "list": {
+                                                            "type": "array",
+                                                            "items": {
+                                                                "$ref": "#/definitions/handler.UserResponse"
+                                                            }
+                                                        }
+                                                    }
+                                                }
+                                            ]
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "创建一个新用户",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "创建用户",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "用户信息",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.UserResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/export": {
+            "get": {
+                "description": "按列表接口的过滤、排序、搜索条件流式导出用户及其角色名称，列为 id、name、email、status、roles（多个用 ; 分隔）、created_at。\n过滤语法与字段见获取用户列表接口",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
                 ],
                 "tags": [
-                    "角色管理"
+                    "用户管理"
                 ],
-                "summary": "移除角色的权限",
+                "summary": "导出用户",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "角色ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "default": "csv",
+                        "description": "导出格式（csv/xlsx）",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "-created_at,name",
+                        "description": "排序字段，多个用逗号分隔，前缀 - 表示降序",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "关键字，模糊匹配 name、email",
+                        "name": "q",
+                        "in": "query"
                     },
                     {
                         "type": "string",
@@ -932,22 +2987,13 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
-                    },
-                    {
-                        "description": "权限ID列表",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.AssignPermissionsRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/util.Response"
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -962,8 +3008,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/util.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/util.Response"
                         }
@@ -971,27 +3017,40 @@ const docTemplate = `{
                 }
             }
         },
-        "/roles/{id}/users": {
-            "get": {
-                "description": "获取拥有该角色的所有用户",
+        "/users/import": {
+            "post": {
+                "description": "上传 CSV 或 xlsx 文件批量创建用户并分配角色。首行为表头，按名称识别 name、email、password、roles 列（roles 可选，多个角色用 ; 或 , 分隔），其他列忽略；xlsx 读取第一个工作表。\n逐行校验必填项、邮箱格式、密码长度、文件内重复邮箱、已存在的邮箱和不存在的角色，有任一行校验失败时不导入并返回 422 和逐行错误报告。\ndry_run=true 时只返回校验报告；否则在一个事务中创建全部用户并分配角色，写入失败时全部回滚并返回 409 和报告。需要 user:create 和 role:update 权限",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "角色管理"
+                    "用户管理"
                 ],
-                "summary": "获取角色的用户列表",
+                "summary": "导入用户",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "角色ID",
-                        "name": "id",
-                        "in": "path",
+                        "type": "file",
+                        "description": "导入文件（.csv/.xlsx，最大 10MB）",
+                        "name": "file",
+                        "in": "formData",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "文件格式（csv/xlsx），为空时按文件扩展名判断",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "仅校验并返回预览报告，不写入",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "default": "Bearer",
@@ -1003,7 +3062,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "预览",
                         "schema": {
                             "allOf": [
                                 {
@@ -1013,10 +3072,25 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/handler.UserResponse"
-                                            }
+                                            "$ref": "#/definitions/service.UserImportReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "201": {
+                        "description": "导入成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.UserImportReport"
                                         }
                                     }
                                 }
@@ -1034,11 +3108,61 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/util.Response"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.UserImportReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.UserImportReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
                     }
                 }
-            },
-            "post": {
-                "description": "为角色分配用户",
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "description": "根据用户ID获取用户详细信息",
                 "consumes": [
                     "application/json"
                 ],
@@ -1046,17 +3170,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "角色管理"
+                    "用户管理"
                 ],
-                "summary": "分配用户给角色",
+                "summary": "获取用户详情",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "角色ID",
+                        "description": "用户ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "example": "id,name",
+                        "description": "只返回这些字段，逗号分隔，id 总是返回；可选: id、name、email、status、version、language、created_at、updated_at、deleted_at",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "展开的关联，逗号分隔，为空表示不展开；可选: roles、roles.permissions。未指定 fields 和 include 时展开 roles、roles.permissions",
+                        "name": "include",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "default": "Bearer",
@@ -1064,22 +3201,31 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
-                    },
-                    {
-                        "description": "用户ID列表",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.AssignUsersRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/util.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.UserResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "用户的版本号，更新或删除时通过 If-Match 带回"
+                            }
                         }
                     },
                     "400": {
@@ -1094,16 +3240,16 @@ const docTemplate = `{
                             "$ref": "#/definitions/util.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/util.Response"
                         }
                     }
                 }
             },
-            "delete": {
-                "description": "从角色中移除用户",
+            "put": {
+                "description": "根据用户ID更新用户信息（支持部分更新）",
                 "consumes": [
                     "application/json"
                 ],
@@ -1111,13 +3257,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "角色管理"
+                    "用户管理"
                 ],
-                "summary": "移除角色的用户",
+                "summary": "更新用户",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "角色ID",
+                        "description": "用户ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1131,20 +3277,44 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "用户ID列表",
-                        "name": "body",
+                        "description": "用户信息",
+                        "name": "user",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.AssignUsersRequest"
+                            "$ref": "#/definitions/handler.UpdateUserRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "GET 返回的 ETag，版本不一致时返回 412",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/util.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.UserResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "更新后的版本号"
+                            }
                         }
                     },
                     "400": {
@@ -1159,6 +3329,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/util.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1166,11 +3354,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/users": {
-            "get": {
-                "description": "分页获取用户列表",
+            },
+            "delete": {
+                "description": "根据用户ID删除用户（软删除）",
                 "consumes": [
                     "application/json"
                 ],
@@ -1180,27 +3366,27 @@ const docTemplate = `{
                 "tags": [
                     "用户管理"
                 ],
-                "summary": "获取用户列表",
+                "summary": "删除用户",
                 "parameters": [
                     {
                         "type": "integer",
-                        "default": 1,
-                        "description": "页码",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "每页数量",
-                        "name": "page_size",
-                        "in": "query"
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
                         "default": "Bearer",
                         "description": "Bearer {token}",
                         "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "GET 返回的 ETag，版本不一致时返回 412",
+                        "name": "If-Match",
                         "in": "header"
                     }
                 ],
@@ -1208,36 +3394,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/util.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "type": "object",
-                                            "properties": {
-                                                "list": {
-                                                    "type": "array",
-                                                    "items": {
-                                                        "$ref": "#/definitions/handler.UserResponse"
-                                                    }
-                                                },
-                                                "page": {
-                                                    "type": "integer"
-                                                },
-                                                "page_size": {
-                                                    "type": "integer"
-                                                },
-                                                "total": {
-                                                    "type": "integer"
-                                                }
-                                            }
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
                         }
                     },
                     "401": {
@@ -1246,6 +3409,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/util.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1254,9 +3429,10 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
-                "description": "创建一个新用户",
+            "patch": {
+                "description": "按 RFC 7396 合并修改用户：请求中没有的字段不修改，language 为 null 时清除语言偏好（改为按 Accept-Language 协商），name、status 不能为 null。\n合并后的结果需通过校验：name 不能为空，status 为 0 或 1，language 为 zh、en 或空。只写入有变化的字段，审计日志只记录这些字段；没有变化时不写入，版本号不变",
                 "consumes": [
+                    "application/merge-patch+json",
                     "application/json"
                 ],
                 "produces": [
@@ -1265,8 +3441,15 @@ const docTemplate = `{
                 "tags": [
                     "用户管理"
                 ],
-                "summary": "创建用户",
+                "summary": "部分更新用户（JSON Merge Patch）",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "string",
                         "default": "Bearer",
@@ -1276,18 +3459,24 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "用户信息",
+                        "description": "要修改的字段",
                         "name": "user",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.CreateUserRequest"
+                            "$ref": "#/definitions/handler.UpdateUserRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "GET 返回的 ETag，版本不一致时返回 412",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -1302,6 +3491,12 @@ const docTemplate = `{
                                     }
                                 }
                             ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "更新后的版本号"
+                            }
                         }
                     },
                     "400": {
@@ -1316,6 +3511,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/util.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1325,9 +3544,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}": {
-            "get": {
-                "description": "根据用户ID获取用户详细信息",
+        "/users/{id}/purge": {
+            "delete": {
+                "description": "从数据库中删除已软删除的用户及其关联，不可恢复；未删除的用户需要先删除，否则返回 409",
                 "consumes": [
                     "application/json"
                 ],
@@ -1337,7 +3556,7 @@ const docTemplate = `{
                 "tags": [
                     "用户管理"
                 ],
-                "summary": "获取用户详情",
+                "summary": "彻底删除用户",
                 "parameters": [
                     {
                         "type": "integer",
@@ -1359,19 +3578,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/util.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/handler.UserResponse"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/util.Response"
                         }
                     },
                     "400": {
@@ -1391,11 +3598,25 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/util.Response"
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
                     }
                 }
-            },
-            "put": {
-                "description": "根据用户ID更新用户信息（支持部分更新）",
+            }
+        },
+        "/users/{id}/restore": {
+            "post": {
+                "description": "恢复已软删除的用户，邮箱已被其他用户使用时返回 409",
                 "consumes": [
                     "application/json"
                 ],
@@ -1405,7 +3626,7 @@ const docTemplate = `{
                 "tags": [
                     "用户管理"
                 ],
-                "summary": "更新用户",
+                "summary": "恢复已删除的用户",
                 "parameters": [
                     {
                         "type": "integer",
@@ -1423,13 +3644,10 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "用户信息",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.UpdateUserRequest"
-                        }
+                        "type": "string",
+                        "description": "GET 返回的 ETag，版本不一致时返回 412",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -1463,6 +3681,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/util.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1470,9 +3706,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "根据用户ID删除用户（软删除）",
+            }
+        },
+        "/users:batch": {
+            "post": {
+                "description": "一次请求执行多个创建、更新、删除操作，每个操作的 data 与单个创建、更新接口的请求体相同，每个操作单独记录审计日志。\n需要请求中出现的各操作类型对应的权限（user:create/user:update/user:delete）。\natomic 模式（默认）在同一个事务中执行，任一操作失败时全部回滚，响应状态码为失败操作的状态码，其他操作的 status 为 424；\nbest_effort 模式逐个执行，互不影响，始终返回 200，通过每个操作的 status 和 error 判断结果",
                 "consumes": [
                     "application/json"
                 ],
@@ -1482,15 +3720,8 @@ const docTemplate = `{
                 "tags": [
                     "用户管理"
                 ],
-                "summary": "删除用户",
+                "summary": "批量创建、更新、删除用户",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "用户ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "type": "string",
                         "default": "Bearer",
@@ -1498,19 +3729,52 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "description": "批量操作",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.BatchRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/util.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.BatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/util.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.BatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "401": {
@@ -1519,23 +3783,341 @@ const docTemplate = `{
                             "$ref": "#/definitions/util.Response"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.BatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.BatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.BatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/util.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/util.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.BatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
+        }
+    },
+    "definitions": {
+        "apperr.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "字段名（请求体中的 JSON 字段名，嵌套字段用 . 连接）",
+                    "type": "string",
+                    "example": "email"
+                },
+                "message": {
+                    "description": "提示信息（按请求语言翻译）",
+                    "type": "string",
+                    "example": "邮箱格式不正确"
+                },
+                "rule": {
+                    "description": "未通过的校验规则，如 required、email、min",
+                    "type": "string",
+                    "example": "email"
+                }
+            }
+        },
+        "database.AuditLog": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "create, update, delete, restore, purge, archive, revert",
+                    "type": "string"
+                },
+                "actor": {
+                    "description": "操作来源（http/cli），为空表示服务内部操作",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "new_values": {
+                    "type": "string"
+                },
+                "old_values": {
+                    "type": "string"
+                },
+                "record_id": {
+                    "type": "integer"
+                },
+                "ref_id": {
+                    "description": "引用的审计日志ID（revert 操作指向被回滚的记录）",
+                    "type": "integer"
+                },
+                "request_id": {
+                    "description": "产生该记录的 HTTP 请求ID",
+                    "type": "string"
+                },
+                "table_name": {
+                    "description": "表名，使用column标签避免与方法名冲突",
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "database.HTTPAuditEvent": {
+            "type": "object",
+            "properties": {
+                "client_ip": {
+                    "description": "客户端IP",
+                    "type": "string"
+                },
+                "client_request_id": {
+                    "description": "客户端在 X-Request-ID 中传入的请求ID",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "decision": {
+                    "description": "权限校验结果，空表示未经过权限校验",
+                    "type": "string"
+                },
+                "duration_ms": {
+                    "description": "处理耗时（毫秒）",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "method": {
+                    "description": "请求方法",
+                    "type": "string"
+                },
+                "params": {
+                    "description": "路径参数和查询参数（JSON 格式）",
+                    "type": "string"
+                },
+                "path": {
+                    "description": "实际请求路径",
+                    "type": "string"
+                },
+                "permission": {
+                    "description": "校验的权限，如 user:create",
+                    "type": "string"
+                },
+                "request_id": {
+                    "description": "服务端生成的请求ID",
+                    "type": "string"
+                },
+                "route": {
+                    "description": "路由模板，如 /api/v1/users/:id",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "响应状态码",
+                    "type": "integer"
+                },
+                "user_agent": {
+                    "description": "User-Agent",
+                    "type": "string"
+                },
+                "user_email": {
+                    "description": "操作者邮箱",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "操作者用户ID（未登录为0）",
+                    "type": "integer"
+                }
+            }
+        },
         "handler.AssignPermissionsRequest": {
             "type": "object"
         },
         "handler.AssignUsersRequest": {
             "type": "object"
         },
+        "handler.BatchItemResult": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "创建或更新后的记录"
+                },
+                "error": {
+                    "description": "失败原因",
+                    "type": "string"
+                },
+                "error_code": {
+                    "description": "失败时的错误码，与单个操作接口一致，如 USER_EMAIL_TAKEN、BATCH_ABORTED",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "记录ID（创建成功后为新记录的ID）",
+                    "type": "integer",
+                    "example": 1
+                },
+                "index": {
+                    "description": "操作在请求中的序号（从 0 开始）",
+                    "type": "integer",
+                    "example": 0
+                },
+                "op": {
+                    "description": "操作类型",
+                    "type": "string",
+                    "example": "create"
+                },
+                "status": {
+                    "description": "与单个操作接口一致的 HTTP 状态码，424 表示因其他操作失败而回滚或未执行",
+                    "type": "integer",
+                    "example": 201
+                }
+            }
+        },
+        "handler.BatchOperation": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "创建或更新的内容，格式与单个创建、更新接口的请求体相同",
+                    "type": "object"
+                },
+                "id": {
+                    "description": "记录ID（update/delete 必填）",
+                    "type": "integer",
+                    "example": 1
+                },
+                "op": {
+                    "description": "操作类型：create/update/delete",
+                    "type": "string",
+                    "example": "create"
+                },
+                "version": {
+                    "description": "版本号（update/delete 可选，作用同 If-Match，不一致时该操作返回 412）",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "handler.BatchRequest": {
+            "type": "object",
+            "required": [
+                "operations"
+            ],
+            "properties": {
+                "mode": {
+                    "description": "atomic（默认，在同一个事务中执行，任一失败时全部回滚）/best_effort（逐个执行，互不影响）",
+                    "type": "string",
+                    "example": "atomic"
+                },
+                "operations": {
+                    "description": "操作列表，数量上限为 server.batch_max_operations",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.BatchOperation"
+                    }
+                }
+            }
+        },
+        "handler.BatchResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "description": "失败的操作数",
+                    "type": "integer",
+                    "example": 0
+                },
+                "mode": {
+                    "description": "执行模式",
+                    "type": "string",
+                    "example": "atomic"
+                },
+                "results": {
+                    "description": "每个操作的结果，顺序与请求一致",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.BatchItemResult"
+                    }
+                },
+                "succeeded": {
+                    "description": "成功的操作数",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
         "handler.CreatePermissionRequest": {
             "type": "object",
             "required": [
@@ -1700,6 +4282,11 @@ const docTemplate = `{
                     "description": "更新时间",
                     "type": "string",
                     "example": "2024-01-01T00:00:00Z"
+                },
+                "version": {
+                    "description": "版本号，每次更新加一",
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
@@ -1740,6 +4327,11 @@ const docTemplate = `{
                     "description": "更新时间",
                     "type": "string",
                     "example": "2024-01-01T00:00:00Z"
+                },
+                "version": {
+                    "description": "版本号，每次更新加一",
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
@@ -1786,6 +4378,15 @@ const docTemplate = `{
         "handler.UpdateUserRequest": {
             "type": "object",
             "properties": {
+                "language": {
+                    "description": "接口提示信息的语言偏好：zh/en（可选）",
+                    "type": "string",
+                    "enum": [
+                        "zh",
+                        "en"
+                    ],
+                    "example": "en"
+                },
                 "name": {
                     "description": "用户姓名（可选）",
                     "type": "string",
@@ -1816,6 +4417,11 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 1
                 },
+                "language": {
+                    "description": "语言偏好，为空时按 Accept-Language 协商",
+                    "type": "string",
+                    "example": "zh"
+                },
                 "name": {
                     "description": "用户姓名",
                     "type": "string",
@@ -1830,6 +4436,225 @@ const docTemplate = `{
                     "description": "更新时间",
                     "type": "string",
                     "example": "2024-01-01T00:00:00Z"
+                },
+                "version": {
+                    "description": "版本号，每次更新加一",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "model.SecurityEvent": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "description": "用户邮箱",
+                    "type": "string"
+                },
+                "event_type": {
+                    "description": "事件类型",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "description": "客户端IP",
+                    "type": "string"
+                },
+                "method": {
+                    "description": "请求方法",
+                    "type": "string"
+                },
+                "path": {
+                    "description": "请求路径",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "失败原因",
+                    "type": "string"
+                },
+                "request_id": {
+                    "description": "请求ID",
+                    "type": "string"
+                },
+                "user_agent": {
+                    "description": "User-Agent",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "用户ID（未知时为0）",
+                    "type": "integer"
+                }
+            }
+        },
+        "service.Dependent": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "用户的邮箱",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "用户名或角色名称",
+                    "type": "string"
+                }
+            }
+        },
+        "service.Dependents": {
+            "type": "object",
+            "properties": {
+                "roles": {
+                    "description": "使用该权限的角色，最多列出 maxDependents 个",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Dependent"
+                    }
+                },
+                "total": {
+                    "description": "引用的记录总数",
+                    "type": "integer"
+                },
+                "users": {
+                    "description": "拥有该角色的用户，最多列出 maxDependents 个",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Dependent"
+                    }
+                }
+            }
+        },
+        "service.RequestAudit": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.AuditLog"
+                    }
+                },
+                "event": {
+                    "$ref": "#/definitions/database.HTTPAuditEvent"
+                }
+            }
+        },
+        "service.RevertResult": {
+            "type": "object",
+            "properties": {
+                "record": {
+                    "description": "回滚后的记录"
+                },
+                "revert": {
+                    "description": "回滚操作产生的审计日志",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.AuditLog"
+                        }
+                    ]
+                }
+            }
+        },
+        "service.UserImportReport": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "description": "是否仅预览",
+                    "type": "boolean",
+                    "example": false
+                },
+                "imported": {
+                    "description": "实际导入的用户数，预览时为 0",
+                    "type": "integer",
+                    "example": 2
+                },
+                "invalid": {
+                    "description": "校验失败的行数",
+                    "type": "integer",
+                    "example": 0
+                },
+                "rows": {
+                    "description": "每行的结果，顺序与文件一致",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.UserImportRow"
+                    }
+                },
+                "total": {
+                    "description": "数据行数",
+                    "type": "integer",
+                    "example": 2
+                },
+                "valid": {
+                    "description": "校验通过的行数",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "service.UserImportRow": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "用户邮箱",
+                    "type": "string",
+                    "example": "zhangsan@example.com"
+                },
+                "errors": {
+                    "description": "该行的错误，为空表示校验通过",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "description": "用户姓名",
+                    "type": "string",
+                    "example": "张三"
+                },
+                "roles": {
+                    "description": "分配的角色名称",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "row": {
+                    "description": "文件中的行号（表头为第 1 行）",
+                    "type": "integer",
+                    "example": 2
+                },
+                "user_id": {
+                    "description": "导入后的用户ID，预览或失败时为空",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "util.PageData": {
+            "type": "object",
+            "properties": {
+                "list": {},
+                "next_cursor": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "description": "过滤后的总数，游标分页不统计",
+                    "type": "integer"
                 }
             }
         },
@@ -1843,12 +4668,24 @@ const docTemplate = `{
                 "data": {
                     "description": "响应数据（可选）"
                 },
+                "details": {
+                    "description": "逐字段的校验错误（VALIDATION_FAILED 时）",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apperr.FieldError"
+                    }
+                },
                 "error": {
-                    "description": "错误详情（可选）",
+                    "description": "错误提示（失败时）",
                     "type": "string"
                 },
+                "error_code": {
+                    "description": "机器可读的错误码（失败时），客户端应按错误码而不是提示文字判断错误类型",
+                    "type": "string",
+                    "example": "USER_EMAIL_TAKEN"
+                },
                 "message": {
-                    "description": "响应消息",
+                    "description": "响应消息（按请求语言翻译）",
                     "type": "string"
                 }
             }
@@ -0,0 +1,126 @@
+// Package apperr 定义带有稳定错误码的业务错误
+// 服务层返回这里定义的错误（需要补充说明时用 WithMessage 指定消息码），由 util.Fail 统一转换为 HTTP 状态码、错误码和按请求语言翻译的提示信息，
+// 客户端应按错误码而不是提示文字判断错误类型
+package apperr
+
+import (
+	"errors"
+	"strings"
+
+	"go_web/internal/i18n"
+)
+
+// Error 业务错误
+type Error struct {
+	Status  int          // HTTP 状态码
+	Code    string       // 机器可读的错误码，如 USER_EMAIL_TAKEN，发布后保持不变
+	Fields  []FieldError // 逐字段的校验错误（可选）
+	Data    interface{}  // 附加数据（可选），作为响应的 data 返回，如删除时仍引用该记录的关联记录
+	message i18n.Message // 返回给客户端的提示信息，默认为错误码对应的消息
+	cause   error        // 内部原因，只记录日志，不返回给客户端
+}
+
+// FieldError 单个字段的校验错误
+type FieldError struct {
+	Field   string       `json:"field" example:"email"`     // 字段名（请求体中的 JSON 字段名，嵌套字段用 . 连接）
+	Rule    string       `json:"rule" example:"email"`      // 未通过的校验规则，如 required、email、min
+	Message string       `json:"message" example:"邮箱格式不正确"` // 提示信息（按请求语言翻译）
+	message i18n.Message // 待翻译的提示信息
+}
+
+// NewFieldError 创建字段错误，key 为提示信息的消息码
+func NewFieldError(field, rule, key string, args ...interface{}) FieldError {
+	return FieldError{Field: field, Rule: rule, message: i18n.M(key, args...)}
+}
+
+// New 创建业务错误，提示信息为消息表中错误码对应的消息
+func New(status int, code string) *Error {
+	return &Error{Status: status, Code: code, message: i18n.M(code)}
+}
+
+// Error 默认语言的提示信息
+func (e *Error) Error() string {
+	return e.Localize(i18n.Default)
+}
+
+// Localize 按语言输出提示信息，有逐字段错误时附上各字段错误的汇总
+func (e *Error) Localize(lang string) string {
+	message := e.message.Localize(lang)
+	if len(e.Fields) == 0 {
+		return message
+	}
+	items := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		items = append(items, f.Field+" "+f.message.Localize(lang))
+	}
+	return message + ": " + strings.Join(items, "; ")
+}
+
+// LocalizeFields 按语言输出逐字段的校验错误
+func (e *Error) LocalizeFields(lang string) []FieldError {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	fields := make([]FieldError, len(e.Fields))
+	for i, f := range e.Fields {
+		f.Message = f.message.Localize(lang)
+		fields[i] = f
+	}
+	return fields
+}
+
+// Unwrap 返回内部原因
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is 错误码相同即视为同一种错误，WithMessage、Wrap 等派生的错误仍可以用 errors.Is 与原错误比较
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithMessage 返回替换了提示信息的副本，key 为 i18n 中的消息码，args 为消息参数
+func (e *Error) WithMessage(key string, args ...interface{}) *Error {
+	c := *e
+	c.message = i18n.M(key, args...)
+	return &c
+}
+
+// WithFields 返回带有逐字段校验错误的副本
+func (e *Error) WithFields(fields []FieldError) *Error {
+	c := *e
+	c.Fields = fields
+	return &c
+}
+
+// WithData 返回带有附加数据的副本
+func (e *Error) WithData(data interface{}) *Error {
+	c := *e
+	c.Data = data
+	return &c
+}
+
+// Wrap 返回记录了内部原因的副本，原因不会出现在提示信息中
+func (e *Error) Wrap(cause error) *Error {
+	c := *e
+	c.cause = cause
+	return &c
+}
+
+// As 查找错误链中的业务错误，找不到时返回 nil
+func As(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return nil
+}
+
+// Localize 按语言输出任意错误的提示信息：业务错误按消息表翻译，其他错误原样输出
+func Localize(err error, lang string) string {
+	if e := As(err); e != nil {
+		return e.Localize(lang)
+	}
+	return err.Error()
+}
@@ -0,0 +1,60 @@
+package apperr
+
+import "net/http"
+
+// 错误码对应的提示信息见 i18n 的消息表（internal/i18n/zh.go、en.go）
+
+// 通用错误
+var (
+	ErrBadRequest           = New(http.StatusBadRequest, "BAD_REQUEST")
+	ErrValidation           = New(http.StatusBadRequest, "VALIDATION_FAILED") // Fields 中列出每个字段的错误
+	ErrInvalidID            = New(http.StatusBadRequest, "INVALID_ID")
+	ErrInvalidQuery         = New(http.StatusBadRequest, "INVALID_QUERY")
+	ErrInvalidIfMatch       = New(http.StatusBadRequest, "INVALID_IF_MATCH")
+	ErrUnsupportedFormat    = New(http.StatusBadRequest, "UNSUPPORTED_FORMAT")
+	ErrUnauthorized         = New(http.StatusUnauthorized, "UNAUTHORIZED")
+	ErrForbidden            = New(http.StatusForbidden, "PERMISSION_DENIED")
+	ErrNotFound             = New(http.StatusNotFound, "NOT_FOUND")
+	ErrVersionConflict      = New(http.StatusConflict, "VERSION_CONFLICT")           // 读取后、写入前被其他请求修改
+	ErrVersionMismatch      = New(http.StatusPreconditionFailed, "VERSION_MISMATCH") // If-Match 或 version 与当前版本不一致
+	ErrNotDeleted           = New(http.StatusConflict, "RECORD_NOT_DELETED")         // 恢复或彻底删除的记录未被删除
+	ErrDuplicate            = New(http.StatusConflict, "DUPLICATE_RECORD")           // 违反唯一索引，业务层没有转换为更具体的错误码时使用
+	ErrRequestTooLarge      = New(http.StatusRequestEntityTooLarge, "REQUEST_TOO_LARGE")
+	ErrUnsupportedMediaType = New(http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE")
+	ErrTooManyRequests      = New(http.StatusTooManyRequests, "RATE_LIMITED")
+	ErrInternal             = New(http.StatusInternalServerError, "INTERNAL_ERROR")
+)
+
+// 认证
+var (
+	ErrInvalidCredentials = New(http.StatusUnauthorized, "INVALID_CREDENTIALS")
+	ErrUserDisabled       = New(http.StatusUnauthorized, "USER_DISABLED")
+)
+
+// 用户、角色、权限
+var (
+	ErrUserNotFound        = New(http.StatusNotFound, "USER_NOT_FOUND")
+	ErrUserEmailTaken      = New(http.StatusConflict, "USER_EMAIL_TAKEN")
+	ErrRoleNotFound        = New(http.StatusNotFound, "ROLE_NOT_FOUND")
+	ErrRoleNameTaken       = New(http.StatusConflict, "ROLE_NAME_TAKEN")
+	ErrRoleInUse           = New(http.StatusConflict, "ROLE_IN_USE") // 删除仍有用户的角色，data 中列出这些用户
+	ErrPermissionNotFound  = New(http.StatusNotFound, "PERMISSION_NOT_FOUND")
+	ErrPermissionNameTaken = New(http.StatusConflict, "PERMISSION_NAME_TAKEN")
+	ErrPermissionInUse     = New(http.StatusConflict, "PERMISSION_IN_USE") // 删除仍被角色使用的权限，data 中列出这些角色
+)
+
+// 批量操作与导入
+var (
+	ErrBatchAborted  = New(http.StatusFailedDependency, "BATCH_ABORTED")
+	ErrImportFile    = New(http.StatusBadRequest, "IMPORT_FILE_INVALID")          // 文件格式错误、缺少必需列或行数超出上限
+	ErrImportInvalid = New(http.StatusUnprocessableEntity, "IMPORT_ROWS_INVALID") // 逐行错误见导入报告
+	ErrImportFailed  = New(http.StatusConflict, "IMPORT_FAILED")                  // 写入时有行失败，逐行错误见导入报告
+)
+
+// 审计
+var (
+	ErrAuditLogNotFound     = New(http.StatusNotFound, "AUDIT_LOG_NOT_FOUND")
+	ErrRequestAuditNotFound = New(http.StatusNotFound, "REQUEST_AUDIT_NOT_FOUND")
+	ErrRevertNotSupported   = New(http.StatusBadRequest, "AUDIT_REVERT_NOT_SUPPORTED")
+	ErrRevertConflict       = New(http.StatusConflict, "AUDIT_REVERT_CONFLICT")
+)
@@ -0,0 +1,157 @@
+package apperr
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"go_web/internal/i18n"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+// From 将任意错误转换为业务错误：
+//   - 错误链中的业务错误：原样返回
+//   - 请求体校验错误：VALIDATION_FAILED，逐字段列出错误
+//   - 请求体 JSON 格式错误：BAD_REQUEST 或 VALIDATION_FAILED（字段类型不匹配）
+//   - gorm.ErrRecordNotFound：NOT_FOUND
+//   - gorm.ErrDuplicatedKey：DUPLICATE_RECORD
+//   - 其他错误：INTERNAL_ERROR，原始错误只作为内部原因，不返回给客户端
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	if e := As(err); e != nil {
+		if top, ok := err.(*Error); ok {
+			return top
+		}
+		return e.Wrap(err)
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, FieldError{Field: fieldPath(fe), Rule: fe.Tag(), message: ruleMessage(fe)})
+		}
+		return ErrValidation.WithFields(fields)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		field := typeErr.Field
+		if field == "" {
+			field = "body"
+		}
+		return ErrValidation.WithFields([]FieldError{{Field: field, Rule: "type", message: i18n.M(i18n.MsgRuleType, typeName(typeErr.Type))}})
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrBadRequest.WithMessage(i18n.MsgBodyInvalidJSON)
+	}
+	if errors.Is(err, io.EOF) {
+		return ErrBadRequest.WithMessage(i18n.MsgBodyEmpty)
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return ErrRequestTooLarge.WithMessage(i18n.MsgBodyTooLarge, maxBytesErr.Limit)
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound.Wrap(err)
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrDuplicate.Wrap(err)
+	}
+	return ErrInternal.Wrap(err)
+}
+
+// fieldPath 字段路径，去掉最外层的结构体名，如 CreateUserRequest.email -> email
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.IndexByte(namespace, '.'); i >= 0 {
+		return namespace[i+1:]
+	}
+	return fe.Field()
+}
+
+// ruleMessage 校验规则对应的提示信息
+func ruleMessage(fe validator.FieldError) i18n.Message {
+	param := fe.Param()
+	isString := fe.Kind() == reflect.String
+	isCollection := fe.Kind() == reflect.Slice || fe.Kind() == reflect.Array || fe.Kind() == reflect.Map
+	switch fe.Tag() {
+	case "required":
+		return i18n.M(i18n.MsgRuleRequired)
+	case "email":
+		return i18n.M(i18n.MsgRuleEmail)
+	case "url", "http_url":
+		return i18n.M(i18n.MsgRuleURL)
+	case "oneof":
+		return i18n.M(i18n.MsgRuleOneOf, strings.Join(strings.Fields(param), ", "))
+	case "min", "gte":
+		switch {
+		case isString:
+			return i18n.M(i18n.MsgRuleMinLength, param)
+		case isCollection:
+			return i18n.M(i18n.MsgRuleMinItems, param)
+		}
+		return i18n.M(i18n.MsgRuleMin, param)
+	case "max", "lte":
+		switch {
+		case isString:
+			return i18n.M(i18n.MsgRuleMaxLength, param)
+		case isCollection:
+			return i18n.M(i18n.MsgRuleMaxItems, param)
+		}
+		return i18n.M(i18n.MsgRuleMax, param)
+	case "gt":
+		return i18n.M(i18n.MsgRuleGreater, param)
+	case "lt":
+		return i18n.M(i18n.MsgRuleLess, param)
+	case "len":
+		if isString {
+			return i18n.M(i18n.MsgRuleLength, param)
+		}
+		return i18n.M(i18n.MsgRuleItems, param)
+	default:
+		return i18n.M(i18n.MsgRuleOther, fe.Tag())
+	}
+}
+
+// typeName JSON 类型的描述
+func typeName(t reflect.Type) i18n.Message {
+	switch t.Kind() {
+	case reflect.String:
+		return i18n.M(i18n.MsgTypeString)
+	case reflect.Bool:
+		return i18n.M(i18n.MsgTypeBool)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return i18n.M(i18n.MsgTypeInteger)
+	case reflect.Float32, reflect.Float64:
+		return i18n.M(i18n.MsgTypeNumber)
+	case reflect.Slice, reflect.Array:
+		return i18n.M(i18n.MsgTypeArray)
+	default:
+		return i18n.M(i18n.MsgTypeObject)
+	}
+}
+
+// JSONFieldName 供校验器使用的字段名：优先使用 json 标签，其次 form 标签，都没有时使用结构体字段名
+func JSONFieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		name := strings.SplitN(field.Tag.Get(key), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
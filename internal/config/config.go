@@ -1,78 +1,237 @@
 package config
 
 import (
-	"os"
+	"net"
+	"net/url"
 	"strconv"
+	"time"
 
+	mysqlDriver "github.com/go-sql-driver/mysql"
 	"github.com/joho/godotenv"
 )
 
+// Config 应用配置
+// 加载顺序（后者覆盖前者）：默认值 -> 配置文件（--config 指定，YAML/TOML）-> 环境变量 -> 命令行参数
+// 字段标签说明：yaml 为配置文件中的键名（TOML 文件使用相同的键名），env 为对应的环境变量，secret 标记的字段支持密钥引用（见 secret.go）且在打印时脱敏
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Log      LogConfig
-	JWT      JWTConfig
+	Server    ServerConfig    `yaml:"server"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Log       LogConfig       `yaml:"log"`
+	JWT       JWTConfig       `yaml:"jwt"`
+	Audit     AuditConfig     `yaml:"audit"`
+	Security  SecurityConfig  `yaml:"security"`
+	CORS      CORSConfig      `yaml:"cors"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Secrets   SecretsConfig   `yaml:"secrets"`
+
+	secretRefs map[string]string // 敏感字段的密钥引用（键为配置键路径），用于轮换时重新读取
 }
 
 type ServerConfig struct {
-	Port string
-	Host string
-	Mode string // debug, release, test
+	Port string `yaml:"port" env:"SERVER_PORT"`
+	Host string `yaml:"host" env:"SERVER_HOST"`
+	Mode string `yaml:"mode" env:"GIN_MODE"` // debug, release, test
+
+	BatchMaxOperations int `yaml:"batch_max_operations" env:"SERVER_BATCH_MAX_OPERATIONS"` // 批量接口单次请求最多包含的操作数
 }
 
 type DatabaseConfig struct {
-	Host        string
-	Port        string
-	User        string
-	Password    string
-	DBName      string
-	DSN         string
-	AutoMigrate bool // 是否自动迁移（开发环境可用，生产环境应设为false）
+	Driver      string `yaml:"driver" env:"DB_DRIVER"` // mysql, postgres, sqlite
+	Host        string `yaml:"host" env:"DB_HOST"`
+	Port        string `yaml:"port" env:"DB_PORT"` // 为空时使用驱动的默认端口（mysql 3306，postgres 5432）
+	User        string `yaml:"user" env:"DB_USER"`
+	Password    Secret `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	DBName      string `yaml:"name" env:"DB_NAME"`
+	Path        string `yaml:"path" env:"DB_PATH"`                 // SQLite 数据库文件路径，:memory: 表示内存数据库
+	DSN         Secret `yaml:"-"`                                  // 由其他字段生成，不从配置文件读取
+	AutoMigrate bool   `yaml:"auto_migrate" env:"DB_AUTO_MIGRATE"` // 启动时是否自动执行未执行的迁移（生产环境应设为false，使用 migrate up 命令）
+
+	// 连接池
+	MaxOpenConns    int `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`         // 最大打开连接数（0表示不限制）
+	MaxIdleConns    int `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`         // 最大空闲连接数
+	ConnMaxLifetime int `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`   // 连接最大生存时间（秒，0表示不限制）
+	ConnMaxIdleTime int `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"` // 连接最大空闲时间（秒，0表示不限制）
+
+	// 超时与重试
+	ConnectTimeout       int `yaml:"connect_timeout" env:"DB_CONNECT_TIMEOUT"`               // 建立连接的超时时间（秒，0表示使用系统默认值）
+	ReadTimeout          int `yaml:"read_timeout" env:"DB_READ_TIMEOUT"`                     // 读超时（秒，0表示不限制，仅 mysql）
+	WriteTimeout         int `yaml:"write_timeout" env:"DB_WRITE_TIMEOUT"`                   // 写超时（秒，0表示不限制，仅 mysql）
+	ConnectRetries       int `yaml:"connect_retries" env:"DB_CONNECT_RETRIES"`               // 启动时连接失败的重试次数（0表示不重试）
+	ConnectRetryInterval int `yaml:"connect_retry_interval" env:"DB_CONNECT_RETRY_INTERVAL"` // 首次重试的等待时间（秒），之后每次翻倍，最长30秒
+	MigrateLockTimeout   int `yaml:"migrate_lock_timeout" env:"DB_MIGRATE_LOCK_TIMEOUT"`     // 等待其他实例释放迁移锁的最长时间（秒）
+
+	TLS     DatabaseTLSConfig     `yaml:"tls"`
+	Params  string                `yaml:"params" env:"DB_PARAMS"` // 附加的 DSN 参数，URL 查询字符串格式，如 collation=utf8mb4_unicode_ci&interpolateParams=true，同名参数覆盖默认值
+	Replica DatabaseReplicaConfig `yaml:"replica"`
+}
+
+// DatabaseTLSConfig 数据库 TLS 配置
+type DatabaseTLSConfig struct {
+	Mode       string `yaml:"mode" env:"DB_TLS_MODE"`               // disable（不加密）, preferred（服务端支持时加密）, skip-verify（加密但不校验证书）, verify（加密并校验证书）
+	CA         string `yaml:"ca" env:"DB_TLS_CA"`                   // CA 证书文件（PEM），为空时使用系统根证书
+	Cert       string `yaml:"cert" env:"DB_TLS_CERT"`               // 客户端证书文件（PEM），需要与 key 同时配置
+	Key        string `yaml:"key" env:"DB_TLS_KEY"`                 // 客户端私钥文件（PEM）
+	ServerName string `yaml:"server_name" env:"DB_TLS_SERVER_NAME"` // 校验证书时使用的服务器名称，为空时使用连接地址中的主机名（仅 mysql）
+}
+
+// DatabaseReplicaConfig 只读副本配置，列表查询和权限查询等读操作分发到副本，写操作和事务内的读操作使用主库
+type DatabaseReplicaConfig struct {
+	Hosts          []string `yaml:"hosts" env:"DB_REPLICA_HOSTS"`                     // 副本地址 host[:port]（环境变量用逗号分隔），未指定端口时与主库相同；用户名、密码、库名、TLS 和附加参数与主库相同，为空表示不启用
+	StickyWindow   int      `yaml:"sticky_window" env:"DB_REPLICA_STICKY_WINDOW"`     // 用户写入后该时间内的读操作都使用主库（秒，0表示只在发生写入的请求内使用主库），应大于副本的复制延迟
+	HealthInterval int      `yaml:"health_interval" env:"DB_REPLICA_HEALTH_INTERVAL"` // 副本健康检查间隔（秒），不可用的副本不再分发读操作，全部不可用时使用主库
 }
 
 type LogConfig struct {
-	Level     string // debug, info, warn, error
-	Format    string // json, text
-	Output    string // stdout, file, both
-	LogFile   string // 请求日志文件路径
-	AuditFile string // 审计日志文件路径
+	Level     string `yaml:"level" env:"LOG_LEVEL"`           // debug, info, warn, error
+	Format    string `yaml:"format" env:"LOG_FORMAT"`         // json, text
+	Output    string `yaml:"output" env:"LOG_OUTPUT"`         // stdout, file, both
+	LogFile   string `yaml:"log_file" env:"APP_LOG_FILE"`     // 请求日志文件路径
+	AuditFile string `yaml:"audit_file" env:"AUDIT_LOG_FILE"` // 审计日志文件路径
 }
 
 type JWTConfig struct {
-	Secret     string // JWT 密钥
-	ExpireTime int    // Token 过期时间（分钟）
+	Secret     Secret `yaml:"secret" env:"JWT_SECRET" secret:"true"` // JWT 密钥
+	ExpireTime int    `yaml:"expire_time" env:"JWT_EXPIRE_TIME"`     // Token 过期时间（分钟）
 }
 
-func LoadConfig() (*Config, error) {
-	// 加载.env文件（如果存在）
-	_ = godotenv.Load()
+type AuditConfig struct {
+	Async           bool   `yaml:"async" env:"AUDIT_ASYNC"`                         // 是否异步批量写入数据库审计日志
+	QueueSize       int    `yaml:"queue_size" env:"AUDIT_QUEUE_SIZE"`               // 审计队列容量
+	BatchSize       int    `yaml:"batch_size" env:"AUDIT_BATCH_SIZE"`               // 每批写入的最大条数
+	FlushInterval   int    `yaml:"flush_interval" env:"AUDIT_FLUSH_INTERVAL"`       // 批量刷新间隔（毫秒）
+	QueueFullPolicy string `yaml:"queue_full_policy" env:"AUDIT_QUEUE_FULL_POLICY"` // 队列满时的策略：block（等待空位，不丢弃）, drop（丢弃并计数）
+	RetentionDays   int    `yaml:"retention_days" env:"AUDIT_RETENTION_DAYS"`       // 审计日志保留天数，超过的记录会被归档并删除（0表示不归档）
+	ArchiveDir      string `yaml:"archive_dir" env:"AUDIT_ARCHIVE_DIR"`             // 归档文件目录
+	ArchiveInterval int    `yaml:"archive_interval" env:"AUDIT_ARCHIVE_INTERVAL"`   // 服务内自动归档的间隔（小时，0表示不自动归档）
+}
+
+type SecurityConfig struct {
+	AlertWindow           int    `yaml:"alert_window" env:"SECURITY_ALERT_WINDOW"`                       // 告警统计窗口（秒）
+	ForbiddenThreshold    int    `yaml:"forbidden_threshold" env:"SECURITY_FORBIDDEN_THRESHOLD"`         // 同一用户在窗口内被拒绝访问的告警阈值（0表示不告警）
+	LoginFailureThreshold int    `yaml:"login_failure_threshold" env:"SECURITY_LOGIN_FAILURE_THRESHOLD"` // 同一邮箱在窗口内登录失败的告警阈值（0表示不告警）
+	InvalidTokenThreshold int    `yaml:"invalid_token_threshold" env:"SECURITY_INVALID_TOKEN_THRESHOLD"` // 同一IP在窗口内使用无效 Token 的告警阈值（0表示不告警）
+	AlertWebhook          Secret `yaml:"alert_webhook" env:"SECURITY_ALERT_WEBHOOK" secret:"true"`       // 告警 Webhook 地址（为空时只写日志）
+}
+
+type CORSConfig struct {
+	AllowedOrigins []string `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"` // 允许跨域访问的来源（环境变量用逗号分隔），* 表示全部，为空表示不启用
+}
 
-	config := &Config{
+type RateLimitConfig struct {
+	RequestsPerSecond int `yaml:"requests_per_second" env:"RATE_LIMIT_RPS"` // 每个客户端IP每秒允许的请求数（0表示不限流）
+	Burst             int `yaml:"burst" env:"RATE_LIMIT_BURST"`             // 允许的突发请求数
+}
+
+type SecretsConfig struct {
+	RefreshInterval int `yaml:"refresh_interval" env:"SECRET_REFRESH_INTERVAL"` // 重新读取密钥引用的间隔（秒，0表示不定期读取）
+}
+
+// Default 返回默认配置
+func Default() *Config {
+	return &Config{
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
-			Host: getEnv("SERVER_HOST", "0.0.0.0"),
-			Mode: getEnv("GIN_MODE", "debug"),
+			Port: "8080",
+			Host: "0.0.0.0",
+			Mode: "debug",
+
+			BatchMaxOperations: 100,
 		},
 		Database: DatabaseConfig{
-			Host:        getEnv("DB_HOST", "localhost"),
-			Port:        getEnv("DB_PORT", "3306"),
-			User:        getEnv("DB_USER", "root"),
-			Password:    getEnv("DB_PASSWORD", ""),
-			DBName:      getEnv("DB_NAME", "testdb"),
-			AutoMigrate: getEnv("DB_AUTO_MIGRATE", "true") == "true", // 默认开启，生产环境应设为false
+			Driver:      "mysql",
+			Host:        "localhost",
+			Port:        "",
+			User:        "root",
+			Password:    "",
+			DBName:      "testdb",
+			Path:        "data/go_web.db",
+			AutoMigrate: true, // 默认开启，生产环境应设为false
+
+			MaxOpenConns:    100,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 3600, // 默认1小时
+			ConnMaxIdleTime: 0,
+
+			ConnectTimeout:       10, // 默认10秒
+			ReadTimeout:          0,
+			WriteTimeout:         0,
+			ConnectRetries:       5,
+			ConnectRetryInterval: 1,  // 默认1秒
+			MigrateLockTimeout:   60, // 默认60秒
+
+			TLS: DatabaseTLSConfig{
+				Mode: "disable",
+			},
+			Replica: DatabaseReplicaConfig{
+				StickyWindow:   5, // 默认5秒
+				HealthInterval: 5, // 默认5秒
+			},
 		},
 		Log: LogConfig{
-			Level:     getEnv("LOG_LEVEL", "info"),
-			Format:    getEnv("LOG_FORMAT", "text"),
-			Output:    getEnv("LOG_OUTPUT", "stdout"),             // stdout, file, both
-			LogFile:   getEnv("APP_LOG_FILE", "logs/app.log"),     // 请求日志文件路径
-			AuditFile: getEnv("AUDIT_LOG_FILE", "logs/audit.log"), // 审计日志文件路径
+			Level:     "info",
+			Format:    "text",
+			Output:    "stdout",         // stdout, file, both
+			LogFile:   "logs/app.log",   // 请求日志文件路径
+			AuditFile: "logs/audit.log", // 审计日志文件路径
 		},
 		JWT: JWTConfig{
-			Secret:     getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
-			ExpireTime: getEnvInt("JWT_EXPIRE_TIME", 1440), // 默认1440分钟（24小时）
+			Secret:     DefaultJWTSecret,
+			ExpireTime: 1440, // 默认1440分钟（24小时）
+		},
+		Audit: AuditConfig{
+			Async:           true,
+			QueueSize:       10000,
+			BatchSize:       100,
+			FlushInterval:   1000, // 默认1000毫秒
+			QueueFullPolicy: "block",
+			RetentionDays:   0,
+			ArchiveDir:      "archive/audit",
+			ArchiveInterval: 0,
+		},
+		Security: SecurityConfig{
+			AlertWindow:           60, // 默认60秒
+			ForbiddenThreshold:    10,
+			LoginFailureThreshold: 5,
+			InvalidTokenThreshold: 20,
+			AlertWebhook:          "",
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: nil,
+		},
+		RateLimit: RateLimitConfig{
+			RequestsPerSecond: 0,
+			Burst:             20,
+		},
+		Secrets: SecretsConfig{
+			RefreshInterval: 60, // 默认60秒
 		},
 	}
+}
+
+// DefaultJWTSecret 默认的 JWT 密钥，release 模式下禁止使用
+const DefaultJWTSecret = "your-secret-key-change-in-production"
+
+// LoadConfig 按 默认值 -> 配置文件 -> 环境变量 -> 命令行参数 的顺序加载配置并校验
+// 任一环节出错都不会立即返回，而是收集全部问题后以 *ValidationError 返回
+func LoadConfig(opts Options) (*Config, error) {
+	// 加载.env文件（如果存在）
+	_ = godotenv.Load()
+
+	config := Default()
+	var problems []string
+
+	if opts.File != "" {
+		if err := loadFile(config, opts.File); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	problems = append(problems, applyEnv(config)...)
+	problems = append(problems, applyOverrides(config, opts.Overrides)...)
+	problems = append(problems, resolveSecrets(config)...)
+	problems = append(problems, config.Validate()...)
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
 
 	// 构建DSN
 	config.Database.DSN = buildDSN(config.Database)
@@ -80,22 +239,121 @@ func LoadConfig() (*Config, error) {
 	return config, nil
 }
 
-func buildDSN(db DatabaseConfig) string {
-	return db.User + ":" + db.Password + "@tcp(" + db.Host + ":" + db.Port + ")/" + db.DBName + "?charset=utf8mb4&parseTime=True&loc=Local"
+// DatabaseTLSConfigName verify 模式下注册到 MySQL 驱动的 TLS 配置名称，由 database 包根据 DatabaseTLSConfig 注册
+const DatabaseTLSConfigName = "go_web"
+
+// defaultDatabasePorts 各驱动的默认端口
+var defaultDatabasePorts = map[string]string{
+	"mysql":    "3306",
+	"postgres": "5432",
+}
+
+// buildDSN 根据驱动生成连接字符串
+func buildDSN(db DatabaseConfig) Secret {
+	switch db.Driver {
+	case "postgres":
+		return buildPostgresDSN(db)
+	case "sqlite":
+		return buildSQLiteDSN(db)
+	default:
+		return buildMySQLDSN(db)
+	}
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// ReplicaDSN 生成只读副本的连接字符串，除地址外与主库相同，addr 未指定端口时使用主库的端口
+func ReplicaDSN(db DatabaseConfig, addr string) Secret {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		host, port = addr, db.Port
 	}
-	return defaultValue
+	db.Host, db.Port = host, port
+	return buildDSN(db)
 }
 
-func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
-		}
+// databaseAddr 返回 host:port，未配置端口时使用驱动的默认端口
+func databaseAddr(db DatabaseConfig) string {
+	port := db.Port
+	if port == "" {
+		port = defaultDatabasePorts[db.Driver]
+	}
+	return net.JoinHostPort(db.Host, port)
+}
+
+// appendParams 将附加参数追加到连接字符串末尾，驱动按顺序解析，同名参数覆盖前面的默认值
+func appendParams(dsn, params string) Secret {
+	if params != "" {
+		dsn += "&" + params
+	}
+	return Secret(dsn)
+}
+
+func buildMySQLDSN(db DatabaseConfig) Secret {
+	dsn := mysqlDriver.NewConfig()
+	dsn.User = db.User
+	dsn.Passwd = db.Password.Value()
+	dsn.Net = "tcp"
+	dsn.Addr = databaseAddr(db)
+	dsn.DBName = db.DBName
+	dsn.Params = map[string]string{"charset": "utf8mb4"}
+	dsn.ParseTime = true
+	dsn.Loc = time.Local
+	dsn.Timeout = time.Duration(db.ConnectTimeout) * time.Second
+	dsn.ReadTimeout = time.Duration(db.ReadTimeout) * time.Second
+	dsn.WriteTimeout = time.Duration(db.WriteTimeout) * time.Second
+
+	switch db.TLS.Mode {
+	case "preferred", "skip-verify":
+		dsn.TLSConfig = db.TLS.Mode
+	case "verify":
+		dsn.TLSConfig = DatabaseTLSConfigName
+	}
+
+	return appendParams(dsn.FormatDSN(), db.Params)
+}
+
+// postgresSSLModes TLS 模式与 PostgreSQL sslmode 的对应关系
+var postgresSSLModes = map[string]string{
+	"disable":     "disable",
+	"preferred":   "prefer",
+	"skip-verify": "require",
+	"verify":      "verify-full",
+}
+
+func buildPostgresDSN(db DatabaseConfig) Secret {
+	query := url.Values{}
+	query.Set("sslmode", postgresSSLModes[db.TLS.Mode])
+	if db.TLS.CA != "" {
+		query.Set("sslrootcert", db.TLS.CA)
+	}
+	if db.TLS.Cert != "" {
+		query.Set("sslcert", db.TLS.Cert)
+		query.Set("sslkey", db.TLS.Key)
+	}
+	if db.ConnectTimeout > 0 {
+		query.Set("connect_timeout", strconv.Itoa(db.ConnectTimeout))
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(db.User, db.Password.Value()),
+		Host:     databaseAddr(db),
+		Path:     "/" + db.DBName,
+		RawQuery: query.Encode(),
+	}
+	return appendParams(dsn.String(), db.Params)
+}
+
+func buildSQLiteDSN(db DatabaseConfig) Secret {
+	query := url.Values{}
+	query.Set("_foreign_keys", "1")
+	query.Set("_busy_timeout", "5000") // 写锁被占用时最多等待5秒，避免并发写入直接失败
+	query.Set("_txlock", "immediate")  // 事务开始时即获取写锁，避免读事务升级为写事务时死锁
+	if db.Path == ":memory:" {
+		// 内存数据库在连接间共享，否则连接池中的每个连接各自是一个空数据库
+		query.Set("mode", "memory")
+		query.Set("cache", "shared")
+		return appendParams("file:go_web?"+query.Encode(), db.Params)
 	}
-	return defaultValue
+	query.Set("_journal_mode", "WAL") // 读写互不阻塞
+	return appendParams("file:"+db.Path+"?"+query.Encode(), db.Params)
 }
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Options 配置加载选项，通常由命令行参数填充
+type Options struct {
+	File      string   // 配置文件路径（.yaml/.yml/.toml）
+	Overrides []string // 命令行覆盖项，格式为 key=value，key 为配置文件中的键路径，如 server.port
+}
+
+// overrideFlag 将命令行参数转换为覆盖项
+// key 为空时参数值本身必须是 key=value 形式（用于 --set）
+type overrideFlag struct {
+	key  string
+	opts *Options
+}
+
+func (f *overrideFlag) String() string { return "" }
+
+func (f *overrideFlag) Set(value string) error {
+	if f.key == "" {
+		if !strings.Contains(value, "=") {
+			return fmt.Errorf("格式应为 key=value: %s", value)
+		}
+		f.opts.Overrides = append(f.opts.Overrides, value)
+		return nil
+	}
+	f.opts.Overrides = append(f.opts.Overrides, f.key+"="+value)
+	return nil
+}
+
+// BindFlags 在 FlagSet 上注册配置相关的命令行参数
+func BindFlags(fs *flag.FlagSet) *Options {
+	opts := &Options{}
+	fs.StringVar(&opts.File, "config", "", "配置文件路径（YAML 或 TOML）")
+	fs.Var(&overrideFlag{opts: opts}, "set", "覆盖任意配置项，格式 key=value，如 --set audit.batch_size=200（可重复）")
+	fs.Var(&overrideFlag{key: "server.host", opts: opts}, "host", "监听地址")
+	fs.Var(&overrideFlag{key: "server.port", opts: opts}, "port", "监听端口")
+	fs.Var(&overrideFlag{key: "server.mode", opts: opts}, "mode", "运行模式（debug/release/test）")
+	fs.Var(&overrideFlag{key: "log.level", opts: opts}, "log-level", "日志级别")
+	fs.Var(&overrideFlag{key: "log.format", opts: opts}, "log-format", "日志格式（text/json）")
+	return opts
+}
+
+// loadFile 读取配置文件，文件中出现未知的键视为错误
+func loadFile(config *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取配置文件失败: %v", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+	case ".toml":
+		// TOML 与 YAML 使用相同的键名，先转换为 YAML 再统一解析
+		var raw map[string]interface{}
+		if err := toml.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("解析配置文件 %s 失败: %v", path, err)
+		}
+		if data, err = yaml.Marshal(raw); err != nil {
+			return fmt.Errorf("解析配置文件 %s 失败: %v", path, err)
+		}
+	default:
+		return fmt.Errorf("不支持的配置文件格式: %s（仅支持 .yaml/.yml/.toml）", path)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("解析配置文件 %s 失败: %v", path, err)
+	}
+	return nil
+}
+
+// applyEnv 使用环境变量覆盖配置，返回无法解析的环境变量
+func applyEnv(config *Config) []string {
+	var problems []string
+	walkFields(config, func(key string, field reflect.StructField, value reflect.Value) {
+		name := field.Tag.Get("env")
+		if name == "" {
+			return
+		}
+		raw := os.Getenv(name)
+		if raw == "" {
+			return
+		}
+		if err := setValue(value, raw); err != nil {
+			problems = append(problems, fmt.Sprintf("环境变量 %s: %v", name, err))
+		}
+	})
+	return problems
+}
+
+// applyOverrides 使用命令行覆盖项覆盖配置，返回无效的覆盖项
+func applyOverrides(config *Config, overrides []string) []string {
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	values := make(map[string]string, len(overrides))
+	for _, override := range overrides {
+		key, value, _ := strings.Cut(override, "=")
+		values[strings.TrimSpace(key)] = value
+	}
+
+	var problems []string
+	walkFields(config, func(key string, _ reflect.StructField, value reflect.Value) {
+		raw, ok := values[key]
+		if !ok {
+			return
+		}
+		delete(values, key)
+		if err := setValue(value, raw); err != nil {
+			problems = append(problems, fmt.Sprintf("命令行参数 %s: %v", key, err))
+		}
+	})
+	for key := range values {
+		problems = append(problems, fmt.Sprintf("命令行参数 %s: 未知的配置项", key))
+	}
+	return problems
+}
+
+// walkFields 遍历配置中的叶子字段，key 为以点分隔的配置文件键路径
+func walkFields(config *Config, fn func(key string, field reflect.StructField, value reflect.Value)) {
+	var walk func(prefix string, v reflect.Value)
+	walk = func(prefix string, v reflect.Value) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := field.Tag.Get("yaml")
+			if name == "" || name == "-" {
+				continue
+			}
+			key := name
+			if prefix != "" {
+				key = prefix + "." + name
+			}
+			if field.Type.Kind() == reflect.Struct {
+				walk(key, v.Field(i))
+				continue
+			}
+			fn(key, field, v.Field(i))
+		}
+	}
+	walk("", reflect.ValueOf(config).Elem())
+}
+
+// setValue 将字符串解析为字段对应的类型并赋值
+func setValue(value reflect.Value, raw string) error {
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Int:
+		i, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("%q 不是有效的整数", raw)
+		}
+		value.SetInt(int64(i))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("%q 不是有效的布尔值（true/false）", raw)
+		}
+		value.SetBool(b)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("不支持的字段类型 %s", value.Type())
+		}
+		// 字符串列表使用逗号分隔
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("不支持的字段类型 %s", value.Kind())
+	}
+	return nil
+}
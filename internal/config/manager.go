@@ -0,0 +1,269 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// hotReloadKeys 可以在运行时热更新的配置项前缀，其余配置项修改后需要重启才能生效
+var hotReloadKeys = []string{
+	"server.batch_max_operations",
+	"log.level",
+	"log.format",
+	"jwt.expire_time",
+	"jwt.secret",        // 轮换后旧密钥签发的 token 在一个 jwt.expire_time 内仍然有效
+	"database.password", // 轮换后新建的数据库连接使用新密码
+	"database.max_open_conns",
+	"database.max_idle_conns",
+	"database.conn_max_lifetime",
+	"database.conn_max_idle_time",
+	"database.replica.sticky_window",
+	"rate_limit.",
+	"cors.",
+}
+
+// reloadDebounce 配置文件变更的合并间隔，避免编辑器多次写入触发多次重新加载
+const reloadDebounce = 500 * time.Millisecond
+
+// ReloadResult 一次重新加载的结果
+type ReloadResult struct {
+	Applied  []string // 已生效的配置项
+	Rejected []string // 不支持热更新而被忽略的配置项（仍使用原值）
+}
+
+// Manager 配置管理器，持有当前生效的配置并支持热更新
+// 需要热更新的组件通过 Current 读取最新配置，或通过 Subscribe 订阅变更
+type Manager struct {
+	opts    Options
+	current atomic.Pointer[Config]
+
+	mu          sync.Mutex // 串行化重新加载和订阅
+	subscribers []func(cfg *Config)
+}
+
+// NewManager 创建配置管理器，opts 用于重新加载时读取相同的配置文件和命令行参数
+func NewManager(cfg *Config, opts Options) *Manager {
+	m := &Manager{opts: opts}
+	m.current.Store(cfg)
+	return m
+}
+
+// Current 返回当前生效的配置，返回值只读
+func (m *Manager) Current() *Config {
+	return m.current.Load()
+}
+
+// Subscribe 订阅配置变更，配置热更新后以新配置调用 fn
+func (m *Manager) Subscribe(fn func(cfg *Config)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscribers = append(m.subscribers, fn)
+}
+
+// Reload 重新加载配置，只应用可热更新的配置项
+// 新配置校验失败时返回错误并保持原配置不变
+func (m *Manager) Reload() (*ReloadResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	loaded, err := LoadConfig(m.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	current := m.Current()
+	oldValues := fieldValues(current)
+	newValues := fieldValues(loaded)
+
+	result := &ReloadResult{}
+	applied := make(map[string]bool)
+	for key, newValue := range newValues {
+		if reflect.DeepEqual(oldValues[key], newValue) {
+			continue
+		}
+		if hotReloadable(key) {
+			applied[key] = true
+			result.Applied = append(result.Applied, key)
+		} else {
+			result.Rejected = append(result.Rejected, key)
+		}
+	}
+	sort.Strings(result.Applied)
+	sort.Strings(result.Rejected)
+	if len(applied) == 0 {
+		return result, nil
+	}
+
+	// 在当前配置的副本上应用可热更新的配置项，然后原子替换
+	next := *current
+	next.secretRefs = make(map[string]string, len(current.secretRefs))
+	for key, ref := range current.secretRefs {
+		next.secretRefs[key] = ref
+	}
+	walkFields(&next, func(key string, _ reflect.StructField, value reflect.Value) {
+		if !applied[key] {
+			return
+		}
+		value.Set(reflect.ValueOf(newValues[key]))
+		// 同步更新已生效字段的密钥引用
+		if ref, ok := loaded.secretRefs[key]; ok {
+			next.secretRefs[key] = ref
+		} else {
+			delete(next.secretRefs, key)
+		}
+	})
+	m.store(&next)
+	return result, nil
+}
+
+// RefreshSecrets 重新读取配置中的密钥引用（如 file:// 指向的文件），密钥轮换后立即生效
+func (m *Manager) RefreshSecrets() (*ReloadResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current := m.Current()
+	result := &ReloadResult{}
+	if len(current.secretRefs) == 0 {
+		return result, nil
+	}
+
+	next := *current
+	var problems []string
+	walkFields(&next, func(key string, _ reflect.StructField, value reflect.Value) {
+		ref, ok := current.secretRefs[key]
+		if !ok {
+			return
+		}
+		provider, rest := lookupSecretProvider(ref)
+		if provider == nil {
+			return
+		}
+		resolved, err := provider.Resolve(rest)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: 解析密钥引用失败: %v", key, err))
+			return
+		}
+		if resolved != value.String() {
+			value.SetString(resolved)
+			result.Applied = append(result.Applied, key)
+		}
+	})
+	if len(problems) > 0 {
+		// 读取失败时保留原密钥，避免因临时故障导致服务不可用
+		return nil, &ValidationError{Problems: problems}
+	}
+	if len(result.Applied) == 0 {
+		return result, nil
+	}
+
+	sort.Strings(result.Applied)
+	m.store(&next)
+	return result, nil
+}
+
+// store 原子替换当前配置并通知订阅者，调用方需持有 m.mu
+func (m *Manager) store(next *Config) {
+	next.Database.DSN = buildDSN(next.Database)
+	m.current.Store(next)
+
+	for _, fn := range m.subscribers {
+		fn(next)
+	}
+}
+
+// Watch 监听配置文件变更和 SIGHUP 信号并重新加载配置，同时定期重新读取密钥引用，直到 ctx 结束
+// 每次重新加载后调用 onReload 报告结果
+func (m *Manager) Watch(ctx context.Context, onReload func(result *ReloadResult, err error)) error {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var fileEvents <-chan fsnotify.Event
+	var fileErrors <-chan error
+	if m.opts.File != "" {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return err
+		}
+		defer watcher.Close()
+
+		// 监听所在目录而不是文件本身，兼容编辑器先写临时文件再重命名的保存方式
+		if err := watcher.Add(filepath.Dir(m.opts.File)); err != nil {
+			return err
+		}
+		fileEvents = watcher.Events
+		fileErrors = watcher.Errors
+	}
+
+	var refreshTick <-chan time.Time
+	if interval := m.Current().Secrets.RefreshInterval; interval > 0 {
+		ticker := time.NewTicker(time.Duration(interval) * time.Second)
+		defer ticker.Stop()
+		refreshTick = ticker.C
+	}
+
+	target := filepath.Clean(m.opts.File)
+	debounce := time.NewTimer(reloadDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	reload := func() {
+		result, err := m.Reload()
+		onReload(result, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-hup:
+			reload()
+		case event := <-fileEvents:
+			if filepath.Clean(event.Name) != target || !event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+				continue
+			}
+			debounce.Reset(reloadDebounce)
+		case <-debounce.C:
+			reload()
+		case <-refreshTick:
+			result, err := m.RefreshSecrets()
+			// 密钥没有变化时不报告，避免定期日志
+			if err != nil || len(result.Applied) > 0 {
+				onReload(result, err)
+			}
+		case err := <-fileErrors:
+			onReload(nil, err)
+		}
+	}
+}
+
+// fieldValues 以配置键路径为键收集全部配置项的值
+func fieldValues(cfg *Config) map[string]interface{} {
+	values := make(map[string]interface{})
+	walkFields(cfg, func(key string, _ reflect.StructField, value reflect.Value) {
+		values[key] = value.Interface()
+	})
+	return values
+}
+
+// hotReloadable 判断配置项是否可以热更新
+func hotReloadable(key string) bool {
+	for _, prefix := range hotReloadKeys {
+		if key == prefix || (strings.HasSuffix(prefix, ".") && strings.HasPrefix(key, prefix)) {
+			return true
+		}
+	}
+	return false
+}
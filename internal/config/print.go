@@ -0,0 +1,52 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// redactedValue 脱敏后显示的值
+const redactedValue = "******"
+
+// Redacted 返回配置的副本，secret 标记的字段已脱敏
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redacted.Database.DSN = ""
+	redacted.secretRefs = nil
+	walkFields(&redacted, func(_ string, field reflect.StructField, value reflect.Value) {
+		if field.Tag.Get("secret") == "true" && value.Kind() == reflect.String && value.String() != "" {
+			value.SetString(redactedValue)
+		}
+	})
+	return &redacted
+}
+
+// Print 以指定格式（yaml/toml）输出脱敏后的配置
+func (c *Config) Print(w io.Writer, format string) error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	data := buf.Bytes()
+
+	switch format {
+	case "yaml", "yml":
+		_, err := w.Write(data)
+		return err
+	case "toml":
+		var raw map[string]interface{}
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		return toml.NewEncoder(w).Encode(raw)
+	default:
+		return fmt.Errorf("不支持的输出格式: %s（可选 yaml/toml）", format)
+	}
+}
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// 主密钥相关的环境变量，主密钥只从环境变量或文件读取，不能写在配置文件中
+const (
+	MasterKeyEnv     = "CONFIG_MASTER_KEY"      // base64 编码的 32 字节密钥
+	MasterKeyFileEnv = "CONFIG_MASTER_KEY_FILE" // 存放 base64 编码密钥的文件路径
+)
+
+// Secret 敏感配置值
+// 格式化输出、JSON 和 YAML 序列化时均显示为脱敏值，需要明文时调用 Value
+type Secret string
+
+// Value 返回明文
+func (s Secret) Value() string {
+	return string(s)
+}
+
+// String 实现 fmt.Stringer，避免明文出现在日志中
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redactedValue
+}
+
+// GoString 实现 fmt.GoStringer，避免 %#v 输出明文
+func (s Secret) GoString() string {
+	return `config.Secret("` + s.String() + `")`
+}
+
+// MarshalJSON 序列化为脱敏值
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + s.String() + `"`), nil
+}
+
+// MarshalYAML 序列化为脱敏值
+func (s Secret) MarshalYAML() (interface{}, error) {
+	return s.String(), nil
+}
+
+// literalSecretPrefix 明文前缀，以 literal: 开头的敏感值去掉前缀后原样使用，不作为密钥引用解析
+// 用于本身以 file:、env:、enc: 等已注册 scheme 开头的明文，如 literal:env:xyz 表示明文 env:xyz
+const literalSecretPrefix = "literal:"
+
+// SecretProvider 密钥提供者，将密钥引用解析为明文
+// 配置中形如 scheme:ref 的敏感值会交给对应 scheme 的提供者解析，以 literal: 开头的值除外
+type SecretProvider interface {
+	// Resolve 解析引用（不含 scheme: 前缀），返回的错误中不能包含明文
+	Resolve(ref string) (string, error)
+}
+
+var (
+	secretProvidersMu sync.RWMutex
+	secretProviders   = map[string]SecretProvider{
+		"file": fileSecretProvider{},
+		"env":  envSecretProvider{},
+		"enc":  encryptedSecretProvider{},
+	}
+)
+
+// RegisterSecretProvider 注册密钥提供者，已存在的 scheme 会被替换
+// 需要在加载配置之前调用，例如接入 Vault 等外部密钥管理服务
+func RegisterSecretProvider(scheme string, provider SecretProvider) {
+	secretProvidersMu.Lock()
+	defer secretProvidersMu.Unlock()
+	secretProviders[scheme] = provider
+}
+
+// lookupSecretProvider 根据值的 scheme 前缀查找提供者，不是引用时返回 nil
+func lookupSecretProvider(value string) (SecretProvider, string) {
+	scheme, ref, ok := strings.Cut(value, ":")
+	if !ok {
+		return nil, ""
+	}
+	secretProvidersMu.RLock()
+	defer secretProvidersMu.RUnlock()
+	return secretProviders[scheme], ref
+}
+
+// resolveSecrets 解析配置中全部敏感字段的密钥引用，并记录引用以便轮换时重新读取
+func resolveSecrets(config *Config) []string {
+	var problems []string
+	refs := make(map[string]string)
+	walkFields(config, func(key string, field reflect.StructField, value reflect.Value) {
+		if field.Tag.Get("secret") != "true" || value.Kind() != reflect.String {
+			return
+		}
+		raw := value.String()
+		if literal, ok := strings.CutPrefix(raw, literalSecretPrefix); ok {
+			value.SetString(literal)
+			return
+		}
+		provider, ref := lookupSecretProvider(raw)
+		if provider == nil {
+			return
+		}
+		resolved, err := provider.Resolve(ref)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: 解析密钥引用失败: %v", key, err))
+			return
+		}
+		refs[key] = raw
+		value.SetString(resolved)
+	})
+	config.secretRefs = refs
+	return problems
+}
+
+// fileSecretProvider 从文件读取密钥，引用格式 file:///run/secrets/db_password
+type fileSecretProvider struct{}
+
+func (fileSecretProvider) Resolve(ref string) (string, error) {
+	path := strings.TrimPrefix(ref, "//")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("读取密钥文件 %s 失败: %v", path, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// envSecretProvider 从其他环境变量读取密钥，引用格式 env:DB_PASSWORD_PROD
+type envSecretProvider struct{}
+
+func (envSecretProvider) Resolve(ref string) (string, error) {
+	value, ok := os.LookupEnv(ref)
+	if !ok || value == "" {
+		return "", fmt.Errorf("环境变量 %s 未设置", ref)
+	}
+	return value, nil
+}
+
+// encryptedSecretProvider 使用主密钥解密，引用格式 enc:<base64(nonce+密文)>，算法为 AES-256-GCM
+type encryptedSecretProvider struct{}
+
+func (encryptedSecretProvider) Resolve(ref string) (string, error) {
+	gcm, err := masterKeyCipher()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(ref)
+	if err != nil {
+		return "", errors.New("密文不是有效的 base64 编码")
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("密文长度无效")
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.New("解密失败，请检查主密钥是否正确")
+	}
+	return string(plaintext), nil
+}
+
+// EncryptSecret 使用主密钥加密明文，返回可以写入配置的 enc: 引用
+func EncryptSecret(plaintext string) (string, error) {
+	gcm, err := masterKeyCipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return "enc:" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// masterKeyCipher 读取主密钥并创建 AES-GCM 实例
+func masterKeyCipher() (cipher.AEAD, error) {
+	encoded := os.Getenv(MasterKeyEnv)
+	if encoded == "" {
+		if path := os.Getenv(MasterKeyFileEnv); path != "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("读取主密钥文件失败: %v", err)
+			}
+			encoded = strings.TrimSpace(string(data))
+		}
+	}
+	if encoded == "" {
+		return nil, fmt.Errorf("未设置主密钥（%s 或 %s）", MasterKeyEnv, MasterKeyFileEnv)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("主密钥应为 base64 编码的 32 字节密钥")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestLoadConfigSecrets 敏感配置中的密钥引用在加载时解析，解析失败时返回错误而不是使用原值
+func TestLoadConfigSecrets(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		want     string
+		wantErr  bool
+	}{
+		{name: "plaintext", password: "secret", want: "secret"},
+		{name: "env reference", password: "env:GO_WEB_TEST_DB_PASSWORD", want: "from-env"},
+		{name: "literal", password: "literal:env:xyz", want: "env:xyz"},
+		{name: "unresolved reference", password: "env:GO_WEB_TEST_MISSING", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GO_WEB_TEST_DB_PASSWORD", "from-env")
+			t.Setenv("DB_PASSWORD", tt.password)
+
+			cfg, err := LoadConfig(Options{})
+			if tt.wantErr {
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) || len(validationErr.Problems) != 1 || !strings.HasPrefix(validationErr.Problems[0], "database.password:") {
+					t.Fatalf("err = %v, want a database.password problem", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := cfg.Database.Password.Value(); got != tt.want {
+				t.Errorf("password = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+)
+
+// ValidationError 配置校验错误，包含全部问题
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "配置无效:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Validate 校验配置，返回全部问题（为空表示配置有效）
+func (c *Config) Validate() []string {
+	var problems []string
+	addf := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	// 服务器
+	if !oneOf(c.Server.Mode, "debug", "release", "test") {
+		addf("server.mode: 未知的 GIN_MODE %q（可选 debug/release/test）", c.Server.Mode)
+	}
+	if !validPort(c.Server.Port) {
+		addf("server.port: 无效的端口 %q（应为 1-65535）", c.Server.Port)
+	}
+	if c.Server.BatchMaxOperations < 1 || c.Server.BatchMaxOperations > 1000 {
+		addf("server.batch_max_operations: 应为 1-1000，当前为 %d", c.Server.BatchMaxOperations)
+	}
+
+	// 数据库
+	switch c.Database.Driver {
+	case "mysql", "postgres":
+		if c.Database.Host == "" {
+			addf("database.host: 不能为空")
+		}
+		if c.Database.Port != "" && !validPort(c.Database.Port) {
+			addf("database.port: 无效的端口 %q（应为 1-65535）", c.Database.Port)
+		}
+		if c.Database.User == "" {
+			addf("database.user: 不能为空")
+		}
+		if c.Database.DBName == "" {
+			addf("database.name: 不能为空")
+		}
+	case "sqlite":
+		if c.Database.Path == "" {
+			addf("database.path: 使用 sqlite 时不能为空")
+		}
+	default:
+		addf("database.driver: 未知的数据库驱动 %q（可选 mysql/postgres/sqlite）", c.Database.Driver)
+	}
+	for _, field := range []struct {
+		key   string
+		value int
+	}{
+		{"database.max_open_conns", c.Database.MaxOpenConns},
+		{"database.max_idle_conns", c.Database.MaxIdleConns},
+		{"database.conn_max_lifetime", c.Database.ConnMaxLifetime},
+		{"database.conn_max_idle_time", c.Database.ConnMaxIdleTime},
+		{"database.connect_timeout", c.Database.ConnectTimeout},
+		{"database.read_timeout", c.Database.ReadTimeout},
+		{"database.write_timeout", c.Database.WriteTimeout},
+		{"database.connect_retries", c.Database.ConnectRetries},
+		{"database.connect_retry_interval", c.Database.ConnectRetryInterval},
+		{"database.migrate_lock_timeout", c.Database.MigrateLockTimeout},
+		{"database.replica.sticky_window", c.Database.Replica.StickyWindow},
+	} {
+		if field.value < 0 {
+			addf("%s: 不能为负数（当前为 %d）", field.key, field.value)
+		}
+	}
+	if !oneOf(c.Database.TLS.Mode, "disable", "preferred", "skip-verify", "verify") {
+		addf("database.tls.mode: 未知的 TLS 模式 %q（可选 disable/preferred/skip-verify/verify）", c.Database.TLS.Mode)
+	} else if c.Database.TLS.Mode != "verify" && (c.Database.TLS.CA != "" || c.Database.TLS.Cert != "" || c.Database.TLS.ServerName != "") {
+		addf("database.tls: ca/cert/server_name 仅在 verify 模式下生效")
+	}
+	if (c.Database.TLS.Cert == "") != (c.Database.TLS.Key == "") {
+		addf("database.tls: cert 和 key 需要同时配置")
+	}
+	if c.Database.Driver != "mysql" {
+		// 以下配置项只有 MySQL 驱动支持，配置后不生效容易造成误解
+		if c.Database.ReadTimeout > 0 || c.Database.WriteTimeout > 0 {
+			addf("database.read_timeout/write_timeout: 仅 mysql 驱动支持")
+		}
+		if c.Database.TLS.ServerName != "" {
+			addf("database.tls.server_name: 仅 mysql 驱动支持")
+		}
+	}
+	if c.Database.Driver == "sqlite" && c.Database.TLS.Mode != "disable" {
+		addf("database.tls.mode: sqlite 不支持 TLS")
+	}
+	if c.Database.Driver == "sqlite" && !c.Audit.Async {
+		// SQLite 同一时间只允许一个写事务，事务中同步写审计日志会等待事务自身释放写锁
+		addf("audit.async: 使用 sqlite 时必须开启异步写入审计日志")
+	}
+	if len(c.Database.Replica.Hosts) > 0 {
+		if c.Database.Driver == "sqlite" {
+			addf("database.replica.hosts: sqlite 不支持只读副本")
+		}
+		for _, host := range c.Database.Replica.Hosts {
+			if !validReplicaHost(host) {
+				addf("database.replica.hosts: 无效的副本地址 %q（应为 host 或 host:port）", host)
+			}
+		}
+		if c.Database.Replica.HealthInterval <= 0 {
+			addf("database.replica.health_interval: 必须大于0（当前为 %d）", c.Database.Replica.HealthInterval)
+		}
+	}
+	if c.Database.Params != "" {
+		if _, err := url.ParseQuery(c.Database.Params); err != nil {
+			addf("database.params: 无效的参数格式（应为 key=value&key=value）: %v", err)
+		} else if c.Database.Driver == "mysql" {
+			// 使用驱动解析生成的 DSN，提前发现无效的参数值；TLS 配置在连接数据库时才注册，这里不参与校验
+			db := c.Database
+			db.TLS.Mode = "disable"
+			if _, err := mysqlDriver.ParseDSN(buildDSN(db).Value()); err != nil {
+				addf("database.params: %v", err)
+			}
+		}
+	}
+
+	// 日志
+	if !oneOf(c.Log.Level, "trace", "debug", "info", "warn", "warning", "error", "fatal", "panic") {
+		addf("log.level: 未知的日志级别 %q（可选 debug/info/warn/error）", c.Log.Level)
+	}
+	if !oneOf(c.Log.Format, "text", "json") {
+		addf("log.format: 未知的日志格式 %q（可选 text/json）", c.Log.Format)
+	}
+	if !oneOf(strings.ToLower(c.Log.Output), "stdout", "file", "both") {
+		addf("log.output: 未知的日志输出 %q（可选 stdout/file/both）", c.Log.Output)
+	}
+
+	// JWT
+	if c.JWT.Secret == "" {
+		addf("jwt.secret: 不能为空")
+	} else if c.Server.Mode == "release" && c.JWT.Secret == DefaultJWTSecret {
+		addf("jwt.secret: release 模式下必须修改默认密钥")
+	}
+	if c.JWT.ExpireTime <= 0 {
+		addf("jwt.expire_time: 必须大于0（当前为 %d）", c.JWT.ExpireTime)
+	}
+
+	// 审计
+	if c.Audit.QueueSize <= 0 {
+		addf("audit.queue_size: 必须大于0（当前为 %d）", c.Audit.QueueSize)
+	}
+	if c.Audit.BatchSize <= 0 {
+		addf("audit.batch_size: 必须大于0（当前为 %d）", c.Audit.BatchSize)
+	}
+	if c.Audit.FlushInterval <= 0 {
+		addf("audit.flush_interval: 必须大于0（当前为 %d）", c.Audit.FlushInterval)
+	}
+	if !oneOf(c.Audit.QueueFullPolicy, "block", "drop") {
+		addf("audit.queue_full_policy: 未知的策略 %q（可选 block/drop）", c.Audit.QueueFullPolicy)
+	}
+	if c.Audit.RetentionDays < 0 {
+		addf("audit.retention_days: 不能为负数（当前为 %d）", c.Audit.RetentionDays)
+	}
+	if c.Audit.ArchiveInterval < 0 {
+		addf("audit.archive_interval: 不能为负数（当前为 %d）", c.Audit.ArchiveInterval)
+	}
+
+	// 安全告警
+	if c.Security.AlertWindow <= 0 {
+		addf("security.alert_window: 必须大于0（当前为 %d）", c.Security.AlertWindow)
+	}
+	if c.Security.ForbiddenThreshold < 0 {
+		addf("security.forbidden_threshold: 不能为负数（当前为 %d）", c.Security.ForbiddenThreshold)
+	}
+	if c.Security.LoginFailureThreshold < 0 {
+		addf("security.login_failure_threshold: 不能为负数（当前为 %d）", c.Security.LoginFailureThreshold)
+	}
+	if c.Security.InvalidTokenThreshold < 0 {
+		addf("security.invalid_token_threshold: 不能为负数（当前为 %d）", c.Security.InvalidTokenThreshold)
+	}
+	if c.Security.AlertWebhook != "" {
+		if u, err := url.Parse(c.Security.AlertWebhook.Value()); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			addf("security.alert_webhook: 应为 http(s) 地址")
+		}
+	}
+
+	// 跨域与限流
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" {
+			addf("cors.allowed_origins: 无效的来源 %q（应为 * 或 scheme://host[:port]）", origin)
+		}
+	}
+	if c.RateLimit.RequestsPerSecond < 0 {
+		addf("rate_limit.requests_per_second: 不能为负数（当前为 %d）", c.RateLimit.RequestsPerSecond)
+	}
+	if c.RateLimit.RequestsPerSecond > 0 && c.RateLimit.Burst <= 0 {
+		addf("rate_limit.burst: 启用限流时必须大于0（当前为 %d）", c.RateLimit.Burst)
+	}
+
+	if c.Secrets.RefreshInterval < 0 {
+		addf("secrets.refresh_interval: 不能为负数（当前为 %d）", c.Secrets.RefreshInterval)
+	}
+
+	return problems
+}
+
+// validPort 判断端口是否在 1-65535 之间
+func validPort(port string) bool {
+	p, err := strconv.Atoi(port)
+	return err == nil && p > 0 && p <= 65535
+}
+
+// validReplicaHost 校验副本地址，格式为 host 或 host:port
+func validReplicaHost(addr string) bool {
+	if strings.ContainsAny(addr, "/@?") {
+		return false
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr != "" && !strings.Contains(addr, ":")
+	}
+	return host != "" && validPort(port)
+}
+
+// oneOf 判断值是否在可选值中
+func oneOf(value string, options ...string) bool {
+	for _, option := range options {
+		if value == option {
+			return true
+		}
+	}
+	return false
+}
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sync"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Context key types for audit information
@@ -15,26 +17,41 @@ import (
 type auditUserIDKeyType struct{}
 type auditIPKeyType struct{}
 type auditOldValuesKeyType struct{}
+type auditRequestIDKeyType struct{}
+type auditSkipKeyType struct{}
+type auditActorKeyType struct{}
 
 // Context keys for audit information
 var (
 	AuditUserIDKey    = auditUserIDKeyType{}
 	AuditIPKey        = auditIPKeyType{}
 	AuditOldValuesKey = auditOldValuesKeyType{} // 用于存储更新前的旧值
+	AuditRequestIDKey = auditRequestIDKeyType{} // 用于关联同一次 HTTP 请求产生的审计记录
+	AuditSkipKey      = auditSkipKeyType{}      // 值为 true 时插件不自动记录，由调用方自行写入审计日志
+	AuditActorKey     = auditActorKeyType{}     // 操作来源，见 AuditActorHTTP、AuditActorCLI
+)
+
+// 审计日志的操作来源
+const (
+	AuditActorHTTP = "http" // HTTP 接口请求
+	AuditActorCLI  = "cli"  // 命令行子命令
 )
 
 // AuditLog 审计日志模型
 type AuditLog struct {
-	ID        uint      `gorm:"primarykey"`
-	CreatedAt time.Time `gorm:"index"`
-
-	ModelTableName string `gorm:"type:varchar(100);index;column:table_name"` // 表名，使用column标签避免与方法名冲突
-	RecordID       uint   `gorm:"index"`
-	Action         string `gorm:"type:varchar(20);index"` // create, update, delete
-	OldValues      string `gorm:"type:text"`
-	NewValues      string `gorm:"type:text"`
-	UserID         uint   `gorm:"index"`
-	IP             string `gorm:"type:varchar(50)"`
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	ModelTableName string `gorm:"type:varchar(100);index;column:table_name" json:"table_name"` // 表名，使用column标签避免与方法名冲突
+	RecordID       uint   `gorm:"index" json:"record_id"`
+	Action         string `gorm:"type:varchar(20);index" json:"action"` // create, update, delete, restore, purge, archive, revert
+	OldValues      string `gorm:"type:text" json:"old_values"`
+	NewValues      string `gorm:"type:text" json:"new_values"`
+	UserID         uint   `gorm:"index" json:"user_id"`
+	Actor          string `gorm:"type:varchar(50);index" json:"actor"` // 操作来源（http/cli），为空表示服务内部操作
+	IP             string `gorm:"type:varchar(50)" json:"ip"`
+	RequestID      string `gorm:"type:varchar(64);index" json:"request_id"` // 产生该记录的 HTTP 请求ID
+	RefID          uint   `gorm:"index" json:"ref_id"`                      // 引用的审计日志ID（revert 操作指向被回滚的记录）
 }
 
 // TableName 指定表名
@@ -42,14 +59,23 @@ func (AuditLog) TableName() string {
 	return "audit_logs"
 }
 
+// auditSkipTables 不记录审计日志的表（审计与安全日志表本身，以及迁移记录表）
+var auditSkipTables = map[string]bool{
+	"audit_logs":        true,
+	"http_audit_events": true,
+	"security_events":   true,
+	"schema_migrations": true,
+}
+
 // AuditPlugin GORM审计插件
 type AuditPlugin struct {
-	db *gorm.DB
+	db     *gorm.DB
+	writer *AuditWriter // 审计日志写入器（为空时同步写入）
 }
 
 // NewAuditPlugin 创建审计插件
-func NewAuditPlugin(db *gorm.DB) *AuditPlugin {
-	return &AuditPlugin{db: db}
+func NewAuditPlugin(db *gorm.DB, writer *AuditWriter) *AuditPlugin {
+	return &AuditPlugin{db: db, writer: writer}
 }
 
 // Name 返回插件名称
@@ -61,6 +87,11 @@ func (p *AuditPlugin) Name() string {
 func (p *AuditPlugin) Initialize(db *gorm.DB) error {
 	p.db = db
 
+	// 包装连接池，事务内产生的审计日志在最外层事务提交后才写入
+	pool := &auditConnPool{ConnPool: db.ConnPool}
+	db.ConnPool = pool
+	db.Statement.ConnPool = pool
+
 	// 注册回调
 	callback := db.Callback()
 
@@ -68,6 +99,7 @@ func (p *AuditPlugin) Initialize(db *gorm.DB) error {
 	callback.Create().After("gorm:create").Register("audit:create", p.auditCreate)
 
 	// Update回调：在更新前获取旧值，在更新后记录审计日志
+	// 旧值查询在语句所在的事务中按主键执行，审计日志的旧值快照是审计回滚（revert）和彻底删除后恢复关联的依据，不能省略
 	callback.Update().Before("gorm:update").Register("audit:before_update", p.auditBeforeUpdate)
 	callback.Update().After("gorm:update").Register("audit:update", p.auditUpdate)
 
@@ -75,6 +107,9 @@ func (p *AuditPlugin) Initialize(db *gorm.DB) error {
 	callback.Delete().Before("gorm:delete").Register("audit:before_delete", p.auditBeforeDelete)
 	callback.Delete().After("gorm:delete").Register("audit:delete", p.auditDelete)
 
+	// 嵌套事务回滚到保存点时丢弃保存点之后暂存的审计日志
+	callback.Raw().After("gorm:raw").Register("audit:savepoint", trackSavepoint)
+
 	return nil
 }
 
@@ -84,8 +119,8 @@ func (p *AuditPlugin) auditCreate(db *gorm.DB) {
 		return
 	}
 
-	// 跳过审计日志表自身的操作，避免递归
-	if db.Statement.Schema != nil && db.Statement.Schema.Table == "audit_logs" {
+	// 跳过审计相关表自身的操作，避免递归
+	if p.skipTable(db) {
 		return
 	}
 
@@ -116,17 +151,19 @@ func (p *AuditPlugin) auditCreate(db *gorm.DB) {
 		OldValues:      "", // 创建操作没有旧值
 		NewValues:      newValues,
 		UserID:         userID,
+		Actor:          p.getActor(db),
 		IP:             ip,
+		RequestID:      p.getRequestID(db),
 	}
 
-	// 使用新的数据库连接保存审计日志，避免影响原事务
-	p.db.Session(&gorm.Session{NewDB: true}).Create(&auditLog)
+	// 交给写入器保存审计日志，避免影响原事务
+	p.write(db, &auditLog)
 }
 
 // auditBeforeUpdate 在更新前获取旧值并存储到context中
 func (p *AuditPlugin) auditBeforeUpdate(db *gorm.DB) {
-	// 跳过审计日志表自身的操作
-	if db.Statement.Schema != nil && db.Statement.Schema.Table == "audit_logs" {
+	// 跳过审计相关表自身的操作
+	if p.skipTable(db) {
 		return
 	}
 
@@ -147,8 +184,12 @@ func (p *AuditPlugin) auditBeforeUpdate(db *gorm.DB) {
 		oldModel := reflect.New(db.Statement.Schema.ModelType).Interface()
 		// 使用新的数据库连接查询，避免影响原事务
 		// 使用 NewDB: true 确保使用独立的连接，避免事务隔离问题
-		// 但保留原 context，以便后续回调可以访问
-		oldDB := db.Session(&gorm.Session{NewDB: true})
+		// 但保留原 context，以便后续回调可以访问；旧值必须读主库，避免读到副本上的旧数据
+		oldDB := db.Session(&gorm.Session{NewDB: true, Context: UsePrimary(db.Statement.Context)})
+		// 恢复（Unscoped 更新）时记录处于软删除状态
+		if db.Statement.Unscoped {
+			oldDB = oldDB.Unscoped()
+		}
 		if err := oldDB.First(oldModel, recordID).Error; err == nil {
 			oldValues := p.serializeModel(oldModel)
 			// 将旧值存储到context中
@@ -168,12 +209,13 @@ func (p *AuditPlugin) auditBeforeUpdate(db *gorm.DB) {
 
 // auditUpdate 记录更新操作的审计日志
 func (p *AuditPlugin) auditUpdate(db *gorm.DB) {
-	if db.Error != nil {
+	// 条件不满足（如版本号不一致）未影响任何记录时不记录
+	if db.Error != nil || db.RowsAffected == 0 {
 		return
 	}
 
-	// 跳过审计日志表自身的操作
-	if db.Statement.Schema != nil && db.Statement.Schema.Table == "audit_logs" {
+	// 跳过审计相关表自身的操作
+	if p.skipTable(db) {
 		return
 	}
 
@@ -198,17 +240,17 @@ func (p *AuditPlugin) auditUpdate(db *gorm.DB) {
 		}
 	}
 
-	// 如果context中没有旧值，尝试查询（兼容性处理）
-	if oldValues == "" && db.Statement.Schema != nil {
-		oldModel := reflect.New(db.Statement.Schema.ModelType).Interface()
-		// 使用 Unscoped 查询，因为可能已经被软删除
-		if err := p.db.Session(&gorm.Session{NewDB: true}).Unscoped().First(oldModel, recordID).Error; err == nil {
-			oldValues = p.serializeModel(oldModel)
+	action := "update"
+	newValues := p.serializeModel(db.Statement.Dest)
+	// 以 map 更新部分字段时只记录这些字段的新旧值
+	if fields, ok := db.Statement.Dest.(map[string]interface{}); ok {
+		oldValues, newValues = p.serializeFields(db, oldValues, fields)
+		// 清空软删除字段为恢复已删除的记录，记为 restore
+		if value, ok := fields["deleted_at"]; ok && value == nil {
+			action = "restore"
 		}
 	}
 
-	newValues := p.serializeModel(db.Statement.Dest)
-
 	// 获取用户ID和IP
 	userID := p.getUserID(db)
 	ip := p.getIP(db)
@@ -217,21 +259,23 @@ func (p *AuditPlugin) auditUpdate(db *gorm.DB) {
 	auditLog := AuditLog{
 		ModelTableName: tableName,
 		RecordID:       recordID,
-		Action:         "update",
+		Action:         action,
 		OldValues:      oldValues,
 		NewValues:      newValues,
 		UserID:         userID,
+		Actor:          p.getActor(db),
 		IP:             ip,
+		RequestID:      p.getRequestID(db),
 	}
 
-	// 使用新的数据库连接保存审计日志
-	p.db.Session(&gorm.Session{NewDB: true}).Create(&auditLog)
+	// 交给写入器保存审计日志
+	p.write(db, &auditLog)
 }
 
 // auditBeforeDelete 在删除前获取旧值并存储到context中
 func (p *AuditPlugin) auditBeforeDelete(db *gorm.DB) {
-	// 跳过审计日志表自身的操作
-	if db.Statement.Schema != nil && db.Statement.Schema.Table == "audit_logs" {
+	// 跳过审计相关表自身的操作
+	if p.skipTable(db) {
 		return
 	}
 
@@ -252,8 +296,12 @@ func (p *AuditPlugin) auditBeforeDelete(db *gorm.DB) {
 		oldModel := reflect.New(db.Statement.Schema.ModelType).Interface()
 		// 使用新的数据库连接查询，避免影响原事务
 		// 使用 NewDB: true 确保使用独立的连接，避免事务隔离问题
-		// 但保留原 context，以便后续回调可以访问
-		oldDB := db.Session(&gorm.Session{NewDB: true})
+		// 但保留原 context，以便后续回调可以访问；旧值必须读主库，避免读到副本上的旧数据
+		oldDB := db.Session(&gorm.Session{NewDB: true, Context: UsePrimary(db.Statement.Context)})
+		// 彻底删除（Unscoped）时记录可能已被软删除
+		if db.Statement.Unscoped {
+			oldDB = oldDB.Unscoped()
+		}
 		if err := oldDB.First(oldModel, recordID).Error; err == nil {
 			oldValues := p.serializeModel(oldModel)
 			// 将旧值存储到context中
@@ -273,12 +321,13 @@ func (p *AuditPlugin) auditBeforeDelete(db *gorm.DB) {
 
 // auditDelete 记录删除操作的审计日志
 func (p *AuditPlugin) auditDelete(db *gorm.DB) {
-	if db.Error != nil {
+	// 条件不满足（如版本号不一致）未影响任何记录时不记录
+	if db.Error != nil || db.RowsAffected == 0 {
 		return
 	}
 
-	// 跳过审计日志表自身的操作
-	if db.Statement.Schema != nil && db.Statement.Schema.Table == "audit_logs" {
+	// 跳过审计相关表自身的操作
+	if p.skipTable(db) {
 		return
 	}
 
@@ -303,32 +352,121 @@ func (p *AuditPlugin) auditDelete(db *gorm.DB) {
 		}
 	}
 
-	// 如果context中没有旧值，尝试查询（兼容性处理）
-	if oldValues == "" && db.Statement.Schema != nil {
-		oldModel := reflect.New(db.Statement.Schema.ModelType).Interface()
-		// 使用 Unscoped 查询，因为可能已经被软删除
-		if err := p.db.Session(&gorm.Session{NewDB: true}).Unscoped().First(oldModel, recordID).Error; err == nil {
-			oldValues = p.serializeModel(oldModel)
-		}
-	}
-
 	// 获取用户ID和IP
 	userID := p.getUserID(db)
 	ip := p.getIP(db)
 
+	// 带软删除字段的模型以 Unscoped 删除时为彻底删除，记为 purge
+	action := "delete"
+	if db.Statement.Unscoped && db.Statement.Schema != nil && db.Statement.Schema.LookUpField("DeletedAt") != nil {
+		action = "purge"
+	}
+
 	// 创建审计日志
 	auditLog := AuditLog{
 		ModelTableName: tableName,
 		RecordID:       recordID,
-		Action:         "delete",
+		Action:         action,
 		OldValues:      oldValues,
 		NewValues:      "", // 删除操作没有新值
 		UserID:         userID,
+		Actor:          p.getActor(db),
 		IP:             ip,
+		RequestID:      p.getRequestID(db),
+	}
+
+	// 交给写入器保存审计日志
+	p.write(db, &auditLog)
+}
+
+// write 保存审计日志：context 设置了暂存区时暂存到暂存区，由调用方提交后写入；在事务中执行时暂存到事务，最外层事务提交后写入；
+// 否则语句已自动提交，直接写入。写入时调用方已不占用数据库连接，写入器的 block 策略可以一直等待队列空位
+func (p *AuditPlugin) write(db *gorm.DB, auditLog *AuditLog) {
+	if db.Statement.Context != nil {
+		if buffer, ok := db.Statement.Context.Value(auditBufferKeyType{}).(*AuditBuffer); ok {
+			buffer.add(p, auditLog)
+			return
+		}
+	}
+	if tx, ok := db.Statement.ConnPool.(*auditTx); ok {
+		tx.buffer.add(p, auditLog)
+		return
 	}
+	p.flush(auditLog)
+}
+
+// flush 写入审计日志
+func (p *AuditPlugin) flush(auditLog *AuditLog) {
+	if p.writer != nil {
+		p.writer.Write(auditLog)
+		return
+	}
+	p.db.Session(&gorm.Session{NewDB: true}).Create(auditLog)
+}
+
+type auditBufferKeyType struct{}
 
-	// 使用新的数据库连接保存审计日志
-	p.db.Session(&gorm.Session{NewDB: true}).Create(&auditLog)
+// AuditBuffer 暂存事务内产生的审计日志，事务提交后调用 Flush 写入，回滚时不调用，避免记录未生效的变更
+type AuditBuffer struct {
+	mu      sync.Mutex
+	plugin  *AuditPlugin
+	entries []*AuditLog
+}
+
+// WithAuditBuffer 返回暂存审计日志的 context，通过该 context 执行的操作产生的审计日志都暂存到返回的 AuditBuffer
+func WithAuditBuffer(ctx context.Context) (context.Context, *AuditBuffer) {
+	buffer := &AuditBuffer{}
+	return context.WithValue(ctx, auditBufferKeyType{}, buffer), buffer
+}
+
+func (b *AuditBuffer) add(plugin *AuditPlugin, auditLog *AuditLog) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.plugin = plugin
+	b.entries = append(b.entries, auditLog)
+}
+
+// discard 丢弃暂存的审计日志
+func (b *AuditBuffer) discard() {
+	b.truncate(0)
+}
+
+// len 返回暂存的审计日志条数
+func (b *AuditBuffer) len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.entries)
+}
+
+// truncate 只保留前 n 条暂存的审计日志
+func (b *AuditBuffer) truncate(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if n < len(b.entries) {
+		b.entries = b.entries[:n]
+	}
+}
+
+// Flush 按产生顺序写入暂存的审计日志
+func (b *AuditBuffer) Flush() {
+	b.mu.Lock()
+	entries := b.entries
+	b.entries = nil
+	b.mu.Unlock()
+
+	for _, entry := range entries {
+		b.plugin.flush(entry)
+	}
+}
+
+// skipTable 判断当前操作是否跳过审计：审计相关表自身的操作，或调用方已声明自行记录
+func (p *AuditPlugin) skipTable(db *gorm.DB) bool {
+	if db.Statement.Context != nil {
+		if skip, ok := db.Statement.Context.Value(AuditSkipKey).(bool); ok && skip {
+			return true
+		}
+	}
+	return db.Statement.Schema != nil && auditSkipTables[db.Statement.Schema.Table]
 }
 
 // getTableName 获取表名
@@ -361,9 +499,13 @@ func (p *AuditPlugin) getRecordID(db *gorm.DB) uint {
 	}
 
 	// 1. 优先尝试从 Schema 的主键字段获取（最可靠，因为 Schema 明确知道主键字段名）
+	// 以 map 更新部分字段时（Model(record).Updates(map)）主键在 Model 上
 	if db.Statement.Schema != nil && db.Statement.Schema.PrioritizedPrimaryField != nil {
-		if db.Statement.Dest != nil {
-			destValue := reflect.ValueOf(db.Statement.Dest)
+		for _, target := range []interface{}{db.Statement.Dest, db.Statement.Model} {
+			if target == nil {
+				continue
+			}
+			destValue := reflect.ValueOf(target)
 			if destValue.Kind() == reflect.Ptr {
 				if !destValue.IsNil() {
 					destValue = destValue.Elem()
@@ -480,6 +622,50 @@ func (p *AuditPlugin) serializeModel(model interface{}) string {
 	return ""
 }
 
+// serializeFields 按快照格式（字段名为键）序列化以 map 更新的字段（列名为键），旧值从完整的旧快照中截取
+func (p *AuditPlugin) serializeFields(db *gorm.DB, oldSnapshot string, fields map[string]interface{}) (string, string) {
+	var old map[string]json.RawMessage
+	if oldSnapshot != "" {
+		if err := json.Unmarshal([]byte(oldSnapshot), &old); err != nil {
+			old = nil
+		}
+	}
+
+	oldValues := make(map[string]interface{}, len(fields))
+	newValues := make(map[string]interface{}, len(fields))
+	for column, value := range fields {
+		name := column
+		if db.Statement.Schema != nil {
+			if field := db.Statement.Schema.LookUpField(column); field != nil {
+				name = field.Name
+			}
+		}
+		// 与 modelToMap 一致，不记录密码和软删除字段；SQL 表达式（如 version + 1）的结果未知，也不记录
+		if _, ok := value.(clause.Expr); ok || name == "Password" || name == "DeletedAt" {
+			continue
+		}
+		newValues[name] = value
+		if raw, ok := old[name]; ok {
+			oldValues[name] = raw
+		}
+	}
+
+	oldData, err := json.Marshal(oldValues)
+	if err != nil || old == nil {
+		oldData = nil
+	}
+	newData, err := json.Marshal(newValues)
+	if err != nil {
+		newData = nil
+	}
+	return string(oldData), string(newData)
+}
+
+// SerializeModel 按审计日志的快照格式序列化模型（过滤密码和软删除字段）
+func SerializeModel(model interface{}) string {
+	return (&AuditPlugin{}).serializeModel(model)
+}
+
 // modelToMap 将模型转换为map，过滤敏感字段
 func (p *AuditPlugin) modelToMap(model interface{}) (map[string]interface{}, error) {
 	data := make(map[string]interface{})
@@ -554,6 +740,19 @@ func (p *AuditPlugin) getUserID(db *gorm.DB) uint {
 	return 0
 }
 
+// getActor 从context中获取操作来源
+func (p *AuditPlugin) getActor(db *gorm.DB) string {
+	if db.Statement.Context == nil {
+		return ""
+	}
+
+	if actor, ok := db.Statement.Context.Value(AuditActorKey).(string); ok {
+		return actor
+	}
+
+	return ""
+}
+
 // getIP 从context中获取IP地址
 func (p *AuditPlugin) getIP(db *gorm.DB) string {
 	if db.Statement.Context == nil {
@@ -567,6 +766,19 @@ func (p *AuditPlugin) getIP(db *gorm.DB) string {
 	return ""
 }
 
+// getRequestID 从context中获取HTTP请求ID
+func (p *AuditPlugin) getRequestID(db *gorm.DB) string {
+	if db.Statement.Context == nil {
+		return ""
+	}
+
+	if requestID, ok := db.Statement.Context.Value(AuditRequestIDKey).(string); ok {
+		return requestID
+	}
+
+	return ""
+}
+
 // SetAuditContext 设置审计上下文信息（在handler或service中调用）
 // 需要在调用数据库操作前设置context
 // 使用示例：
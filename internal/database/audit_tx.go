@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// auditConnPool 包装数据库连接池，通过它开启的事务（包括 GORM 为单条语句开启的默认事务和 db.Transaction）
+// 暂存事务内产生的审计日志，最外层事务提交后再写入，回滚时丢弃；嵌套的 db.Transaction 使用保存点，回滚到保存点时丢弃其后的审计日志
+type auditConnPool struct {
+	gorm.ConnPool
+}
+
+// BeginTx 开启事务，返回暂存审计日志的事务连接
+func (c *auditConnPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	var (
+		tx  gorm.ConnPool
+		err error
+	)
+	switch beginner := c.ConnPool.(type) {
+	case gorm.TxBeginner:
+		tx, err = beginner.BeginTx(ctx, opts)
+	case gorm.ConnPoolBeginner:
+		tx, err = beginner.BeginTx(ctx, opts)
+	default:
+		return nil, gorm.ErrInvalidTransaction
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &auditTx{ConnPool: tx, buffer: &AuditBuffer{}, savepoints: map[string]int{}}, nil
+}
+
+// GetDBConn 返回底层的 *sql.DB，供 db.DB() 和 db.Connection() 使用
+func (c *auditConnPool) GetDBConn() (*sql.DB, error) {
+	switch pool := c.ConnPool.(type) {
+	case *sql.DB:
+		return pool, nil
+	case gorm.GetDBConnector:
+		return pool.GetDBConn()
+	}
+	return nil, gorm.ErrInvalidDB
+}
+
+// auditTx 事务连接，提交成功后写入暂存的审计日志
+type auditTx struct {
+	gorm.ConnPool
+	buffer     *AuditBuffer
+	savepoints map[string]int // 保存点名称到创建时已暂存的审计日志条数
+}
+
+// Commit 提交事务，提交成功后才写入审计日志，此时事务已释放连接和行锁
+func (t *auditTx) Commit() error {
+	if err := t.ConnPool.(gorm.TxCommitter).Commit(); err != nil {
+		return err
+	}
+	t.buffer.Flush()
+	return nil
+}
+
+// Rollback 回滚事务并丢弃暂存的审计日志
+func (t *auditTx) Rollback() error {
+	t.buffer.discard()
+	return t.ConnPool.(gorm.TxCommitter).Rollback()
+}
+
+// trackSavepoint 在 Raw 回调中跟踪各数据库方言执行的 SAVEPOINT / ROLLBACK TO SAVEPOINT 语句
+func trackSavepoint(db *gorm.DB) {
+	tx, ok := db.Statement.ConnPool.(*auditTx)
+	if !ok || db.Error != nil {
+		return
+	}
+	stmt := db.Statement.SQL.String()
+	switch {
+	case strings.HasPrefix(stmt, "SAVEPOINT "):
+		tx.savepoints[strings.TrimPrefix(stmt, "SAVEPOINT ")] = tx.buffer.len()
+	case strings.HasPrefix(stmt, "ROLLBACK TO SAVEPOINT "):
+		if n, ok := tx.savepoints[strings.TrimPrefix(stmt, "ROLLBACK TO SAVEPOINT ")]; ok {
+			tx.buffer.truncate(n)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"go_web/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// TestAuditTransaction 事务内产生的审计日志在最外层事务提交后写入，回滚（包括回滚到保存点）时丢弃
+func TestAuditTransaction(t *testing.T) {
+	errRollback := errors.New("rollback")
+	createRole := func(tx *gorm.DB, name string) error {
+		return tx.Create(&model.Role{Name: name, DisplayName: name}).Error
+	}
+
+	tests := []struct {
+		name      string
+		run       func(t *testing.T, db *gorm.DB) error
+		wantRoles []string
+	}{
+		{
+			name:      "single statement",
+			run:       func(t *testing.T, db *gorm.DB) error { return createRole(db, "a") },
+			wantRoles: []string{"a"},
+		},
+		{
+			name: "commit",
+			run: func(t *testing.T, db *gorm.DB) error {
+				return db.Transaction(func(tx *gorm.DB) error {
+					if err := createRole(tx, "a"); err != nil {
+						return err
+					}
+					var count int64
+					if err := tx.Model(&AuditLog{}).Count(&count).Error; err != nil {
+						return err
+					}
+					if count != 0 {
+						t.Errorf("audit logs before commit = %d, want 0", count)
+					}
+					return createRole(tx, "b")
+				})
+			},
+			wantRoles: []string{"a", "b"},
+		},
+		{
+			name: "rollback",
+			run: func(t *testing.T, db *gorm.DB) error {
+				return db.Transaction(func(tx *gorm.DB) error {
+					if err := createRole(tx, "a"); err != nil {
+						return err
+					}
+					return errRollback
+				})
+			},
+		},
+		{
+			name: "rollback to savepoint",
+			run: func(t *testing.T, db *gorm.DB) error {
+				return db.Transaction(func(tx *gorm.DB) error {
+					if err := createRole(tx, "a"); err != nil {
+						return err
+					}
+					_ = tx.Transaction(func(tx *gorm.DB) error {
+						if err := createRole(tx, "b"); err != nil {
+							return err
+						}
+						return errRollback
+					})
+					return createRole(tx, "c")
+				})
+			},
+			wantRoles: []string{"a", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			if err := db.Use(NewAuditPlugin(db, nil)); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.run(t, db); err != nil && !errors.Is(err, errRollback) {
+				t.Fatal(err)
+			}
+
+			var logs []AuditLog
+			if err := db.Where("table_name = ?", "roles").Order("id").Find(&logs).Error; err != nil {
+				t.Fatal(err)
+			}
+			if len(logs) != len(tt.wantRoles) {
+				t.Fatalf("audit logs = %d, want %d", len(logs), len(tt.wantRoles))
+			}
+			for i, name := range tt.wantRoles {
+				var role model.Role
+				if err := db.First(&role, logs[i].RecordID).Error; err != nil {
+					t.Fatal(err)
+				}
+				if role.Name != name {
+					t.Errorf("audit log %d is for role %q, want %q", i, role.Name, name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go_web/internal/config"
+	"go_web/internal/logger"
+	"go_web/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// 队列满时的处理策略
+const (
+	QueueFullPolicyBlock = "block" // 等待队列有空位，不丢弃
+	QueueFullPolicyDrop  = "drop"  // 直接丢弃并计数
+)
+
+// 批量写入失败后的重试次数和首次重试的等待时间（之后每次翻倍），重试仍失败时逐条写入
+const (
+	auditWriteRetries      = 3
+	auditWriteRetryBackoff = 100 * time.Millisecond
+)
+
+// AuditWriterStats 审计写入器运行指标
+type AuditWriterStats struct {
+	Pending  int    `json:"pending"`  // 队列中待写入的条数
+	Enqueued uint64 `json:"enqueued"` // 累计入队条数
+	Written  uint64 `json:"written"`  // 累计成功写入条数
+	Dropped  uint64 `json:"dropped"`  // 累计因队列已满被丢弃的条数（仅 drop 策略）
+	Retried  uint64 `json:"retried"`  // 累计重试写入的批次数
+	Failed   uint64 `json:"failed"`   // 累计重试和逐条写入后仍写入失败的条数
+}
+
+// AuditWriter 审计日志异步批量写入器
+// 审计插件、HTTP 审计中间件和安全事件服务将记录推入有界队列，由后台 worker 按批次写入数据库
+// 队列中的记录类型为 *AuditLog、*HTTPAuditEvent 或 *model.SecurityEvent
+type AuditWriter struct {
+	cfg config.AuditConfig
+	log *logger.Logger
+	db  *gorm.DB
+
+	queue chan interface{}
+	done  chan struct{}
+
+	mu      sync.RWMutex // 保护 closed，避免向已关闭的队列写入
+	closed  bool
+	started bool
+
+	enqueued atomic.Uint64
+	written  atomic.Uint64
+	dropped  atomic.Uint64
+	retried  atomic.Uint64
+	failed   atomic.Uint64
+}
+
+// NewAuditWriter 创建审计写入器
+// 写入器在 Start 之前（或未开启异步时）会同步写入审计日志
+func NewAuditWriter(cfg *config.Config, log *logger.Logger) *AuditWriter {
+	auditCfg := cfg.Audit
+	if auditCfg.QueueSize <= 0 {
+		auditCfg.QueueSize = 10000
+	}
+	if auditCfg.BatchSize <= 0 {
+		auditCfg.BatchSize = 100
+	}
+	if auditCfg.FlushInterval <= 0 {
+		auditCfg.FlushInterval = 1000
+	}
+	if auditCfg.QueueFullPolicy != QueueFullPolicyDrop {
+		auditCfg.QueueFullPolicy = QueueFullPolicyBlock
+	}
+
+	return &AuditWriter{
+		cfg:   auditCfg,
+		log:   log,
+		queue: make(chan interface{}, auditCfg.QueueSize),
+		done:  make(chan struct{}),
+	}
+}
+
+// Start 绑定数据库连接并启动后台 worker
+func (w *AuditWriter) Start(db *gorm.DB) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.db = db
+	if !w.cfg.Async || w.started || w.closed {
+		return
+	}
+	w.started = true
+
+	go w.run()
+}
+
+// Write 写入一条数据库审计日志
+func (w *AuditWriter) Write(entry *AuditLog) {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	w.enqueue(entry)
+}
+
+// WriteHTTPEvent 写入一条 HTTP 审计事件
+func (w *AuditWriter) WriteHTTPEvent(event *HTTPAuditEvent) {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	w.enqueue(event)
+}
+
+// WriteSecurityEvent 写入一条安全事件
+// 认证和授权失败时在请求路径上调用，异步写入避免每次失败都同步插入数据库
+func (w *AuditWriter) WriteSecurityEvent(event *model.SecurityEvent) {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	w.enqueue(event)
+}
+
+// enqueue 异步模式下推入队列；未启动或已关闭时同步写入，保证不丢失
+func (w *AuditWriter) enqueue(entry interface{}) {
+	w.mu.RLock()
+	if !w.started || w.closed {
+		w.mu.RUnlock()
+		w.writeSync(entry)
+		return
+	}
+
+	if w.cfg.QueueFullPolicy == QueueFullPolicyDrop {
+		select {
+		case w.queue <- entry:
+			w.enqueued.Add(1)
+		default:
+			w.dropped.Add(1)
+		}
+		w.mu.RUnlock()
+		return
+	}
+
+	// block 策略：等待 worker 腾出空位。审计插件在事务提交后才写入，调用方等待时不占用数据库连接，
+	// 不会与 worker 争抢连接
+	w.queue <- entry
+	w.enqueued.Add(1)
+	w.mu.RUnlock()
+}
+
+// Close 停止接收新日志，并等待队列中剩余的日志全部写入
+func (w *AuditWriter) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	started := w.started
+	close(w.queue)
+	w.mu.Unlock()
+
+	if !started {
+		return nil
+	}
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats 返回写入器的运行指标
+func (w *AuditWriter) Stats() AuditWriterStats {
+	return AuditWriterStats{
+		Pending:  len(w.queue),
+		Enqueued: w.enqueued.Load(),
+		Written:  w.written.Load(),
+		Dropped:  w.dropped.Load(),
+		Retried:  w.retried.Load(),
+		Failed:   w.failed.Load(),
+	}
+}
+
+// run 后台 worker：攒够一批或到达刷新间隔时写入数据库
+func (w *AuditWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(time.Duration(w.cfg.FlushInterval) * time.Millisecond)
+	defer ticker.Stop()
+
+	batch := make([]interface{}, 0, w.cfg.BatchSize)
+	for {
+		select {
+		case entry, ok := <-w.queue:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= w.cfg.BatchSize {
+				w.flush(batch)
+				batch = make([]interface{}, 0, w.cfg.BatchSize)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				w.flush(batch)
+				batch = make([]interface{}, 0, w.cfg.BatchSize)
+			}
+		}
+	}
+}
+
+// flush 按记录类型分组后批量写入
+func (w *AuditWriter) flush(batch []interface{}) {
+	var auditLogs []*AuditLog
+	var httpEvents []*HTTPAuditEvent
+	var securityEvents []*model.SecurityEvent
+	for _, entry := range batch {
+		switch v := entry.(type) {
+		case *AuditLog:
+			auditLogs = append(auditLogs, v)
+		case *HTTPAuditEvent:
+			httpEvents = append(httpEvents, v)
+		case *model.SecurityEvent:
+			securityEvents = append(securityEvents, v)
+		}
+	}
+
+	if len(auditLogs) > 0 {
+		records := make([]interface{}, len(auditLogs))
+		for i, entry := range auditLogs {
+			records[i] = entry
+		}
+		w.createInBatches(auditLogs, records)
+	}
+	if len(httpEvents) > 0 {
+		records := make([]interface{}, len(httpEvents))
+		for i, entry := range httpEvents {
+			records[i] = entry
+		}
+		w.createInBatches(httpEvents, records)
+	}
+	if len(securityEvents) > 0 {
+		records := make([]interface{}, len(securityEvents))
+		for i, event := range securityEvents {
+			records[i] = event
+		}
+		w.createInBatches(securityEvents, records)
+	}
+}
+
+// createInBatches 批量写入同一类型的记录，slice 为记录切片，records 为其中的各条记录
+// 失败时按退避间隔重试（如数据库短暂不可用），仍失败时逐条写入，避免一条无效记录导致整批丢失
+func (w *AuditWriter) createInBatches(slice interface{}, records []interface{}) {
+	count := len(records)
+	backoff := auditWriteRetryBackoff
+	var err error
+	for attempt := 0; attempt <= auditWriteRetries; attempt++ {
+		if attempt > 0 {
+			w.retried.Add(1)
+			time.Sleep(backoff)
+			backoff *= 2
+			// 回滚前已写入的行回填了自增主键，清空后重新分配
+			resetIDs(records)
+		}
+		// 事务保证重试前失败的批次没有部分写入，避免重复
+		err = w.session().Transaction(func(tx *gorm.DB) error {
+			return tx.CreateInBatches(slice, w.cfg.BatchSize).Error
+		})
+		if err == nil {
+			w.written.Add(uint64(count))
+			return
+		}
+	}
+
+	if w.log != nil {
+		w.log.WithField("count", count).Errorf("批量写入审计日志失败，改为逐条写入: %v", err)
+	}
+	resetIDs(records)
+	for _, record := range records {
+		w.writeSync(record)
+	}
+}
+
+// resetIDs 清空记录的主键，由数据库重新分配
+func resetIDs(records []interface{}) {
+	for _, record := range records {
+		switch v := record.(type) {
+		case *AuditLog:
+			v.ID = 0
+		case *HTTPAuditEvent:
+			v.ID = 0
+		case *model.SecurityEvent:
+			v.ID = 0
+		}
+	}
+}
+
+// writeSync 同步写入单条记录
+func (w *AuditWriter) writeSync(entry interface{}) {
+	if w.db == nil {
+		w.failed.Add(1)
+		return
+	}
+
+	if err := w.session().Create(entry).Error; err != nil {
+		w.failed.Add(1)
+		if w.log != nil {
+			w.log.Errorf("写入审计日志失败: %v", err)
+		}
+		return
+	}
+	w.written.Add(1)
+}
+
+// session 使用新的数据库会话，避免与业务事务共享状态
+func (w *AuditWriter) session() *gorm.DB {
+	return w.db.Session(&gorm.Session{NewDB: true, Context: context.Background()})
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"go_web/internal/config"
+	"go_web/internal/model"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB 在临时目录中创建 SQLite 数据库并执行全部迁移
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "database.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := NewMigrator(db, &config.Config{Database: config.DatabaseConfig{Driver: "sqlite"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// TestAuditWriterBlockPolicy block 策略下队列再小也不丢弃审计日志
+func TestAuditWriterBlockPolicy(t *testing.T) {
+	db := newTestDB(t)
+	writer := NewAuditWriter(&config.Config{Audit: config.AuditConfig{
+		Async:           true,
+		QueueSize:       1,
+		BatchSize:       1,
+		FlushInterval:   10,
+		QueueFullPolicy: QueueFullPolicyBlock,
+	}}, nil)
+	if err := db.Use(NewAuditPlugin(db, writer)); err != nil {
+		t.Fatal(err)
+	}
+	writer.Start(db)
+
+	const n = 20
+	for i := 0; i < n; i++ {
+		role := &model.Role{Name: fmt.Sprintf("role-%d", i), DisplayName: "Role"}
+		if err := db.Create(role).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var count int64
+	if err := db.Model(&AuditLog{}).Where("table_name = ? AND action = ?", "roles", "create").Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != n {
+		t.Errorf("audit logs = %d, want %d", count, n)
+	}
+	if stats := writer.Stats(); stats.Dropped != 0 || stats.Written != n {
+		t.Errorf("stats = %+v, want %d written and none dropped", stats, n)
+	}
+}
+
+// TestAuditWriterRetryResetsIDs 重试前清空上次失败时回填的主键
+func TestAuditWriterRetryResetsIDs(t *testing.T) {
+	db := newTestDB(t)
+	writer := NewAuditWriter(&config.Config{Audit: config.AuditConfig{BatchSize: 1}}, nil)
+	writer.Start(db)
+
+	// 模拟上次写入回滚前已回填的主键：与已有记录冲突，首次写入失败，清空主键后重试成功
+	if err := db.Create(&AuditLog{ID: 1, ModelTableName: "roles", Action: "create"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	entries := []*AuditLog{
+		{ModelTableName: "roles", Action: "update"},
+		{ModelTableName: "roles", Action: "update"},
+	}
+	records := []interface{}{entries[0], entries[1]}
+	for _, entry := range entries {
+		entry.ID = 1
+	}
+	writer.createInBatches(entries, records)
+
+	if stats := writer.Stats(); stats.Written != 2 || stats.Failed != 0 || stats.Retried != 1 {
+		t.Fatalf("stats = %+v, want 2 written after 1 retry", stats)
+	}
+}
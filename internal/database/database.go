@@ -1,17 +1,28 @@
 package database
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"time"
 
 	"go_web/internal/config"
 	"go_web/internal/logger"
 
-	"gorm.io/driver/mysql"
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 	gormLogger "gorm.io/gorm/logger"
 )
 
-func NewDatabase(cfg *config.Config, log *logger.Logger) (*gorm.DB, error) {
+func NewDatabase(configManager *config.Manager, log *logger.Logger, auditWriter *AuditWriter, replicaResolver *ReplicaResolver) (*gorm.DB, error) {
+	cfg := configManager.Current()
+
 	// 配置GORM日志
 	var gormLog gormLogger.Interface
 	if cfg.Log.Level == "debug" {
@@ -20,30 +31,147 @@ func NewDatabase(cfg *config.Config, log *logger.Logger) (*gorm.DB, error) {
 		gormLog = gormLogger.Default.LogMode(gormLogger.Silent)
 	}
 
-	db, err := gorm.Open(mysql.Open(cfg.Database.DSN), &gorm.Config{
-		Logger: gormLog,
-	})
+	sqlDriver, err := lookupDriver(cfg.Database.Driver)
 	if err != nil {
 		return nil, err
 	}
+	switch {
+	case cfg.Database.Driver == "mysql" && cfg.Database.TLS.Mode == "verify":
+		if err := registerTLSConfig(cfg.Database); err != nil {
+			return nil, err
+		}
+	case cfg.Database.Driver == "sqlite" && cfg.Database.Path != ":memory:":
+		if err := os.MkdirAll(filepath.Dir(cfg.Database.Path), 0755); err != nil {
+			return nil, fmt.Errorf("创建数据库目录失败: %v", err)
+		}
+	}
 
-	// 配置连接池
-	sqlDB, err := db.DB()
-	if err != nil {
+	sqlConn := sql.OpenDB(&rotatingConnector{configManager: configManager, driver: sqlDriver.driver})
+	applyPoolConfig(sqlConn, cfg.Database)
+	// 连接池参数支持热更新
+	configManager.Subscribe(func(cfg *config.Config) {
+		applyPoolConfig(sqlConn, cfg.Database)
+	})
+
+	if err := waitForDatabase(sqlConn, cfg.Database, log); err != nil {
 		return nil, err
 	}
 
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	db, err := gorm.Open(sqlDriver.dialector(cfg.Database.DSN.Value(), sqlConn), &gorm.Config{
+		Logger: gormLog,
+		// 将各数据库的唯一索引冲突（MySQL 1062、PostgreSQL 23505、SQLite UNIQUE）统一转换为 gorm.ErrDuplicatedKey
+		TranslateError: true,
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	// 注册自定义audit插件（可选，HTTP层面的审计已在中间件中实现）
-	auditPlugin := NewAuditPlugin(db)
+	auditPlugin := NewAuditPlugin(db, auditWriter)
 	if err := db.Use(auditPlugin); err != nil {
 		return nil, err
 	}
 
-	log.Info("数据库连接成功")
+	// 注册读写分离插件（未配置只读副本时不生效）
+	if err := db.Use(replicaResolver); err != nil {
+		return nil, err
+	}
+
+	// 启动审计日志后台写入
+	auditWriter.Start(db)
+
+	log.Infof("数据库连接成功（%s）", cfg.Database.Driver)
+
+	// 副本不可用不影响启动，读操作使用主库，恢复后自动启用
+	replicaResolver.Start()
 
 	return db, nil
 }
+
+// applyPoolConfig 按配置设置连接池参数
+func applyPoolConfig(sqlDB *sql.DB, cfg config.DatabaseConfig) {
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
+	sqlDB.SetConnMaxIdleTime(time.Duration(cfg.ConnMaxIdleTime) * time.Second)
+
+	if cfg.Driver == "sqlite" && cfg.Path == ":memory:" {
+		// 内存数据库在最后一个连接关闭时销毁，需要保持连接常驻
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
+		if cfg.MaxIdleConns < 1 {
+			sqlDB.SetMaxIdleConns(1)
+		}
+	}
+}
+
+// maxConnectRetryInterval 启动时连接重试的最长等待时间
+const maxConnectRetryInterval = 30 * time.Second
+
+// waitForDatabase 启动时检查数据库是否可用，失败后按指数退避重试，避免数据库晚于服务启动时直接退出
+func waitForDatabase(sqlDB *sql.DB, cfg config.DatabaseConfig, log *logger.Logger) error {
+	interval := time.Duration(cfg.ConnectRetryInterval) * time.Second
+	for attempt := 0; ; attempt++ {
+		err := pingDatabase(sqlDB, cfg)
+		if err == nil {
+			return nil
+		}
+		if attempt >= cfg.ConnectRetries {
+			return fmt.Errorf("连接数据库失败（已重试 %d 次）: %w", attempt, err)
+		}
+
+		log.WithFields(logrus.Fields{
+			"attempt": attempt + 1,
+			"retries": cfg.ConnectRetries,
+			"wait":    interval.String(),
+		}).Warnf("连接数据库失败，稍后重试: %v", err)
+		time.Sleep(interval)
+
+		interval *= 2
+		if interval > maxConnectRetryInterval {
+			interval = maxConnectRetryInterval
+		}
+	}
+}
+
+// pingDatabase 检查数据库连接，超时时间与建立连接的超时时间一致
+func pingDatabase(sqlDB *sql.DB, cfg config.DatabaseConfig) error {
+	ctx := context.Background()
+	if cfg.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(cfg.ConnectTimeout)*time.Second)
+		defer cancel()
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// registerTLSConfig 根据配置创建 TLS 配置并注册到 MySQL 驱动（verify 模式）
+func registerTLSConfig(cfg config.DatabaseConfig) error {
+	// ServerName 为空时驱动使用连接地址中的主机名，主库和各副本分别按自己的主机名校验证书
+	tlsConfig := &tls.Config{
+		ServerName: cfg.TLS.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if cfg.TLS.CA != "" {
+		pem, err := os.ReadFile(cfg.TLS.CA)
+		if err != nil {
+			return fmt.Errorf("读取数据库 CA 证书失败: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.New("数据库 CA 证书无效: 未找到 PEM 格式的证书")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.TLS.Cert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLS.Cert, cfg.TLS.Key)
+		if err != nil {
+			return fmt.Errorf("加载数据库客户端证书失败: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return mysqlDriver.RegisterTLSConfig(config.DatabaseTLSConfigName, tlsConfig)
+}
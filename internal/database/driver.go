@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+
+	"go_web/internal/config"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// sqlDriver 数据库驱动及对应的 GORM 方言
+type sqlDriver struct {
+	driver    driver.Driver
+	dialector func(dsn string, conn gorm.ConnPool) gorm.Dialector
+}
+
+// sqlDrivers 支持的数据库驱动，键为配置项 database.driver 的取值
+// 仓储层和审计插件只使用 GORM 的通用接口，新增驱动时只需在这里注册
+var sqlDrivers = map[string]sqlDriver{
+	"mysql": {
+		driver: mysqlDriver.MySQLDriver{},
+		dialector: func(dsn string, conn gorm.ConnPool) gorm.Dialector {
+			return mysql.New(mysql.Config{DSN: dsn, Conn: conn})
+		},
+	},
+	"postgres": {
+		driver: stdlib.GetDefaultDriver(),
+		dialector: func(dsn string, conn gorm.ConnPool) gorm.Dialector {
+			return postgres.New(postgres.Config{DSN: dsn, Conn: conn})
+		},
+	},
+	"sqlite": {
+		driver: &sqlite3.SQLiteDriver{},
+		dialector: func(dsn string, conn gorm.ConnPool) gorm.Dialector {
+			return &sqlite.Dialector{DSN: dsn, Conn: conn}
+		},
+	},
+}
+
+// lookupDriver 根据配置查找数据库驱动
+func lookupDriver(name string) (sqlDriver, error) {
+	d, ok := sqlDrivers[name]
+	if !ok {
+		return sqlDriver{}, fmt.Errorf("不支持的数据库驱动: %s", name)
+	}
+	return d, nil
+}
+
+// rotatingConnector 每次建立新连接时读取最新的 DSN，数据库密码轮换后新连接使用新密码
+type rotatingConnector struct {
+	configManager *config.Manager
+	driver        driver.Driver
+	replica       string // 只读副本地址，为空表示连接主库
+}
+
+func (c *rotatingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	dsn := c.configManager.Current().Database.DSN.Value()
+	if c.replica != "" {
+		dsn = config.ReplicaDSN(c.configManager.Current().Database, c.replica).Value()
+	}
+	if dc, ok := c.driver.(driver.DriverContext); ok {
+		connector, err := dc.OpenConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+		return connector.Connect(ctx)
+	}
+	return c.driver.Open(dsn)
+}
+
+func (c *rotatingConnector) Driver() driver.Driver {
+	return c.driver
+}
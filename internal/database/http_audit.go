@@ -0,0 +1,38 @@
+package database
+
+import "time"
+
+// HTTP 审计事件中的权限校验结果
+const (
+	PermissionDecisionAllowed         = "allowed"         // 权限校验通过
+	PermissionDecisionDenied          = "denied"          // 权限不足
+	PermissionDecisionUnauthenticated = "unauthenticated" // 未登录
+	PermissionDecisionError           = "error"           // 权限校验出错
+)
+
+// HTTPAuditEvent HTTP 请求审计事件
+// 在处理器执行完成后记录，通过 RequestID 关联同一请求产生的 AuditLog
+type HTTPAuditEvent struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	RequestID       string `gorm:"type:varchar(64);index" json:"request_id"`            // 服务端生成的请求ID
+	ClientRequestID string `gorm:"type:varchar(64)" json:"client_request_id,omitempty"` // 客户端在 X-Request-ID 中传入的请求ID
+	UserID          uint   `gorm:"index" json:"user_id"`                                // 操作者用户ID（未登录为0）
+	UserEmail       string `gorm:"type:varchar(100)" json:"user_email"`                 // 操作者邮箱
+	Method          string `gorm:"type:varchar(10)" json:"method"`                      // 请求方法
+	Route           string `gorm:"type:varchar(255);index" json:"route"`                // 路由模板，如 /api/v1/users/:id
+	Path            string `gorm:"type:varchar(255)" json:"path"`                       // 实际请求路径
+	Params          string `gorm:"type:text" json:"params"`                             // 路径参数和查询参数（JSON 格式）
+	Status          int    `gorm:"index" json:"status"`                                 // 响应状态码
+	DurationMs      int64  `json:"duration_ms"`                                         // 处理耗时（毫秒）
+	ClientIP        string `gorm:"type:varchar(50)" json:"client_ip"`                   // 客户端IP
+	UserAgent       string `gorm:"type:varchar(255)" json:"user_agent"`                 // User-Agent
+	Permission      string `gorm:"type:varchar(100)" json:"permission"`                 // 校验的权限，如 user:create
+	Decision        string `gorm:"type:varchar(20);index" json:"decision"`              // 权限校验结果，空表示未经过权限校验
+}
+
+// TableName 指定表名
+func (HTTPAuditEvent) TableName() string {
+	return "http_audit_events"
+}
@@ -0,0 +1,493 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go_web/internal/config"
+
+	"gorm.io/gorm"
+)
+
+// migrationFiles 内嵌的迁移文件，每种数据库驱动一个目录
+// 文件名格式为 <版本号>_<名称>.up.sql / <版本号>_<名称>.down.sql，如 0002_add_user_phone.up.sql
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+var (
+	ErrSchemaBehind      = errors.New("数据库结构版本落后")
+	ErrSchemaDirty       = errors.New("数据库迁移未完成")
+	ErrMigrationNotFound = errors.New("迁移版本不存在")
+	ErrMigrationLocked   = errors.New("等待迁移锁超时，可能有其他实例正在执行迁移")
+)
+
+// migrationFilePattern 迁移文件名格式
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration 一个版本的数据库迁移
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string // 升级脚本
+	Down    string // 回滚脚本
+}
+
+// SchemaMigration 已执行的迁移记录
+type SchemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false" json:"version"`
+	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
+	Dirty     bool      `gorm:"not null" json:"dirty"` // 迁移执行中断（仅 MySQL，DDL 不支持事务），需要手动修复
+	AppliedAt time.Time `gorm:"not null" json:"applied_at"`
+}
+
+// TableName 指定表名
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationStatus 迁移状态
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	Dirty     bool       `json:"dirty"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	Unknown   bool       `json:"unknown"` // 数据库中已执行但程序中不存在（程序版本落后于数据库）
+}
+
+// schemaMigrationsDDL 各驱动创建迁移记录表的语句
+var schemaMigrationsDDL = map[string]string{
+	"mysql":    "CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, name VARCHAR(255) NOT NULL, dirty BOOLEAN NOT NULL DEFAULT FALSE, applied_at DATETIME(3) NOT NULL) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+	"postgres": "CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, name VARCHAR(255) NOT NULL, dirty BOOLEAN NOT NULL DEFAULT FALSE, applied_at TIMESTAMPTZ NOT NULL)",
+	"sqlite":   "CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER NOT NULL PRIMARY KEY, name VARCHAR(255) NOT NULL, dirty BOOLEAN NOT NULL DEFAULT FALSE, applied_at DATETIME NOT NULL)",
+}
+
+// postgresMigrationLockKey PostgreSQL 迁移使用的 advisory lock 键（advisory lock 按数据库隔离）
+const postgresMigrationLockKey = 7_316_452_019
+
+// Migrator 数据库迁移执行器
+type Migrator struct {
+	db          *gorm.DB
+	driver      string
+	lockName    string
+	lockTimeout time.Duration
+	migrations  []Migration
+}
+
+// NewMigrator 创建迁移执行器，加载当前驱动的内嵌迁移文件
+func NewMigrator(db *gorm.DB, cfg *config.Config) (*Migrator, error) {
+	migrations, err := loadMigrations(cfg.Database.Driver)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:          db,
+		driver:      cfg.Database.Driver,
+		lockName:    cfg.Database.DBName + ".schema_migrations",
+		lockTimeout: time.Duration(cfg.Database.MigrateLockTimeout) * time.Second,
+		migrations:  migrations,
+	}, nil
+}
+
+// loadMigrations 读取驱动对应目录下的迁移文件，每个版本必须同时有 up 和 down 脚本
+func loadMigrations(driver string) ([]Migration, error) {
+	dir := path.Join("migrations", driver)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("没有 %s 驱动的迁移文件: %v", driver, err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		matches := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("无效的迁移文件名: %s", entry.Name())
+		}
+		version, _ := strconv.ParseInt(matches[1], 10, 64)
+		content, err := fs.ReadFile(migrationFiles, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		} else if migration.Name != matches[2] {
+			return nil, fmt.Errorf("迁移版本 %d 重复: %s 和 %s", version, migration.Name, matches[2])
+		}
+		if matches[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("迁移 %d_%s 缺少 up 或 down 脚本", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrations 返回全部迁移，按版本升序
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// LatestVersion 返回程序中最新的迁移版本
+func (m *Migrator) LatestVersion() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Status 返回全部迁移的执行状态，按版本升序
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	db := m.db.WithContext(ctx)
+	var records []SchemaMigration
+	if db.Migrator().HasTable(&SchemaMigration{}) {
+		if err := db.Order("version").Find(&records).Error; err != nil {
+			return nil, err
+		}
+	}
+	return m.status(records), nil
+}
+
+func (m *Migrator) status(records []SchemaMigration) []MigrationStatus {
+	applied := make(map[int64]SchemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if record, ok := applied[migration.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.Applied = true
+			status.Dirty = record.Dirty
+			status.AppliedAt = &appliedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, record := range applied {
+		appliedAt := record.AppliedAt
+		statuses = append(statuses, MigrationStatus{
+			Version:   record.Version,
+			Name:      record.Name,
+			Applied:   true,
+			Dirty:     record.Dirty,
+			AppliedAt: &appliedAt,
+			Unknown:   true,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses
+}
+
+// Check 检查数据库结构是否为最新，存在未执行或未完成的迁移时返回错误
+// 数据库版本高于程序（滚动发布时旧实例）不视为错误
+func (m *Migrator) Check(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, status := range statuses {
+		if status.Dirty {
+			return fmt.Errorf("%w: 版本 %d_%s 执行中断，请手动修复后删除 schema_migrations 中的该记录", ErrSchemaDirty, status.Version, status.Name)
+		}
+		if !status.Applied {
+			pending = append(pending, fmt.Sprintf("%d_%s", status.Version, status.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: 未执行的迁移 %s", ErrSchemaBehind, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// Up 执行全部未执行的迁移，返回本次执行的迁移
+// 数据库中存在程序未知的更高版本时不会回滚，只补齐程序中未执行的迁移
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.run(ctx, func(conn *gorm.DB, applied []SchemaMigration) error {
+		for _, migration := range m.pending(applied, m.LatestVersion()) {
+			if err := m.execute(conn, migration, true); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down 回滚最近执行的 steps 个迁移，返回本次回滚的迁移
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.run(ctx, func(conn *gorm.DB, applied []SchemaMigration) error {
+		for i := len(applied) - 1; i >= 0 && len(done) < steps; i-- {
+			migration, err := m.find(applied[i].Version)
+			if err != nil {
+				return err
+			}
+			if err := m.execute(conn, migration, false); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// To 迁移到指定版本：高于当前版本时执行升级，低于当前版本时回滚，version 为 0 表示回滚全部
+func (m *Migrator) To(ctx context.Context, version int64) ([]Migration, error) {
+	if version != 0 {
+		if _, err := m.find(version); err != nil {
+			return nil, err
+		}
+	}
+
+	var done []Migration
+	err := m.run(ctx, func(conn *gorm.DB, applied []SchemaMigration) error {
+		// 回滚高于目标版本的迁移（从新到旧）
+		for i := len(applied) - 1; i >= 0; i-- {
+			if applied[i].Version <= version {
+				break
+			}
+			migration, err := m.find(applied[i].Version)
+			if err != nil {
+				return err
+			}
+			if err := m.execute(conn, migration, false); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+
+		// 执行不高于目标版本且未执行的迁移（从旧到新）
+		for _, migration := range m.pending(applied, version) {
+			if err := m.execute(conn, migration, true); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// pending 返回不高于 version 且未执行的迁移，按版本升序
+func (m *Migrator) pending(applied []SchemaMigration, version int64) []Migration {
+	appliedVersions := make(map[int64]bool, len(applied))
+	for _, record := range applied {
+		appliedVersions[record.Version] = true
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if migration.Version <= version && !appliedVersions[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+	return pending
+}
+
+// find 查找指定版本的迁移
+func (m *Migrator) find(version int64) (Migration, error) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, nil
+		}
+	}
+	return Migration{}, fmt.Errorf("%w: %d", ErrMigrationNotFound, version)
+}
+
+// run 在同一个数据库连接上持有迁移锁执行 fn，持锁期间读取的已执行迁移不会被其他实例修改
+func (m *Migrator) run(ctx context.Context, fn func(conn *gorm.DB, applied []SchemaMigration) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec(schemaMigrationsDDL[m.driver]).Error; err != nil {
+			return fmt.Errorf("创建 schema_migrations 表失败: %w", err)
+		}
+
+		if m.driver == "sqlite" {
+			// SQLite 的 DDL 支持事务，全部迁移在一个写事务中执行（DSN 设置了 _txlock=immediate），由数据库文件锁保证互斥
+			return conn.Transaction(func(tx *gorm.DB) error {
+				applied, err := m.applied(tx)
+				if err != nil {
+					return err
+				}
+				return fn(tx, applied)
+			})
+		}
+
+		unlock, err := m.lock(ctx, conn)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+		return fn(conn, applied)
+	})
+}
+
+// applied 读取已执行的迁移，存在未完成的迁移时返回错误
+func (m *Migrator) applied(conn *gorm.DB) ([]SchemaMigration, error) {
+	var records []SchemaMigration
+	if err := conn.Order("version").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		if record.Dirty {
+			return nil, fmt.Errorf("%w: 版本 %d_%s 执行中断，请手动修复后删除 schema_migrations 中的该记录", ErrSchemaDirty, record.Version, record.Name)
+		}
+	}
+	return records, nil
+}
+
+// lock 获取迁移锁，返回释放锁的函数
+// 使用会话级锁（MySQL GET_LOCK、PostgreSQL advisory lock），进程异常退出时随连接关闭自动释放
+func (m *Migrator) lock(ctx context.Context, conn *gorm.DB) (func(), error) {
+	switch m.driver {
+	case "mysql":
+		var acquired *int
+		if err := conn.Raw("SELECT GET_LOCK(?, ?)", m.lockName, int(m.lockTimeout.Seconds())).Scan(&acquired).Error; err != nil {
+			return nil, fmt.Errorf("获取迁移锁失败: %w", err)
+		}
+		if acquired == nil || *acquired != 1 {
+			return nil, ErrMigrationLocked
+		}
+		return func() { m.unlockConn(conn).Exec("SELECT RELEASE_LOCK(?)", m.lockName) }, nil
+	case "postgres":
+		deadline := time.Now().Add(m.lockTimeout)
+		for {
+			var acquired bool
+			if err := conn.Raw("SELECT pg_try_advisory_lock(?)", postgresMigrationLockKey).Scan(&acquired).Error; err != nil {
+				return nil, fmt.Errorf("获取迁移锁失败: %w", err)
+			}
+			if acquired {
+				return func() { m.unlockConn(conn).Exec("SELECT pg_advisory_unlock(?)", postgresMigrationLockKey) }, nil
+			}
+			if time.Now().After(deadline) {
+				return nil, ErrMigrationLocked
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(500 * time.Millisecond):
+			}
+		}
+	default:
+		return nil, fmt.Errorf("不支持的数据库驱动: %s", m.driver)
+	}
+}
+
+// unlockConn 释放锁使用的会话，不受调用方 ctx 取消的影响，避免锁残留在连接池的连接上
+func (m *Migrator) unlockConn(conn *gorm.DB) *gorm.DB {
+	return conn.WithContext(context.Background())
+}
+
+// execute 执行一个迁移的升级或回滚脚本，并更新迁移记录
+func (m *Migrator) execute(conn *gorm.DB, migration Migration, up bool) error {
+	script := migration.Down
+	if up {
+		script = migration.Up
+	}
+	statements := splitStatements(script)
+	record := SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}
+
+	run := func(tx *gorm.DB) error {
+		for i, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return fmt.Errorf("执行迁移 %d_%s 第 %d 条语句失败: %w", migration.Version, migration.Name, i+1, err)
+			}
+		}
+		return nil
+	}
+
+	switch m.driver {
+	case "mysql":
+		// MySQL 的 DDL 会隐式提交事务，执行前先标记为 dirty，全部语句成功后再清除，执行中断时可以被发现
+		var err error
+		if up {
+			record.Dirty = true
+			err = conn.Create(&record).Error
+		} else {
+			err = conn.Model(&record).Update("dirty", true).Error
+		}
+		if err != nil {
+			return err
+		}
+		if err := run(conn); err != nil {
+			return fmt.Errorf("%w（数据库处于未完成状态，请手动修复后删除 schema_migrations 中 version=%d 的记录）", err, migration.Version)
+		}
+		if up {
+			return conn.Model(&record).Update("dirty", false).Error
+		}
+		return conn.Delete(&record).Error
+	case "sqlite":
+		// 已在 run 开启的事务中
+		return m.record(conn, record, up, run)
+	default:
+		return conn.Transaction(func(tx *gorm.DB) error {
+			return m.record(tx, record, up, run)
+		})
+	}
+}
+
+// record 执行迁移脚本并写入或删除迁移记录（在事务中调用）
+func (m *Migrator) record(tx *gorm.DB, record SchemaMigration, up bool, run func(tx *gorm.DB) error) error {
+	if err := run(tx); err != nil {
+		return err
+	}
+	if up {
+		return tx.Create(&record).Error
+	}
+	return tx.Delete(&record).Error
+}
+
+// splitStatements 将迁移脚本拆分为单条语句：以行尾的分号作为语句结束，忽略 -- 开头的注释行
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
@@ -0,0 +1,148 @@
+-- 初始表结构，与此前 GORM AutoMigrate 创建的结构一致
+-- 使用 IF NOT EXISTS，已由 AutoMigrate 建表的数据库可以直接执行
+
+CREATE TABLE IF NOT EXISTS `users` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    `deleted_at` DATETIME(3) NULL,
+    `name` VARCHAR(100) NOT NULL,
+    `email` VARCHAR(100) NOT NULL,
+    `password` VARCHAR(255) NOT NULL,
+    `status` BIGINT DEFAULT 1,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_users_email` (`email`),
+    INDEX `idx_users_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `roles` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    `deleted_at` DATETIME(3) NULL,
+    `name` VARCHAR(100) NOT NULL,
+    `display_name` VARCHAR(100) NOT NULL,
+    `description` VARCHAR(255),
+    `status` BIGINT DEFAULT 1,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_roles_name` (`name`),
+    INDEX `idx_roles_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `permissions` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    `deleted_at` DATETIME(3) NULL,
+    `name` VARCHAR(100) NOT NULL,
+    `display_name` VARCHAR(100) NOT NULL,
+    `description` VARCHAR(255),
+    `resource` VARCHAR(50) NOT NULL,
+    `action` VARCHAR(50) NOT NULL,
+    `status` BIGINT DEFAULT 1,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_permissions_name` (`name`),
+    INDEX `idx_permissions_resource` (`resource`),
+    INDEX `idx_permissions_action` (`action`),
+    INDEX `idx_permissions_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `user_roles` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    `deleted_at` DATETIME(3) NULL,
+    `user_id` BIGINT UNSIGNED NOT NULL,
+    `role_id` BIGINT UNSIGNED NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_user_role` (`user_id`, `role_id`),
+    INDEX `idx_user_roles_user_id` (`user_id`),
+    INDEX `idx_user_roles_role_id` (`role_id`),
+    INDEX `idx_user_roles_deleted_at` (`deleted_at`),
+    CONSTRAINT `fk_user_roles_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`),
+    CONSTRAINT `fk_user_roles_role` FOREIGN KEY (`role_id`) REFERENCES `roles` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `role_permissions` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    `deleted_at` DATETIME(3) NULL,
+    `role_id` BIGINT UNSIGNED NOT NULL,
+    `permission_id` BIGINT UNSIGNED NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_role_permission` (`role_id`, `permission_id`),
+    INDEX `idx_role_permissions_role_id` (`role_id`),
+    INDEX `idx_role_permissions_permission_id` (`permission_id`),
+    INDEX `idx_role_permissions_deleted_at` (`deleted_at`),
+    CONSTRAINT `fk_role_permissions_role` FOREIGN KEY (`role_id`) REFERENCES `roles` (`id`),
+    CONSTRAINT `fk_role_permissions_permission` FOREIGN KEY (`permission_id`) REFERENCES `permissions` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `audit_logs` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `created_at` DATETIME(3) NULL,
+    `table_name` VARCHAR(100),
+    `record_id` BIGINT UNSIGNED,
+    `action` VARCHAR(20),
+    `old_values` TEXT,
+    `new_values` TEXT,
+    `user_id` BIGINT UNSIGNED,
+    `ip` VARCHAR(50),
+    `request_id` VARCHAR(64),
+    `ref_id` BIGINT UNSIGNED,
+    PRIMARY KEY (`id`),
+    INDEX `idx_audit_logs_created_at` (`created_at`),
+    INDEX `idx_audit_logs_model_table_name` (`table_name`),
+    INDEX `idx_audit_logs_record_id` (`record_id`),
+    INDEX `idx_audit_logs_action` (`action`),
+    INDEX `idx_audit_logs_user_id` (`user_id`),
+    INDEX `idx_audit_logs_request_id` (`request_id`),
+    INDEX `idx_audit_logs_ref_id` (`ref_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `http_audit_events` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `created_at` DATETIME(3) NULL,
+    `request_id` VARCHAR(64),
+    `user_id` BIGINT UNSIGNED,
+    `user_email` VARCHAR(100),
+    `method` VARCHAR(10),
+    `route` VARCHAR(255),
+    `path` VARCHAR(255),
+    `params` TEXT,
+    `status` BIGINT,
+    `duration_ms` BIGINT,
+    `client_ip` VARCHAR(50),
+    `user_agent` VARCHAR(255),
+    `permission` VARCHAR(100),
+    `decision` VARCHAR(20),
+    PRIMARY KEY (`id`),
+    INDEX `idx_http_audit_events_created_at` (`created_at`),
+    INDEX `idx_http_audit_events_request_id` (`request_id`),
+    INDEX `idx_http_audit_events_user_id` (`user_id`),
+    INDEX `idx_http_audit_events_route` (`route`),
+    INDEX `idx_http_audit_events_status` (`status`),
+    INDEX `idx_http_audit_events_decision` (`decision`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `security_events` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `created_at` DATETIME(3) NULL,
+    `event_type` VARCHAR(50) NOT NULL,
+    `user_id` BIGINT UNSIGNED,
+    `email` VARCHAR(100),
+    `ip` VARCHAR(50),
+    `user_agent` VARCHAR(255),
+    `method` VARCHAR(10),
+    `path` VARCHAR(255),
+    `reason` VARCHAR(255),
+    `request_id` VARCHAR(64),
+    PRIMARY KEY (`id`),
+    INDEX `idx_security_events_created_at` (`created_at`),
+    INDEX `idx_security_events_event_type` (`event_type`),
+    INDEX `idx_security_events_user_id` (`user_id`),
+    INDEX `idx_security_events_email` (`email`),
+    INDEX `idx_security_events_ip` (`ip`),
+    INDEX `idx_security_events_request_id` (`request_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
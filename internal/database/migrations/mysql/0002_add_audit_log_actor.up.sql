@@ -0,0 +1,4 @@
+-- 审计日志记录操作来源（http 接口请求、cli 命令行）
+ALTER TABLE `audit_logs`
+    ADD COLUMN `actor` VARCHAR(50) AFTER `user_id`,
+    ADD INDEX `idx_audit_logs_actor` (`actor`);
@@ -0,0 +1,2 @@
+-- 用户的接口提示信息语言偏好（zh/en），为空时按 Accept-Language 协商
+ALTER TABLE `users` ADD COLUMN `language` VARCHAR(10) NOT NULL DEFAULT '' AFTER `version`;
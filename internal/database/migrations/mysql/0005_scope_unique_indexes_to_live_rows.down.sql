@@ -0,0 +1,4 @@
+-- 已删除的记录与其他记录重名时无法恢复为全表唯一索引，需先彻底删除这些记录
+ALTER TABLE `users` DROP INDEX `idx_users_email`, ADD UNIQUE INDEX `idx_users_email` (`email`), DROP COLUMN `live`;
+ALTER TABLE `roles` DROP INDEX `idx_roles_name`, ADD UNIQUE INDEX `idx_roles_name` (`name`), DROP COLUMN `live`;
+ALTER TABLE `permissions` DROP INDEX `idx_permissions_name`, ADD UNIQUE INDEX `idx_permissions_name` (`name`), DROP COLUMN `live`;
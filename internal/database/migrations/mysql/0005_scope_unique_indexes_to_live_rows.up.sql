@@ -0,0 +1,5 @@
+-- 唯一索引只约束未删除的记录，软删除后可以用相同的邮箱、名称重新创建
+-- MySQL 不支持部分索引：live 列未删除时为 1、已删除时为 NULL，唯一索引中含 NULL 的行互不冲突
+ALTER TABLE `users` ADD COLUMN `live` TINYINT AS (IF(`deleted_at` IS NULL, 1, NULL)) VIRTUAL, DROP INDEX `idx_users_email`, ADD UNIQUE INDEX `idx_users_email` (`email`, `live`);
+ALTER TABLE `roles` ADD COLUMN `live` TINYINT AS (IF(`deleted_at` IS NULL, 1, NULL)) VIRTUAL, DROP INDEX `idx_roles_name`, ADD UNIQUE INDEX `idx_roles_name` (`name`, `live`);
+ALTER TABLE `permissions` ADD COLUMN `live` TINYINT AS (IF(`deleted_at` IS NULL, 1, NULL)) VIRTUAL, DROP INDEX `idx_permissions_name`, ADD UNIQUE INDEX `idx_permissions_name` (`name`, `live`);
@@ -0,0 +1 @@
+ALTER TABLE `http_audit_events` DROP COLUMN `client_request_id`;
@@ -0,0 +1,2 @@
+-- 客户端在 X-Request-ID 中传入的请求ID，仅供追踪；request_id 始终由服务端生成
+ALTER TABLE `http_audit_events` ADD COLUMN `client_request_id` VARCHAR(64) AFTER `request_id`;
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS security_events;
+DROP TABLE IF EXISTS http_audit_events;
+DROP TABLE IF EXISTS audit_logs;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS users;
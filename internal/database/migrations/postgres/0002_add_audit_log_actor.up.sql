@@ -0,0 +1,3 @@
+-- 审计日志记录操作来源（http 接口请求、cli 命令行）
+ALTER TABLE audit_logs ADD COLUMN actor VARCHAR(50);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor ON audit_logs (actor);
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN version;
+ALTER TABLE roles DROP COLUMN version;
+ALTER TABLE permissions DROP COLUMN version;
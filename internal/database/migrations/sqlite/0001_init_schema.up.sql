@@ -0,0 +1,140 @@
+-- 初始表结构，与此前 GORM AutoMigrate 创建的结构一致
+-- 使用 IF NOT EXISTS，已由 AutoMigrate 建表的数据库可以直接执行
+
+CREATE TABLE IF NOT EXISTS users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME NULL,
+    updated_at DATETIME NULL,
+    deleted_at DATETIME NULL,
+    name VARCHAR(100) NOT NULL,
+    email VARCHAR(100) NOT NULL,
+    password VARCHAR(255) NOT NULL,
+    status INTEGER DEFAULT 1
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS roles (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME NULL,
+    updated_at DATETIME NULL,
+    deleted_at DATETIME NULL,
+    name VARCHAR(100) NOT NULL,
+    display_name VARCHAR(100) NOT NULL,
+    description VARCHAR(255),
+    status INTEGER DEFAULT 1
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_roles_name ON roles (name);
+CREATE INDEX IF NOT EXISTS idx_roles_deleted_at ON roles (deleted_at);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME NULL,
+    updated_at DATETIME NULL,
+    deleted_at DATETIME NULL,
+    name VARCHAR(100) NOT NULL,
+    display_name VARCHAR(100) NOT NULL,
+    description VARCHAR(255),
+    resource VARCHAR(50) NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    status INTEGER DEFAULT 1
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_permissions_name ON permissions (name);
+CREATE INDEX IF NOT EXISTS idx_permissions_resource ON permissions (resource);
+CREATE INDEX IF NOT EXISTS idx_permissions_action ON permissions (action);
+CREATE INDEX IF NOT EXISTS idx_permissions_deleted_at ON permissions (deleted_at);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME NULL,
+    updated_at DATETIME NULL,
+    deleted_at DATETIME NULL,
+    user_id INTEGER NOT NULL,
+    role_id INTEGER NOT NULL,
+    CONSTRAINT fk_user_roles_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_user_roles_role FOREIGN KEY (role_id) REFERENCES roles (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_role ON user_roles (user_id, role_id);
+CREATE INDEX IF NOT EXISTS idx_user_roles_user_id ON user_roles (user_id);
+CREATE INDEX IF NOT EXISTS idx_user_roles_role_id ON user_roles (role_id);
+CREATE INDEX IF NOT EXISTS idx_user_roles_deleted_at ON user_roles (deleted_at);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME NULL,
+    updated_at DATETIME NULL,
+    deleted_at DATETIME NULL,
+    role_id INTEGER NOT NULL,
+    permission_id INTEGER NOT NULL,
+    CONSTRAINT fk_role_permissions_role FOREIGN KEY (role_id) REFERENCES roles (id),
+    CONSTRAINT fk_role_permissions_permission FOREIGN KEY (permission_id) REFERENCES permissions (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_role_permission ON role_permissions (role_id, permission_id);
+CREATE INDEX IF NOT EXISTS idx_role_permissions_role_id ON role_permissions (role_id);
+CREATE INDEX IF NOT EXISTS idx_role_permissions_permission_id ON role_permissions (permission_id);
+CREATE INDEX IF NOT EXISTS idx_role_permissions_deleted_at ON role_permissions (deleted_at);
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME NULL,
+    table_name VARCHAR(100),
+    record_id INTEGER,
+    action VARCHAR(20),
+    old_values TEXT,
+    new_values TEXT,
+    user_id INTEGER,
+    ip VARCHAR(50),
+    request_id VARCHAR(64),
+    ref_id INTEGER
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_model_table_name ON audit_logs (table_name);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_record_id ON audit_logs (record_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs (action);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_user_id ON audit_logs (user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_request_id ON audit_logs (request_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_ref_id ON audit_logs (ref_id);
+
+CREATE TABLE IF NOT EXISTS http_audit_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME NULL,
+    request_id VARCHAR(64),
+    user_id INTEGER,
+    user_email VARCHAR(100),
+    method VARCHAR(10),
+    route VARCHAR(255),
+    path VARCHAR(255),
+    params TEXT,
+    status INTEGER,
+    duration_ms INTEGER,
+    client_ip VARCHAR(50),
+    user_agent VARCHAR(255),
+    permission VARCHAR(100),
+    decision VARCHAR(20)
+);
+CREATE INDEX IF NOT EXISTS idx_http_audit_events_created_at ON http_audit_events (created_at);
+CREATE INDEX IF NOT EXISTS idx_http_audit_events_request_id ON http_audit_events (request_id);
+CREATE INDEX IF NOT EXISTS idx_http_audit_events_user_id ON http_audit_events (user_id);
+CREATE INDEX IF NOT EXISTS idx_http_audit_events_route ON http_audit_events (route);
+CREATE INDEX IF NOT EXISTS idx_http_audit_events_status ON http_audit_events (status);
+CREATE INDEX IF NOT EXISTS idx_http_audit_events_decision ON http_audit_events (decision);
+
+CREATE TABLE IF NOT EXISTS security_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME NULL,
+    event_type VARCHAR(50) NOT NULL,
+    user_id INTEGER,
+    email VARCHAR(100),
+    ip VARCHAR(50),
+    user_agent VARCHAR(255),
+    method VARCHAR(10),
+    path VARCHAR(255),
+    reason VARCHAR(255),
+    request_id VARCHAR(64)
+);
+CREATE INDEX IF NOT EXISTS idx_security_events_created_at ON security_events (created_at);
+CREATE INDEX IF NOT EXISTS idx_security_events_event_type ON security_events (event_type);
+CREATE INDEX IF NOT EXISTS idx_security_events_user_id ON security_events (user_id);
+CREATE INDEX IF NOT EXISTS idx_security_events_email ON security_events (email);
+CREATE INDEX IF NOT EXISTS idx_security_events_ip ON security_events (ip);
+CREATE INDEX IF NOT EXISTS idx_security_events_request_id ON security_events (request_id);
@@ -0,0 +1,4 @@
+-- 用户、角色、权限的版本号，用于乐观锁（更新时校验并加一）
+ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE roles ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE permissions ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,7 @@
+-- 已删除的记录与其他记录重名时无法恢复为全表唯一索引，需先彻底删除这些记录
+DROP INDEX IF EXISTS idx_users_email;
+CREATE UNIQUE INDEX idx_users_email ON users (email);
+DROP INDEX IF EXISTS idx_roles_name;
+CREATE UNIQUE INDEX idx_roles_name ON roles (name);
+DROP INDEX IF EXISTS idx_permissions_name;
+CREATE UNIQUE INDEX idx_permissions_name ON permissions (name);
@@ -0,0 +1,7 @@
+-- 唯一索引只约束未删除的记录，软删除后可以用相同的邮箱、名称重新创建
+DROP INDEX IF EXISTS idx_users_email;
+CREATE UNIQUE INDEX idx_users_email ON users (email) WHERE deleted_at IS NULL;
+DROP INDEX IF EXISTS idx_roles_name;
+CREATE UNIQUE INDEX idx_roles_name ON roles (name) WHERE deleted_at IS NULL;
+DROP INDEX IF EXISTS idx_permissions_name;
+CREATE UNIQUE INDEX idx_permissions_name ON permissions (name) WHERE deleted_at IS NULL;
@@ -0,0 +1,313 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go_web/internal/config"
+	"go_web/internal/logger"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// 读写分离：读操作分发到健康的只读副本，写操作、事务内和加锁的读操作使用主库
+// 读己之写：一次请求发生写入后，该请求后续的读操作使用主库；
+// 用户写入后 sticky_window 内，该用户的读操作也使用主库，避免读到副本上尚未同步的旧数据
+
+type usePrimaryKeyType struct{}
+type readYourWritesKeyType struct{}
+
+// UsePrimary 返回强制使用主库的 context，用于不能容忍复制延迟的读操作（如读取后基于结果写入）
+func UsePrimary(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, usePrimaryKeyType{}, true)
+}
+
+// WithReadYourWrites 返回记录写入状态的 context，通过该 context（及其派生的 context）发生写入后，后续读操作都使用主库
+func WithReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readYourWritesKeyType{}, new(atomic.Bool))
+}
+
+// ReplicaStatus 只读副本状态
+type ReplicaStatus struct {
+	Addr    string `json:"addr"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"` // 最近一次检查失败的原因
+}
+
+// replica 只读副本连接池
+type replica struct {
+	addr    string
+	db      *sql.DB
+	healthy atomic.Bool
+	checked atomic.Bool // 是否已完成首次检查
+
+	mu      sync.Mutex
+	lastErr string
+}
+
+// ReplicaResolver GORM 读写分离插件，未配置副本时不注册任何回调
+type ReplicaResolver struct {
+	configManager *config.Manager
+	log           *logger.Logger
+	replicas      []*replica
+	primary       gorm.ConnPool
+	next          atomic.Uint64 // 轮询计数
+	recentWrites  sync.Map      // 用户ID -> 最近一次写入时间
+}
+
+// NewReplicaResolver 按配置创建副本连接池（此时不建立连接），连接池参数与主库相同并支持热更新
+func NewReplicaResolver(configManager *config.Manager, log *logger.Logger) (*ReplicaResolver, error) {
+	cfg := configManager.Current().Database
+	r := &ReplicaResolver{configManager: configManager, log: log}
+	if len(cfg.Replica.Hosts) == 0 {
+		return r, nil
+	}
+
+	sqlDriver, err := lookupDriver(cfg.Driver)
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range cfg.Replica.Hosts {
+		db := sql.OpenDB(&rotatingConnector{configManager: configManager, driver: sqlDriver.driver, replica: addr})
+		applyPoolConfig(db, cfg)
+		r.replicas = append(r.replicas, &replica{addr: addr, db: db})
+	}
+	configManager.Subscribe(func(cfg *config.Config) {
+		for _, rep := range r.replicas {
+			applyPoolConfig(rep.db, cfg.Database)
+		}
+	})
+	return r, nil
+}
+
+// Name 返回插件名称
+func (r *ReplicaResolver) Name() string {
+	return "replica_resolver"
+}
+
+// Initialize 初始化插件
+func (r *ReplicaResolver) Initialize(db *gorm.DB) error {
+	if len(r.replicas) == 0 {
+		return nil
+	}
+	r.primary = db.ConnPool
+
+	callback := db.Callback()
+
+	// 读操作：查询前选择连接，查询失败时检查副本是否可用
+	callback.Query().Before("gorm:query").Register("replica:route", r.routeRead)
+	callback.Query().After("gorm:query").Register("replica:check", r.checkError)
+	callback.Row().Before("gorm:row").Register("replica:route", r.routeRead)
+	callback.Row().After("gorm:row").Register("replica:check", r.checkError)
+
+	// 写操作（Exec 执行的原生 SQL 也视为写操作）：使用主库并记录写入
+	callback.Create().Before("gorm:begin_transaction").Register("replica:write", r.markWrite)
+	callback.Update().Before("gorm:begin_transaction").Register("replica:write", r.markWrite)
+	callback.Delete().Before("gorm:begin_transaction").Register("replica:write", r.markWrite)
+	callback.Raw().Before("gorm:raw").Register("replica:write", r.markWrite)
+
+	return nil
+}
+
+// Start 检查各副本是否可用并启动后台健康检查
+func (r *ReplicaResolver) Start() {
+	if len(r.replicas) == 0 {
+		return
+	}
+
+	r.checkHealth()
+	interval := time.Duration(r.configManager.Current().Database.Replica.HealthInterval) * time.Second
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			r.checkHealth()
+			r.pruneRecentWrites()
+		}
+	}()
+}
+
+// Stats 返回各副本的状态，未配置副本时返回 nil
+func (r *ReplicaResolver) Stats() []ReplicaStatus {
+	if len(r.replicas) == 0 {
+		return nil
+	}
+	stats := make([]ReplicaStatus, 0, len(r.replicas))
+	for _, rep := range r.replicas {
+		rep.mu.Lock()
+		lastErr := rep.lastErr
+		rep.mu.Unlock()
+		stats = append(stats, ReplicaStatus{Addr: rep.addr, Healthy: rep.healthy.Load(), Error: lastErr})
+	}
+	return stats
+}
+
+// routeRead 为读操作选择连接：事务内或固定连接上的读操作使用当前连接，其余读操作在不需要读主库时分发到副本
+func (r *ReplicaResolver) routeRead(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	pool := db.Statement.ConnPool
+	if pool != r.primary && r.replicaOf(pool) == nil {
+		return
+	}
+
+	// 同一个 Statement 可能被多次执行（如先 Count 再 Find），每次都重新选择
+	db.Statement.ConnPool = r.primary
+	if _, locking := db.Statement.Clauses["FOR"]; locking {
+		return
+	}
+	if db.Statement.SQL.Len() > 0 && !isReadOnlySQL(db.Statement.SQL.String()) {
+		return
+	}
+	if r.stickToPrimary(db.Statement.Context) {
+		return
+	}
+	if rep := r.pick(); rep != nil {
+		db.Statement.ConnPool = rep.db
+	}
+}
+
+// markWrite 写操作使用主库，并记录请求和用户的写入状态（审计、安全日志和迁移记录不是业务数据，不记录）
+func (r *ReplicaResolver) markWrite(db *gorm.DB) {
+	if r.replicaOf(db.Statement.ConnPool) != nil {
+		db.Statement.ConnPool = r.primary
+	}
+	if auditSkipTables[db.Statement.Table] {
+		return
+	}
+
+	ctx := db.Statement.Context
+	if ctx == nil {
+		return
+	}
+	if written, ok := ctx.Value(readYourWritesKeyType{}).(*atomic.Bool); ok {
+		written.Store(true)
+	}
+	if userID, ok := ctx.Value(AuditUserIDKey).(uint); ok && userID != 0 {
+		r.recentWrites.Store(userID, time.Now())
+	}
+}
+
+// checkError 副本查询出现连接错误时立即标记为不可用，后续读操作改用其他副本或主库，直到健康检查恢复
+func (r *ReplicaResolver) checkError(db *gorm.DB) {
+	if db.Error == nil || !isConnectionError(db.Error) {
+		return
+	}
+	if rep := r.replicaOf(db.Statement.ConnPool); rep != nil {
+		r.setHealth(rep, db.Error)
+	}
+}
+
+// stickToPrimary 判断读操作是否需要使用主库
+func (r *ReplicaResolver) stickToPrimary(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	if primary, _ := ctx.Value(usePrimaryKeyType{}).(bool); primary {
+		return true
+	}
+	if written, ok := ctx.Value(readYourWritesKeyType{}).(*atomic.Bool); ok && written.Load() {
+		return true
+	}
+	if userID, ok := ctx.Value(AuditUserIDKey).(uint); ok {
+		if at, ok := r.recentWrites.Load(userID); ok && time.Since(at.(time.Time)) < r.stickyWindow() {
+			return true
+		}
+	}
+	return false
+}
+
+// pick 轮询选择一个健康的副本，全部不可用时返回 nil（使用主库）
+func (r *ReplicaResolver) pick() *replica {
+	n := uint64(len(r.replicas))
+	start := r.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		if rep := r.replicas[(start+i)%n]; rep.healthy.Load() {
+			return rep
+		}
+	}
+	return nil
+}
+
+// replicaOf 返回连接池对应的副本，不是副本时返回 nil
+func (r *ReplicaResolver) replicaOf(pool gorm.ConnPool) *replica {
+	for _, rep := range r.replicas {
+		if pool == gorm.ConnPool(rep.db) {
+			return rep
+		}
+	}
+	return nil
+}
+
+// checkHealth 检查各副本是否可用，超时时间与建立连接的超时时间一致
+func (r *ReplicaResolver) checkHealth() {
+	cfg := r.configManager.Current().Database
+	for _, rep := range r.replicas {
+		r.setHealth(rep, pingDatabase(rep.db, cfg))
+	}
+}
+
+// setHealth 更新副本状态，状态变化时记录日志
+func (r *ReplicaResolver) setHealth(rep *replica, err error) {
+	rep.mu.Lock()
+	if err != nil {
+		rep.lastErr = err.Error()
+	} else {
+		rep.lastErr = ""
+	}
+	rep.mu.Unlock()
+
+	wasHealthy := rep.healthy.Swap(err == nil)
+	firstCheck := !rep.checked.Swap(true)
+	switch {
+	case err != nil && (wasHealthy || firstCheck):
+		r.log.Warnf("只读副本 %s 不可用，读操作改用其他副本或主库: %v", rep.addr, err)
+	case err == nil && firstCheck:
+		r.log.Infof("只读副本 %s 连接成功", rep.addr)
+	case err == nil && !wasHealthy:
+		r.log.Infof("只读副本 %s 已恢复", rep.addr)
+	}
+}
+
+// stickyWindow 用户写入后读主库的时长
+func (r *ReplicaResolver) stickyWindow() time.Duration {
+	return time.Duration(r.configManager.Current().Database.Replica.StickyWindow) * time.Second
+}
+
+// pruneRecentWrites 清理超过 sticky_window 的写入记录
+func (r *ReplicaResolver) pruneRecentWrites() {
+	window := r.stickyWindow()
+	r.recentWrites.Range(func(key, value interface{}) bool {
+		if time.Since(value.(time.Time)) >= window {
+			r.recentWrites.Delete(key)
+		}
+		return true
+	})
+}
+
+// isReadOnlySQL 判断原生 SQL 是否为不加锁的查询
+func isReadOnlySQL(sql string) bool {
+	sql = strings.ToLower(strings.TrimSpace(sql))
+	if !strings.HasPrefix(sql, "select") {
+		return false
+	}
+	return !strings.Contains(sql, " for update") && !strings.Contains(sql, " for share") && !strings.Contains(sql, " lock in share mode")
+}
+
+// isConnectionError 判断是否为连接类错误（连接断开、网络超时等），SQL 本身的错误不影响副本状态
+func isConnectionError(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysqlDriver.ErrInvalidConn) || errors.As(err, &netErr)
+}
@@ -0,0 +1,768 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"go_web/internal/model"
+
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// ErrSeedPasswordRequired 需要创建管理员或重置密码，但没有提供密码
+var ErrSeedPasswordRequired = errors.New("需要提供管理员密码")
+
+// 种子数据变更类型
+const (
+	SeedActionCreate = "create"
+	SeedActionUpdate = "update"
+	SeedActionDelete = "delete"
+)
+
+// SeedData 声明式种子数据（YAML），描述期望的权限、角色、角色权限和初始管理员
+type SeedData struct {
+	Permissions []SeedPermission `yaml:"permissions"`
+	Roles       []SeedRole       `yaml:"roles"`
+	Admin       *SeedAdmin       `yaml:"admin"`
+}
+
+// SeedPermission 种子权限
+type SeedPermission struct {
+	Name        string `yaml:"name"`
+	DisplayName string `yaml:"display_name"`
+	Description string `yaml:"description"`
+	Resource    string `yaml:"resource"` // 为空时取名称中冒号前的部分
+	Action      string `yaml:"action"`   // 为空时取名称中冒号后的部分
+	Status      *int   `yaml:"status"`   // 默认 1
+}
+
+// SeedRole 种子角色
+type SeedRole struct {
+	Name        string `yaml:"name"`
+	DisplayName string `yaml:"display_name"`
+	Description string `yaml:"description"`
+	Status      *int   `yaml:"status"` // 默认 1
+	// Permissions 角色拥有的权限，支持通配符（如 *、server:*、*:read），以 ! 开头表示排除
+	Permissions []string `yaml:"permissions"`
+
+	permissions []string // 展开通配符后的权限名称
+}
+
+// SeedAdmin 初始管理员，密码不写在文件中，创建时由命令行生成或输入
+type SeedAdmin struct {
+	Name  string   `yaml:"name"`
+	Email string   `yaml:"email"`
+	Roles []string `yaml:"roles"`
+}
+
+// LoadSeedData 读取并校验种子数据文件
+func LoadSeedData(file string) (*SeedData, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取种子数据文件失败: %v", err)
+	}
+
+	var data SeedData
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&data); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("解析种子数据文件 %s 失败: %v", file, err)
+	}
+
+	if problems := data.normalize(); len(problems) > 0 {
+		return nil, fmt.Errorf("种子数据无效:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return &data, nil
+}
+
+// normalize 填充默认值、展开角色权限通配符，返回全部问题
+func (d *SeedData) normalize() []string {
+	var problems []string
+	addf := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	permissionNames := make([]string, 0, len(d.Permissions))
+	seen := make(map[string]bool)
+	for i := range d.Permissions {
+		p := &d.Permissions[i]
+		if p.Name == "" {
+			addf("permissions[%d].name: 不能为空", i)
+			continue
+		}
+		if seen[p.Name] {
+			addf("permissions[%d].name: 重复的权限 %s", i, p.Name)
+			continue
+		}
+		seen[p.Name] = true
+		permissionNames = append(permissionNames, p.Name)
+
+		if resource, action, ok := strings.Cut(p.Name, ":"); ok {
+			if p.Resource == "" {
+				p.Resource = resource
+			}
+			if p.Action == "" {
+				p.Action = action
+			}
+		}
+		if p.DisplayName == "" {
+			addf("permissions[%d].display_name: 权限 %s 的显示名称不能为空", i, p.Name)
+		}
+		if p.Resource == "" || p.Action == "" {
+			addf("permissions[%d]: 权限 %s 缺少 resource/action（名称不是 资源:操作 格式时需要显式指定）", i, p.Name)
+		}
+		if p.Status == nil {
+			p.Status = seedDefaultStatus()
+		}
+	}
+
+	roles := make(map[string]bool)
+	for i := range d.Roles {
+		r := &d.Roles[i]
+		if r.Name == "" {
+			addf("roles[%d].name: 不能为空", i)
+			continue
+		}
+		if roles[r.Name] {
+			addf("roles[%d].name: 重复的角色 %s", i, r.Name)
+			continue
+		}
+		roles[r.Name] = true
+		if r.DisplayName == "" {
+			addf("roles[%d].display_name: 角色 %s 的显示名称不能为空", i, r.Name)
+		}
+		if r.Status == nil {
+			r.Status = seedDefaultStatus()
+		}
+
+		expanded, err := expandPermissionPatterns(r.Permissions, permissionNames)
+		if err != nil {
+			addf("roles[%d].permissions: 角色 %s %v", i, r.Name, err)
+		}
+		r.permissions = expanded
+	}
+
+	if d.Admin != nil {
+		if d.Admin.Email == "" {
+			addf("admin.email: 不能为空")
+		}
+		if d.Admin.Name == "" {
+			addf("admin.name: 不能为空")
+		}
+		for _, role := range d.Admin.Roles {
+			if !roles[role] {
+				addf("admin.roles: 角色 %s 未在 roles 中声明", role)
+			}
+		}
+	}
+
+	return problems
+}
+
+// expandPermissionPatterns 展开权限通配符，先合并全部包含规则，再去掉排除规则
+// 每条规则至少要匹配一个权限，避免拼写错误被静默忽略
+func expandPermissionPatterns(patterns []string, names []string) ([]string, error) {
+	included := make(map[string]bool)
+	var excludes []string
+	for _, pattern := range patterns {
+		if exclude, ok := strings.CutPrefix(pattern, "!"); ok {
+			excludes = append(excludes, exclude)
+			continue
+		}
+		matched, err := matchPermissions(pattern, names)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range matched {
+			included[name] = true
+		}
+	}
+	for _, pattern := range excludes {
+		matched, err := matchPermissions(pattern, names)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range matched {
+			delete(included, name)
+		}
+	}
+
+	result := make([]string, 0, len(included))
+	for _, name := range names {
+		if included[name] {
+			result = append(result, name)
+		}
+	}
+	return result, nil
+}
+
+// matchPermissions 返回匹配规则的权限名称
+func matchPermissions(pattern string, names []string) ([]string, error) {
+	var matched []string
+	for _, name := range names {
+		ok, err := path.Match(pattern, name)
+		if err != nil {
+			return nil, fmt.Errorf("的权限规则 %q 无效: %v", pattern, err)
+		}
+		if ok {
+			matched = append(matched, name)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("的权限规则 %q 没有匹配任何已声明的权限", pattern)
+	}
+	return matched, nil
+}
+
+func seedDefaultStatus() *int {
+	status := 1
+	return &status
+}
+
+// SeedOptions 种子数据同步选项
+type SeedOptions struct {
+	DryRun             bool   // 只计算变更，不写入数据库
+	Prune              bool   // 删除文件中未声明的权限、角色，以及已声明角色和管理员上多余的关联
+	ResetAdminPassword bool   // 管理员已存在时重置密码
+	AdminPassword      string // 创建管理员或重置密码时使用的密码
+}
+
+// SeedFieldChange 字段变更
+type SeedFieldChange struct {
+	Field string
+	Old   interface{}
+	New   interface{}
+}
+
+// SeedChange 一项种子数据变更
+type SeedChange struct {
+	Action        string            // create/update/delete
+	Kind          string            // 对象类型，如 权限、角色、角色权限
+	Name          string            // 对象名称，关联关系为 "角色 → 权限"
+	Fields        []SeedFieldChange // 更新的字段
+	NeedsPassword bool              // 需要管理员密码（创建管理员或重置密码）
+
+	apply func(tx *gorm.DB, ids *seedIDs, opts SeedOptions) error
+}
+
+// String 以 diff 的形式输出变更，如 "~ 角色 admin（display_name: "管理员" → "系统管理员"）"
+func (c SeedChange) String() string {
+	sign := map[string]string{SeedActionCreate: "+", SeedActionUpdate: "~", SeedActionDelete: "-"}[c.Action]
+	line := fmt.Sprintf("%s %s %s", sign, c.Kind, c.Name)
+	if len(c.Fields) == 0 {
+		return line
+	}
+	fields := make([]string, 0, len(c.Fields))
+	for _, field := range c.Fields {
+		fields = append(fields, fmt.Sprintf("%s: %s → %s", field.Field, formatSeedValue(field.Old), formatSeedValue(field.New)))
+	}
+	return line + "（" + strings.Join(fields, ", ") + "）"
+}
+
+func formatSeedValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("%q", v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// seedIDs 名称到ID的映射，执行变更时记录新建记录的ID
+type seedIDs struct {
+	permissions map[string]uint
+	roles       map[string]uint
+	admin       uint
+}
+
+// Seeder 种子数据同步器，将数据库中的权限、角色和初始管理员调整为种子数据文件描述的状态
+type Seeder struct {
+	db *gorm.DB
+}
+
+// NewSeeder 创建种子数据同步器
+func NewSeeder(db *gorm.DB) *Seeder {
+	return &Seeder{db: db}
+}
+
+// Sync 比较种子数据与数据库，返回需要的变更；非 DryRun 时在一个事务中执行全部变更
+// 重复执行是幂等的：数据库已与文件一致时不返回任何变更
+func (s *Seeder) Sync(ctx context.Context, data *SeedData, opts SeedOptions) ([]SeedChange, error) {
+	if opts.DryRun {
+		changes, _, err := s.plan(s.db.WithContext(ctx), data, opts)
+		return changes, err
+	}
+
+	// 审计日志暂存到事务提交后再写入，任一变更失败回滚时不留下未生效变更的审计记录
+	ctx, buffer := WithAuditBuffer(ctx)
+	var changes []SeedChange
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids *seedIDs
+		var err error
+		changes, ids, err = s.plan(tx, data, opts)
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			if change.NeedsPassword && opts.AdminPassword == "" {
+				return ErrSeedPasswordRequired
+			}
+			if err := change.apply(tx, ids, opts); err != nil {
+				return fmt.Errorf("%s %s 失败: %v", seedActionNames[change.Action], change.Name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	buffer.Flush()
+	return changes, nil
+}
+
+var seedActionNames = map[string]string{
+	SeedActionCreate: "创建",
+	SeedActionUpdate: "更新",
+	SeedActionDelete: "删除",
+}
+
+// plan 读取数据库当前状态（包含软删除的记录），计算变更
+// 软删除的同名记录会被恢复而不是重新创建，避免违反唯一索引
+func (s *Seeder) plan(db *gorm.DB, data *SeedData, opts SeedOptions) ([]SeedChange, *seedIDs, error) {
+	ids := &seedIDs{
+		permissions: make(map[string]uint),
+		roles:       make(map[string]uint),
+	}
+
+	var permissions []model.Permission
+	if err := db.Unscoped().Order("id").Find(&permissions).Error; err != nil {
+		return nil, nil, err
+	}
+	var roles []model.Role
+	if err := db.Unscoped().Order("id").Find(&roles).Error; err != nil {
+		return nil, nil, err
+	}
+	var rolePermissions []model.RolePermission
+	if err := db.Unscoped().Order("id").Find(&rolePermissions).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var changes []SeedChange
+	changes = append(changes, planPermissions(permissions, data, opts, ids)...)
+	changes = append(changes, planRoles(roles, data, opts, ids)...)
+	changes = append(changes, planRolePermissions(permissions, roles, rolePermissions, data, opts, ids)...)
+
+	if data.Admin != nil {
+		adminChanges, err := planAdmin(db, roles, data.Admin, opts, ids)
+		if err != nil {
+			return nil, nil, err
+		}
+		changes = append(changes, adminChanges...)
+	}
+
+	return changes, ids, nil
+}
+
+// permissionsByName 按名称索引权限；唯一索引只约束未删除的记录，同名的记录可能有多条，优先使用未删除的记录
+func permissionsByName(permissions []model.Permission) map[string]model.Permission {
+	byName := make(map[string]model.Permission, len(permissions))
+	for _, p := range permissions {
+		if current, ok := byName[p.Name]; ok && !current.DeletedAt.Valid {
+			continue
+		}
+		byName[p.Name] = p
+	}
+	return byName
+}
+
+// rolesByName 按名称索引角色，同名时优先使用未删除的记录（同 permissionsByName）
+func rolesByName(roles []model.Role) map[string]model.Role {
+	byName := make(map[string]model.Role, len(roles))
+	for _, r := range roles {
+		if current, ok := byName[r.Name]; ok && !current.DeletedAt.Valid {
+			continue
+		}
+		byName[r.Name] = r
+	}
+	return byName
+}
+
+// planPermissions 计算权限的新增、更新（含恢复）和删除
+func planPermissions(existing []model.Permission, data *SeedData, opts SeedOptions, ids *seedIDs) []SeedChange {
+	byName := permissionsByName(existing)
+
+	var changes []SeedChange
+	declared := make(map[string]bool, len(data.Permissions))
+	for _, p := range data.Permissions {
+		p := p
+		declared[p.Name] = true
+		current, ok := byName[p.Name]
+		if !ok {
+			changes = append(changes, SeedChange{
+				Action: SeedActionCreate,
+				Kind:   "权限",
+				Name:   p.Name,
+				apply: func(tx *gorm.DB, ids *seedIDs, _ SeedOptions) error {
+					permission := &model.Permission{
+						Name:        p.Name,
+						DisplayName: p.DisplayName,
+						Description: p.Description,
+						Resource:    p.Resource,
+						Action:      p.Action,
+						Status:      *p.Status,
+					}
+					if err := tx.Create(permission).Error; err != nil {
+						return err
+					}
+					ids.permissions[p.Name] = permission.ID
+					return nil
+				},
+			})
+			continue
+		}
+
+		ids.permissions[p.Name] = current.ID
+		fields := diffFields(current.DeletedAt,
+			seedField("display_name", current.DisplayName, p.DisplayName),
+			seedField("description", current.Description, p.Description),
+			seedField("resource", current.Resource, p.Resource),
+			seedField("action", current.Action, p.Action),
+			seedField("status", current.Status, *p.Status),
+		)
+		if len(fields) > 0 {
+			changes = append(changes, updateChange("权限", p.Name, &model.Permission{ID: current.ID}, fields))
+		}
+	}
+
+	if opts.Prune {
+		for _, p := range existing {
+			if !declared[p.Name] && !p.DeletedAt.Valid {
+				changes = append(changes, deleteChange("权限", p.Name, &model.Permission{}, p.ID))
+			}
+		}
+	}
+	return changes
+}
+
+// planRoles 计算角色的新增、更新（含恢复）和删除
+func planRoles(existing []model.Role, data *SeedData, opts SeedOptions, ids *seedIDs) []SeedChange {
+	byName := rolesByName(existing)
+
+	var changes []SeedChange
+	declared := make(map[string]bool, len(data.Roles))
+	for _, r := range data.Roles {
+		r := r
+		declared[r.Name] = true
+		current, ok := byName[r.Name]
+		if !ok {
+			changes = append(changes, SeedChange{
+				Action: SeedActionCreate,
+				Kind:   "角色",
+				Name:   r.Name,
+				apply: func(tx *gorm.DB, ids *seedIDs, _ SeedOptions) error {
+					role := &model.Role{
+						Name:        r.Name,
+						DisplayName: r.DisplayName,
+						Description: r.Description,
+						Status:      *r.Status,
+					}
+					if err := tx.Create(role).Error; err != nil {
+						return err
+					}
+					ids.roles[r.Name] = role.ID
+					return nil
+				},
+			})
+			continue
+		}
+
+		ids.roles[r.Name] = current.ID
+		fields := diffFields(current.DeletedAt,
+			seedField("display_name", current.DisplayName, r.DisplayName),
+			seedField("description", current.Description, r.Description),
+			seedField("status", current.Status, *r.Status),
+		)
+		if len(fields) > 0 {
+			changes = append(changes, updateChange("角色", r.Name, &model.Role{ID: current.ID}, fields))
+		}
+	}
+
+	if opts.Prune {
+		for _, r := range existing {
+			if !declared[r.Name] && !r.DeletedAt.Valid {
+				changes = append(changes, deleteChange("角色", r.Name, &model.Role{}, r.ID))
+			}
+		}
+	}
+	return changes
+}
+
+// planRolePermissions 计算已声明角色的权限关联，Prune 时删除多余的关联
+func planRolePermissions(permissions []model.Permission, roles []model.Role, existing []model.RolePermission, data *SeedData, opts SeedOptions, ids *seedIDs) []SeedChange {
+	// 只按名称对应的记录索引关联，忽略同名的已删除记录上的关联
+	permissionNames := make(map[uint]string, len(permissions))
+	for name, p := range permissionsByName(permissions) {
+		permissionNames[p.ID] = name
+	}
+	roleNames := make(map[uint]string, len(roles))
+	for name, r := range rolesByName(roles) {
+		roleNames[r.ID] = name
+	}
+
+	// 按 角色名称 → 权限名称 索引现有关联
+	current := make(map[string]map[string]model.RolePermission)
+	for _, rp := range existing {
+		roleName, permissionName := roleNames[rp.RoleID], permissionNames[rp.PermissionID]
+		if roleName == "" || permissionName == "" {
+			continue
+		}
+		if current[roleName] == nil {
+			current[roleName] = make(map[string]model.RolePermission)
+		}
+		current[roleName][permissionName] = rp
+	}
+
+	var changes []SeedChange
+	for _, r := range data.Roles {
+		desired := make(map[string]bool, len(r.permissions))
+		for _, permissionName := range r.permissions {
+			desired[permissionName] = true
+			name := r.Name + " → " + permissionName
+			rp, ok := current[r.Name][permissionName]
+			switch {
+			case !ok:
+				roleName, permissionName := r.Name, permissionName
+				changes = append(changes, SeedChange{
+					Action: SeedActionCreate,
+					Kind:   "角色权限",
+					Name:   name,
+					apply: func(tx *gorm.DB, ids *seedIDs, _ SeedOptions) error {
+						return tx.Create(&model.RolePermission{
+							RoleID:       ids.roles[roleName],
+							PermissionID: ids.permissions[permissionName],
+						}).Error
+					},
+				})
+			case rp.DeletedAt.Valid:
+				changes = append(changes, updateChange("角色权限", name, &model.RolePermission{ID: rp.ID}, diffFields(rp.DeletedAt)))
+			}
+		}
+
+		if !opts.Prune {
+			continue
+		}
+		var removed []SeedChange
+		for permissionName, rp := range current[r.Name] {
+			if !desired[permissionName] && !rp.DeletedAt.Valid {
+				removed = append(removed, unlinkChange("角色权限", r.Name+" → "+permissionName, &model.RolePermission{}, rp.ID))
+			}
+		}
+		sortChanges(removed)
+		changes = append(changes, removed...)
+	}
+
+	return changes
+}
+
+// planAdmin 计算初始管理员及其角色的变更，管理员已存在时只在 ResetAdminPassword 时修改密码
+func planAdmin(db *gorm.DB, roles []model.Role, admin *SeedAdmin, opts SeedOptions, ids *seedIDs) ([]SeedChange, error) {
+	var changes []SeedChange
+
+	// 同一邮箱可能有多个已删除的用户，优先使用未删除的用户，其次是最近删除的用户
+	var user model.User
+	err := db.Where("email = ?", admin.Email).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = db.Unscoped().Where("email = ?", admin.Email).Last(&user).Error
+	}
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		changes = append(changes, SeedChange{
+			Action:        SeedActionCreate,
+			Kind:          "用户",
+			Name:          admin.Email,
+			NeedsPassword: true,
+			apply: func(tx *gorm.DB, ids *seedIDs, opts SeedOptions) error {
+				hashedPassword, err := bcrypt.GenerateFromPassword([]byte(opts.AdminPassword), bcrypt.DefaultCost)
+				if err != nil {
+					return errors.New("密码加密失败")
+				}
+				user := &model.User{
+					Name:     admin.Name,
+					Email:    admin.Email,
+					Password: string(hashedPassword),
+					Status:   1,
+				}
+				if err := tx.Create(user).Error; err != nil {
+					return err
+				}
+				ids.admin = user.ID
+				return nil
+			},
+		})
+	case err != nil:
+		return nil, err
+	default:
+		ids.admin = user.ID
+		fields := diffFields(user.DeletedAt,
+			seedField("name", user.Name, admin.Name),
+			seedField("status", user.Status, 1),
+		)
+		if len(fields) > 0 {
+			changes = append(changes, updateChange("用户", admin.Email, &model.User{ID: user.ID}, fields))
+		}
+		if opts.ResetAdminPassword {
+			changes = append(changes, SeedChange{
+				Action:        SeedActionUpdate,
+				Kind:          "用户",
+				Name:          admin.Email,
+				Fields:        []SeedFieldChange{{Field: "password", Old: "******", New: "******"}},
+				NeedsPassword: true,
+				apply: func(tx *gorm.DB, ids *seedIDs, opts SeedOptions) error {
+					hashedPassword, err := bcrypt.GenerateFromPassword([]byte(opts.AdminPassword), bcrypt.DefaultCost)
+					if err != nil {
+						return errors.New("密码加密失败")
+					}
+					return tx.Model(&model.User{ID: ids.admin}).Updates(map[string]interface{}{
+						"password": string(hashedPassword),
+						"version":  gorm.Expr("version + 1"),
+					}).Error
+				},
+			})
+		}
+	}
+
+	// 管理员的角色关联
+	roleNames := make(map[uint]string, len(roles))
+	for name, r := range rolesByName(roles) {
+		roleNames[r.ID] = name
+	}
+	current := make(map[string]model.UserRole)
+	if user.ID != 0 {
+		var userRoles []model.UserRole
+		if err := db.Unscoped().Where("user_id = ?", user.ID).Order("id").Find(&userRoles).Error; err != nil {
+			return nil, err
+		}
+		for _, ur := range userRoles {
+			if name := roleNames[ur.RoleID]; name != "" {
+				current[name] = ur
+			}
+		}
+	}
+
+	desired := make(map[string]bool, len(admin.Roles))
+	for _, roleName := range admin.Roles {
+		roleName := roleName
+		desired[roleName] = true
+		name := admin.Email + " → " + roleName
+		ur, ok := current[roleName]
+		switch {
+		case !ok:
+			changes = append(changes, SeedChange{
+				Action: SeedActionCreate,
+				Kind:   "用户角色",
+				Name:   name,
+				apply: func(tx *gorm.DB, ids *seedIDs, _ SeedOptions) error {
+					return tx.Create(&model.UserRole{UserID: ids.admin, RoleID: ids.roles[roleName]}).Error
+				},
+			})
+		case ur.DeletedAt.Valid:
+			changes = append(changes, updateChange("用户角色", name, &model.UserRole{ID: ur.ID}, diffFields(ur.DeletedAt)))
+		}
+	}
+	if opts.Prune {
+		var removed []SeedChange
+		for roleName, ur := range current {
+			if !desired[roleName] && !ur.DeletedAt.Valid {
+				removed = append(removed, unlinkChange("用户角色", admin.Email+" → "+roleName, &model.UserRole{}, ur.ID))
+			}
+		}
+		sortChanges(removed)
+		changes = append(changes, removed...)
+	}
+
+	return changes, nil
+}
+
+// seedField 构造字段变更，值相同时返回 nil
+func seedField(field string, old, new interface{}) *SeedFieldChange {
+	if old == new {
+		return nil
+	}
+	return &SeedFieldChange{Field: field, Old: old, New: new}
+}
+
+// diffFields 汇总有变化的字段，记录已被软删除时追加恢复（deleted_at 置空）
+func diffFields(deletedAt gorm.DeletedAt, fields ...*SeedFieldChange) []SeedFieldChange {
+	var result []SeedFieldChange
+	for _, field := range fields {
+		if field != nil {
+			result = append(result, *field)
+		}
+	}
+	if deletedAt.Valid {
+		result = append(result, SeedFieldChange{Field: "deleted_at", Old: deletedAt.Time.Local().Format(time.DateTime), New: nil})
+	}
+	return result
+}
+
+// updateChange 按字段更新记录（包含软删除的记录）
+func updateChange(kind, name string, record interface{}, fields []SeedFieldChange) SeedChange {
+	return SeedChange{
+		Action: SeedActionUpdate,
+		Kind:   kind,
+		Name:   name,
+		Fields: fields,
+		apply: func(tx *gorm.DB, _ *seedIDs, _ SeedOptions) error {
+			values := make(map[string]interface{}, len(fields)+1)
+			for _, field := range fields {
+				values[field.Field] = field.New
+			}
+			// 与接口更新一致，版本号加一，使客户端持有的旧版本失效
+			values["version"] = gorm.Expr("version + 1")
+			return tx.Unscoped().Model(record).Updates(values).Error
+		},
+	}
+}
+
+// deleteChange 软删除记录，与接口删除的行为一致
+func deleteChange(kind, name string, record interface{}, id uint) SeedChange {
+	return SeedChange{
+		Action: SeedActionDelete,
+		Kind:   kind,
+		Name:   name,
+		apply: func(tx *gorm.DB, _ *seedIDs, _ SeedOptions) error {
+			return tx.Delete(record, id).Error
+		},
+	}
+}
+
+// unlinkChange 物理删除关联记录，与接口移除关联的行为一致
+func unlinkChange(kind, name string, record interface{}, id uint) SeedChange {
+	return SeedChange{
+		Action: SeedActionDelete,
+		Kind:   kind,
+		Name:   name,
+		apply: func(tx *gorm.DB, _ *seedIDs, _ SeedOptions) error {
+			return tx.Unscoped().Delete(record, id).Error
+		},
+	}
+}
+
+// sortChanges 按名称排序来自 map 遍历的变更，保证输出稳定
+func sortChanges(changes []SeedChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+}
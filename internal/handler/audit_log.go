@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go_web/internal/apperr"
+	"go_web/internal/i18n"
+	"go_web/internal/repository"
+	"go_web/internal/service"
+	"go_web/internal/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditLogHandler struct {
+	auditLogService service.AuditLogService
+}
+
+func NewAuditLogHandler(auditLogService service.AuditLogService) *AuditLogHandler {
+	return &AuditLogHandler{auditLogService: auditLogService}
+}
+
+// ExportAuditLogs 导出审计日志
+// @Summary      导出审计日志
+// @Description  按条件流式导出审计日志，支持 CSV 和 NDJSON 格式
+// @Tags         审计日志
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Param        format        query     string  false  "导出格式（csv/ndjson）"  default(csv)
+// @Param        table_name    query     string  false  "表名"
+// @Param        record_id     query     int     false  "记录ID"
+// @Param        action        query     string  false  "操作类型（create/update/delete）"
+// @Param        user_id       query     int     false  "操作者用户ID"
+// @Param        actor         query     string  false  "操作来源（http/cli）"
+// @Param        request_id    query     string  false  "HTTP 请求ID"
+// @Param        start_time    query     string  false  "起始时间（RFC3339 或 2006-01-02，包含）"
+// @Param        end_time      query     string  false  "结束时间（RFC3339 或 2006-01-02，不包含）"
+// @Param        Authorization header    string  true   "Bearer {token}"  default(Bearer )
+// @Success      200           {file}    file
+// @Failure      400           {object}  util.Response
+// @Failure      401           {object}  util.Response
+// @Failure      403           {object}  util.Response
+// @Router       /audit-logs/export [get]
+func (h *AuditLogHandler) ExportAuditLogs(c *gin.Context) {
+	format := c.DefaultQuery("format", service.ExportFormatCSV)
+	var contentType string
+	switch format {
+	case service.ExportFormatCSV:
+		contentType = "text/csv; charset=utf-8"
+	case service.ExportFormatNDJSON:
+		contentType = "application/x-ndjson"
+	default:
+		util.Fail(c, apperr.ErrUnsupportedFormat.WithMessage(i18n.MsgExportFormat, format))
+		return
+	}
+
+	filter, err := parseAuditLogFilter(c)
+	if err != nil {
+		util.Fail(c, err)
+		return
+	}
+
+	fileName := fmt.Sprintf("audit_logs_%s.%s", time.Now().Format("20060102150405"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileName))
+	c.Status(http.StatusOK)
+
+	// 数据已开始写出，出错时只能记录错误，无法再修改响应状态
+	if err := h.auditLogService.ExportAuditLogs(c.Request.Context(), filter, format, c.Writer); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// GetRequestAudit 获取请求审计详情
+// @Summary      获取请求审计详情
+// @Description  根据请求ID获取 HTTP 审计事件及该请求产生的数据变更记录
+// @Tags         审计日志
+// @Accept       json
+// @Produce      json
+// @Param        request_id    path      string  true  "请求ID（响应头 X-Request-ID）"
+// @Param        Authorization header    string  true  "Bearer {token}"  default(Bearer )
+// @Success      200           {object}  util.Response{data=service.RequestAudit}
+// @Failure      401           {object}  util.Response
+// @Failure      403           {object}  util.Response
+// @Failure      404           {object}  util.Response
+// @Router       /audit-logs/requests/{request_id} [get]
+func (h *AuditLogHandler) GetRequestAudit(c *gin.Context) {
+	requestAudit, err := h.auditLogService.GetRequestAudit(c.Request.Context(), c.Param("request_id"))
+	if err != nil {
+		util.Fail(c, err)
+		return
+	}
+
+	util.Success(c, requestAudit)
+}
+
+// RevertAuditLog 根据审计日志回滚记录
+// @Summary      根据审计日志回滚记录
+// @Description  使用审计日志中的旧值快照恢复已软删除的用户/角色/权限，或将记录回滚到该次更新前的状态，或重新建立被解除的用户角色、角色权限关联，回滚本身记录为 revert 操作
+// @Tags         审计日志
+// @Accept       json
+// @Produce      json
+// @Param        id            path      int     true  "审计日志ID"
+// @Param        Authorization header    string  true  "Bearer {token}"  default(Bearer )
+// @Success      200           {object}  util.Response{data=service.RevertResult}
+// @Failure      400           {object}  util.Response
+// @Failure      401           {object}  util.Response
+// @Failure      403           {object}  util.Response
+// @Failure      404           {object}  util.Response
+// @Failure      409           {object}  util.Response
+// @Failure      500           {object}  util.Response
+// @Router       /audit-logs/{id}/revert [post]
+func (h *AuditLogHandler) RevertAuditLog(c *gin.Context) {
+	id, ok := parseID(c, i18n.MsgResourceAuditLog)
+	if !ok {
+		return
+	}
+
+	result, err := h.auditLogService.RevertAuditLog(c.Request.Context(), id)
+	if err != nil {
+		util.Fail(c, err)
+		return
+	}
+
+	util.SuccessWithMessage(c, i18n.M(i18n.MsgAuditLogReverted), result)
+}
+
+// parseAuditLogFilter 从查询参数解析审计日志过滤条件
+func parseAuditLogFilter(c *gin.Context) (repository.AuditLogFilter, error) {
+	filter := repository.AuditLogFilter{
+		TableName: c.Query("table_name"),
+		Action:    c.Query("action"),
+		RequestID: c.Query("request_id"),
+		Actor:     c.Query("actor"),
+	}
+
+	if recordID := c.Query("record_id"); recordID != "" {
+		id, err := strconv.ParseUint(recordID, 10, 32)
+		if err != nil {
+			return filter, apperr.ErrBadRequest.WithMessage(i18n.MsgInvalidIDValue, i18n.M(i18n.MsgResourceRecord), recordID)
+		}
+		filter.RecordID = uint(id)
+	}
+
+	if userID := c.Query("user_id"); userID != "" {
+		id, err := strconv.ParseUint(userID, 10, 32)
+		if err != nil {
+			return filter, apperr.ErrBadRequest.WithMessage(i18n.MsgInvalidIDValue, i18n.M(i18n.MsgResourceUser), userID)
+		}
+		filter.UserID = uint(id)
+	}
+
+	startTime, err := service.ParseFilterTime(c.Query("start_time"))
+	if err != nil {
+		return filter, err
+	}
+	filter.StartTime = startTime
+
+	endTime, err := service.ParseFilterTime(c.Query("end_time"))
+	if err != nil {
+		return filter, err
+	}
+	filter.EndTime = endTime
+
+	return filter, nil
+}
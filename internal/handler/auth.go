@@ -1,7 +1,12 @@
 package handler
 
 import (
+	"context"
+
+	"go_web/internal/apperr"
 	"go_web/internal/config"
+	"go_web/internal/i18n"
+	"go_web/internal/model"
 	"go_web/internal/service"
 	"go_web/internal/util"
 
@@ -10,14 +15,16 @@ import (
 )
 
 type AuthHandler struct {
-	userService service.UserService
-	config      *config.Config
+	userService          service.UserService
+	securityEventService service.SecurityEventService
+	configManager        *config.Manager
 }
 
-func NewAuthHandler(userService service.UserService, cfg *config.Config) *AuthHandler {
+func NewAuthHandler(userService service.UserService, securityEventService service.SecurityEventService, configManager *config.Manager) *AuthHandler {
 	return &AuthHandler{
-		userService: userService,
-		config:      cfg,
+		userService:          userService,
+		securityEventService: securityEventService,
+		configManager:        configManager,
 	}
 }
 
@@ -44,35 +51,38 @@ type LoginResponse struct {
 // @Router       /login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		util.BadRequestWithError(c, "请求参数错误", err)
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	// 1. 根据邮箱查找用户
-	user, err := h.userService.GetUserByEmail(req.Email)
+	user, err := h.userService.GetUserByEmail(c.Request.Context(), req.Email)
 	if err != nil {
-		util.Unauthorized(c, "邮箱或密码错误")
+		h.recordLoginFailure(c, req.Email, 0, "用户不存在")
+		util.Fail(c, apperr.ErrInvalidCredentials)
 		return
 	}
 
 	// 2. 验证密码（使用 bcrypt）
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
 	if err != nil {
-		util.Unauthorized(c, "邮箱或密码错误")
+		h.recordLoginFailure(c, req.Email, user.ID, "密码错误")
+		util.Fail(c, apperr.ErrInvalidCredentials)
 		return
 	}
 
 	// 3. 检查用户状态
 	if user.Status != 1 {
-		util.Unauthorized(c, "用户已被禁用")
+		h.recordLoginFailure(c, req.Email, user.ID, "用户已被禁用")
+		util.Fail(c, apperr.ErrUserDisabled)
 		return
 	}
 
 	// 4. 生成 JWT Token
-	token, err := util.GenerateToken(h.config, user.ID, user.Email)
+	// 使用最新配置生成 token，过期时间支持热更新
+	token, err := util.GenerateToken(h.configManager.Current(), user.ID, user.Email)
 	if err != nil {
-		util.InternalServerError(c, "生成 token 失败")
+		util.Fail(c, apperr.ErrInternal.WithMessage(i18n.MsgTokenFailed).Wrap(err))
 		return
 	}
 
@@ -86,3 +96,21 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		},
 	})
 }
+
+// recordLoginFailure 记录登录失败的安全事件
+func (h *AuthHandler) recordLoginFailure(c *gin.Context, email string, userID uint, reason string) {
+	event := &model.SecurityEvent{
+		EventType: model.SecurityEventLoginFailed,
+		UserID:    userID,
+		Email:     email,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.Path,
+		Reason:    reason,
+		RequestID: c.GetString("request_id"),
+	}
+	if err := h.securityEventService.RecordEvent(context.WithoutCancel(c.Request.Context()), event); err != nil {
+		_ = c.Error(err)
+	}
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go_web/internal/apperr"
+	"go_web/internal/config"
+	"go_web/internal/i18n"
+	"go_web/internal/service"
+	"go_web/internal/util"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// 批量操作模式
+const (
+	batchModeAtomic     = "atomic"      // 全部成功或全部回滚（默认）
+	batchModeBestEffort = "best_effort" // 逐个执行，互不影响
+)
+
+// BatchOperation 批量操作中的单个操作
+type BatchOperation struct {
+	Op      string          `json:"op" example:"create"`                 // 操作类型：create/update/delete
+	ID      uint            `json:"id,omitempty" example:"1"`            // 记录ID（update/delete 必填）
+	Version uint            `json:"version,omitempty" example:"1"`       // 版本号（update/delete 可选，作用同 If-Match，不一致时该操作返回 412）
+	Data    json.RawMessage `json:"data,omitempty" swaggertype:"object"` // 创建或更新的内容，格式与单个创建、更新接口的请求体相同
+}
+
+// BatchRequest 批量操作请求
+type BatchRequest struct {
+	Mode       string           `json:"mode" example:"atomic"`         // atomic（默认，在同一个事务中执行，任一失败时全部回滚）/best_effort（逐个执行，互不影响）
+	Operations []BatchOperation `json:"operations" binding:"required"` // 操作列表，数量上限为 server.batch_max_operations
+}
+
+// BatchItemResult 单个操作的结果
+type BatchItemResult struct {
+	Index  int         `json:"index" example:"0"`        // 操作在请求中的序号（从 0 开始）
+	Op     string      `json:"op" example:"create"`      // 操作类型
+	ID     uint        `json:"id,omitempty" example:"1"` // 记录ID（创建成功后为新记录的ID）
+	Status int         `json:"status" example:"201"`     // 与单个操作接口一致的 HTTP 状态码，424 表示因其他操作失败而回滚或未执行
+	Data   interface{} `json:"data,omitempty"`           // 创建或更新后的记录
+	Error  string      `json:"error,omitempty"`          // 失败原因
+	Code   string      `json:"error_code,omitempty"`     // 失败时的错误码，与单个操作接口一致，如 USER_EMAIL_TAKEN、BATCH_ABORTED
+}
+
+// BatchResponse 批量操作响应
+type BatchResponse struct {
+	Mode      string            `json:"mode" example:"atomic"` // 执行模式
+	Succeeded int               `json:"succeeded" example:"2"` // 成功的操作数
+	Failed    int               `json:"failed" example:"0"`    // 失败的操作数
+	Results   []BatchItemResult `json:"results"`               // 每个操作的结果，顺序与请求一致
+}
+
+// batchProcessor 批量接口中与资源相关的部分
+type batchProcessor struct {
+	// decode 校验并保存一个操作（ID 和操作类型已校验），返回错误时该操作不执行并返回 400
+	decode func(op BatchOperation) error
+	// execute 按保存的顺序执行全部校验通过的操作
+	execute func(atomic bool) []service.BatchResult
+}
+
+// handleBatch 批量接口的通用流程：解析请求、逐个校验操作、执行并汇总每个操作的结果
+// atomic 模式下有操作校验失败时不执行任何操作；有操作执行失败时全部回滚，响应状态码为失败操作的状态码
+func handleBatch(c *gin.Context, configManager *config.Manager, p batchProcessor) {
+	var req BatchRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = batchModeAtomic
+	}
+	if req.Mode != batchModeAtomic && req.Mode != batchModeBestEffort {
+		util.Fail(c, apperr.ErrBadRequest.WithMessage(i18n.MsgBatchModeUnknown, req.Mode))
+		return
+	}
+	lang := util.Lang(c)
+	maxOperations := configManager.Current().Server.BatchMaxOperations
+	if len(req.Operations) == 0 || len(req.Operations) > maxOperations {
+		util.Fail(c, apperr.ErrBadRequest.WithMessage(i18n.MsgBatchCount, maxOperations, len(req.Operations)))
+		return
+	}
+	atomic := req.Mode == batchModeAtomic
+
+	results := make([]BatchItemResult, len(req.Operations))
+	var valid []int
+	for i, op := range req.Operations {
+		results[i] = BatchItemResult{Index: i, Op: op.Op, ID: op.ID}
+		if err := validateBatchOperation(op, p.decode); err != nil {
+			results[i].setError(lang, requestError(err))
+			continue
+		}
+		valid = append(valid, i)
+	}
+
+	if len(valid) == len(results) || !atomic {
+		for j, result := range p.execute(atomic) {
+			item := &results[valid[j]]
+			switch {
+			case result.Err != nil:
+				item.setError(lang, result.Err)
+			case item.Op == service.BatchCreate:
+				item.Status = http.StatusCreated
+			default:
+				item.Status = http.StatusOK
+			}
+			if result.ID != 0 {
+				item.ID = result.ID
+			}
+			item.Data = result.Data
+		}
+	} else {
+		for _, i := range valid {
+			results[i].setError(lang, apperr.ErrBatchAborted)
+		}
+	}
+
+	resp := BatchResponse{Mode: req.Mode, Results: results}
+	var failure *apperr.Error
+	for _, item := range results {
+		if item.Error == "" {
+			resp.Succeeded++
+			continue
+		}
+		resp.Failed++
+		if failure == nil && item.Code != apperr.ErrBatchAborted.Code {
+			failure = apperr.New(item.Status, item.Code).WithMessage(i18n.MsgBatchRolledBack)
+		}
+	}
+
+	switch {
+	case resp.Failed == 0:
+		util.SuccessWithMessage(c, i18n.M(i18n.MsgBatchSucceeded), resp)
+	case atomic:
+		util.FailWithData(c, failure, resp)
+	default:
+		util.SuccessWithMessage(c, i18n.M(i18n.MsgBatchPartiallyFailed), resp)
+	}
+}
+
+// validateBatchOperation 校验操作类型和记录ID，再交给 decode 校验操作内容
+func validateBatchOperation(op BatchOperation, decode func(op BatchOperation) error) error {
+	switch op.Op {
+	case service.BatchCreate:
+	case service.BatchUpdate, service.BatchDelete:
+		if op.ID == 0 {
+			return apperr.ErrBadRequest.WithMessage(i18n.MsgBatchIDRequired, op.Op)
+		}
+	default:
+		return apperr.ErrBadRequest.WithMessage(i18n.MsgBatchOpUnknown, op.Op)
+	}
+	return decode(op)
+}
+
+// bindBatchData 解析并校验操作内容，校验规则与单个接口的请求体相同
+func bindBatchData(data json.RawMessage, obj interface{}) error {
+	if len(data) == 0 || string(data) == "null" {
+		data = json.RawMessage("{}")
+	}
+	if err := json.Unmarshal(data, obj); err != nil {
+		return requestError(err)
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// setError 记录失败操作的状态码、错误码和按 lang 翻译的错误信息，与单个操作接口一致
+func (r *BatchItemResult) setError(lang string, err error) {
+	e := apperr.From(err)
+	r.Status, r.Code, r.Error = e.Status, e.Code, e.Localize(lang)
+}
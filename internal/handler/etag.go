@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"go_web/internal/apperr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setETag 将记录的版本号写入 ETag 响应头，客户端更新或删除时通过 If-Match 带回
+func setETag(c *gin.Context, version uint) {
+	c.Header("ETag", strconv.Quote(strconv.FormatUint(uint64(version), 10)))
+}
+
+// ifMatchVersion 解析 If-Match 请求头中的版本号，未提供或为 * 时返回 0（不校验版本号）
+func ifMatchVersion(c *gin.Context) (uint, error) {
+	value := strings.TrimSpace(c.GetHeader("If-Match"))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+
+	// If-Match 使用强比较，弱 ETag（W/ 前缀）视为无效
+	tag, err := strconv.Unquote(value)
+	if err != nil {
+		return 0, apperr.ErrInvalidIfMatch
+	}
+	version, err := strconv.ParseUint(tag, 10, 32)
+	if err != nil || version == 0 {
+		return 0, apperr.ErrInvalidIfMatch
+	}
+	return uint(version), nil
+}
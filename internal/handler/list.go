@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"go_web/internal/query"
+	"go_web/internal/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseListQuery 解析列表接口的过滤、排序、搜索、分页参数以及返回的字段和关联
+func parseListQuery(c *gin.Context) (*query.Spec, query.Page, error) {
+	values := c.Request.URL.Query()
+	page, err := query.ParsePage(values)
+	if err != nil {
+		return nil, page, err
+	}
+	spec, err := query.Parse(values)
+	return spec, page, err
+}
+
+// render 按 fields 和 include 裁剪详情或列表的响应数据，失败时写入错误响应并返回 false
+func render(c *gin.Context, proj *query.Projection, data interface{}) (interface{}, bool) {
+	result, err := proj.Render(data)
+	if err != nil {
+		util.Fail(c, err)
+		return nil, false
+	}
+	return result, true
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+
+	"go_web/internal/apperr"
+	"go_web/internal/i18n"
+	"go_web/internal/util"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// mimeMergePatch RFC 7396 JSON Merge Patch 的媒体类型
+const mimeMergePatch = "application/merge-patch+json"
+
+// mergePatch RFC 7396 JSON Merge Patch 文档，按成员保存原始 JSON 以区分三种情况：
+// 成员不存在表示不修改，null 表示清空（只允许可清空的字段），其他值（包括空字符串）表示修改为该值
+type mergePatch map[string]json.RawMessage
+
+// bindMergePatch 解析 PATCH 请求体，Content-Type 应为 application/merge-patch+json 或 application/json，请求体必须是 JSON 对象
+func bindMergePatch(c *gin.Context) (mergePatch, bool) {
+	if contentType := c.ContentType(); contentType != mimeMergePatch && contentType != binding.MIMEJSON {
+		util.Fail(c, apperr.ErrUnsupportedMediaType.WithMessage(i18n.MsgPatchContentType))
+		return nil, false
+	}
+
+	var patch mergePatch
+	err := json.NewDecoder(c.Request.Body).Decode(&patch)
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) || (err == nil && patch == nil) {
+		// 数组、字符串或 null：资源只能按字段合并，不支持整体替换
+		util.Fail(c, apperr.ErrBadRequest.WithMessage(i18n.MsgBodyNotObject))
+		return nil, false
+	}
+	if err != nil {
+		util.Fail(c, requestError(err))
+		return nil, false
+	}
+	return patch, true
+}
+
+// apply 将文档合并到 doc（指向结构体的指针，成员按字段的 json 标签匹配），再按 binding 标签校验合并后的结果
+// nullable 中的字段为 null 时清空为零值；其他字段为 null、类型不匹配或 doc 中没有对应字段时返回 VALIDATION_FAILED
+func (p mergePatch) apply(doc interface{}, nullable ...string) error {
+	value := reflect.ValueOf(doc).Elem()
+	fields := make(map[string]reflect.Value, value.NumField())
+	for i := 0; i < value.NumField(); i++ {
+		name := strings.Split(value.Type().Field(i).Tag.Get("json"), ",")[0]
+		fields[name] = value.Field(i)
+	}
+
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var fieldErrs []apperr.FieldError
+	for _, name := range names {
+		field, ok := fields[name]
+		if !ok {
+			fieldErrs = append(fieldErrs, apperr.NewFieldError(name, "read_only", i18n.MsgRuleReadOnly))
+			continue
+		}
+
+		raw := bytes.TrimSpace(p[name])
+		if string(raw) == "null" {
+			if !containsString(nullable, name) {
+				fieldErrs = append(fieldErrs, apperr.NewFieldError(name, "not_null", i18n.MsgRuleNotNull))
+				continue
+			}
+			field.Set(reflect.Zero(field.Type()))
+			continue
+		}
+
+		if err := json.Unmarshal(raw, field.Addr().Interface()); err != nil {
+			e := apperr.From(err)
+			if len(e.Fields) == 0 {
+				return requestError(err)
+			}
+			fieldErr := e.Fields[0]
+			fieldErr.Field = name
+			fieldErrs = append(fieldErrs, fieldErr)
+		}
+	}
+	if len(fieldErrs) > 0 {
+		return apperr.ErrValidation.WithFields(fieldErrs)
+	}
+
+	return binding.Validator.ValidateStruct(doc)
+}
+
+// containsString 判断 values 中是否包含 s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -1,9 +1,12 @@
 package handler
 
 import (
-	"strconv"
 	"time"
 
+	"go_web/internal/config"
+	"go_web/internal/i18n"
+	"go_web/internal/model"
+	"go_web/internal/query"
 	"go_web/internal/service"
 	"go_web/internal/util"
 
@@ -12,10 +15,11 @@ import (
 
 type PermissionHandler struct {
 	permissionService service.PermissionService
+	configManager     *config.Manager
 }
 
-func NewPermissionHandler(permissionService service.PermissionService) *PermissionHandler {
-	return &PermissionHandler{permissionService: permissionService}
+func NewPermissionHandler(permissionService service.PermissionService, configManager *config.Manager) *PermissionHandler {
+	return &PermissionHandler{permissionService: permissionService, configManager: configManager}
 }
 
 type CreatePermissionRequest struct {
@@ -26,6 +30,13 @@ type CreatePermissionRequest struct {
 	Action      string `json:"action" binding:"required" example:"create"`     // 操作类型
 }
 
+// permissionPatch PATCH 合并后的权限信息，按 binding 标签校验合并结果
+type permissionPatch struct {
+	DisplayName string `json:"display_name" binding:"required"`
+	Description string `json:"description"`
+	Status      int    `json:"status" binding:"oneof=0 1"`
+}
+
 type UpdatePermissionRequest struct {
 	DisplayName *string `json:"display_name" example:"创建用户"`  // 显示名称（可选）
 	Description *string `json:"description" example:"允许创建用户"` // 权限描述（可选）
@@ -43,6 +54,7 @@ type PermissionResponse struct {
 	Resource    string    `json:"resource" example:"user"`                   // 资源类型
 	Action      string    `json:"action" example:"create"`                   // 操作类型
 	Status      int       `json:"status" example:"1"`                        // 状态：1-启用，0-禁用
+	Version     uint      `json:"version" example:"1"`                       // 版本号，每次更新加一
 }
 
 // CreatePermission 创建权限
@@ -60,18 +72,17 @@ type PermissionResponse struct {
 // @Router       /permissions [post]
 func (h *PermissionHandler) CreatePermission(c *gin.Context) {
 	var req CreatePermissionRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		util.BadRequestWithError(c, "请求参数错误", err)
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	permission, err := h.permissionService.CreatePermission(req.Name, req.DisplayName, req.Description, req.Resource, req.Action)
+	permission, err := h.permissionService.CreatePermission(c.Request.Context(), req.Name, req.DisplayName, req.Description, req.Resource, req.Action)
 	if err != nil {
-		util.InternalServerErrorWithError(c, "创建权限失败", err)
+		util.Fail(c, err)
 		return
 	}
 
-	util.CreatedWithMessage(c, "权限创建成功", permission)
+	util.CreatedWithMessage(c, i18n.M(i18n.MsgPermissionCreated), permission)
 }
 
 // GetPermission 获取权限详情
@@ -81,27 +92,39 @@ func (h *PermissionHandler) CreatePermission(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        id            path      int     true  "权限ID"
+// @Param        fields        query     string  false  "只返回这些字段，逗号分隔，id 总是返回；可选: id、name、display_name、description、resource、action、status、version、created_at、updated_at、deleted_at"  example(id,name)
+// @Param        include       query     string  false  "展开的关联，逗号分隔，为空表示不展开；可选: roles。未指定 fields 和 include 时展开 roles"
 // @Param        Authorization header    string  true  "Bearer {token}"  default(Bearer )
 // @Success      200           {object}  util.Response{data=PermissionResponse}
+// @Header       200           {string}  ETag  "权限的版本号，更新或删除时通过 If-Match 带回"
 // @Failure      400           {object}  util.Response
 // @Failure      401           {object}  util.Response
 // @Failure      404           {object}  util.Response
 // @Router       /permissions/{id} [get]
 func (h *PermissionHandler) GetPermission(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
+	id, ok := parseID(c, i18n.MsgResourcePermission)
+	if !ok {
+		return
+	}
+
+	proj, err := query.ParseProjection(c.Request.URL.Query())
 	if err != nil {
-		util.BadRequest(c, "无效的权限ID")
+		util.Fail(c, err)
 		return
 	}
 
-	permission, err := h.permissionService.GetPermissionByID(uint(id))
+	permission, err := h.permissionService.GetPermissionProjected(c.Request.Context(), uint(id), proj)
 	if err != nil {
-		util.NotFound(c, "权限不存在")
+		util.Fail(c, err)
 		return
 	}
 
-	util.Success(c, permission)
+	data, ok := render(c, proj, permission)
+	if !ok {
+		return
+	}
+	setETag(c, permission.Version)
+	util.Success(c, data)
 }
 
 // UpdatePermission 更新权限
@@ -113,22 +136,30 @@ func (h *PermissionHandler) GetPermission(c *gin.Context) {
 // @Param        id            path      int                    true  "权限ID"
 // @Param        Authorization header    string                 true  "Bearer {token}"  default(Bearer )
 // @Param        permission    body      UpdatePermissionRequest true  "权限信息"
+// @Param        If-Match      header    string             false "GET 返回的 ETag，版本不一致时返回 412"
 // @Success      200           {object}  util.Response{data=PermissionResponse}
+// @Header       200           {string}  ETag  "更新后的版本号"
 // @Failure      400           {object}  util.Response
 // @Failure      401           {object}  util.Response
+// @Failure      404           {object}  util.Response
+// @Failure      409           {object}  util.Response
+// @Failure      412           {object}  util.Response
 // @Failure      500           {object}  util.Response
 // @Router       /permissions/{id} [put]
 func (h *PermissionHandler) UpdatePermission(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
-	if err != nil {
-		util.BadRequest(c, "无效的权限ID")
+	id, ok := parseID(c, i18n.MsgResourcePermission)
+	if !ok {
 		return
 	}
 
 	var req UpdatePermissionRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		util.BadRequestWithError(c, "请求参数错误", err)
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		util.Fail(c, err)
 		return
 	}
 
@@ -147,67 +178,272 @@ func (h *PermissionHandler) UpdatePermission(c *gin.Context) {
 		status = *req.Status
 	}
 
-	permission, err := h.permissionService.UpdatePermission(uint(id), displayName, description, status)
+	permission, err := h.permissionService.UpdatePermission(c.Request.Context(), uint(id), version, displayName, description, status)
+	if err != nil {
+		util.Fail(c, err)
+		return
+	}
+
+	setETag(c, permission.Version)
+	util.SuccessWithMessage(c, i18n.M(i18n.MsgPermissionUpdated), permission)
+}
+
+// PatchPermission 部分更新权限
+// @Summary      部分更新权限（JSON Merge Patch）
+// @Description  按 RFC 7396 合并修改权限：请求中没有的字段不修改，description 为 null 或空字符串时清空描述，display_name、status 不能为 null。
+// @Description  合并后的结果需通过校验：display_name 不能为空，status 为 0 或 1。只写入有变化的字段，审计日志只记录这些字段；没有变化时不写入，版本号不变
+// @Tags         权限管理
+// @Accept       application/merge-patch+json,json
+// @Produce      json
+// @Param        id            path      int                      true  "权限ID"
+// @Param        Authorization header    string                   true  "Bearer {token}"  default(Bearer )
+// @Param        permission    body      UpdatePermissionRequest  true  "要修改的字段"
+// @Param        If-Match      header    string                   false "GET 返回的 ETag，版本不一致时返回 412"
+// @Success      200           {object}  util.Response{data=PermissionResponse}
+// @Header       200           {string}  ETag  "更新后的版本号"
+// @Failure      400           {object}  util.Response
+// @Failure      401           {object}  util.Response
+// @Failure      404           {object}  util.Response
+// @Failure      409           {object}  util.Response
+// @Failure      412           {object}  util.Response
+// @Failure      415           {object}  util.Response
+// @Failure      500           {object}  util.Response
+// @Router       /permissions/{id} [patch]
+func (h *PermissionHandler) PatchPermission(c *gin.Context) {
+	id, ok := parseID(c, i18n.MsgResourcePermission)
+	if !ok {
+		return
+	}
+
+	patch, ok := bindMergePatch(c)
+	if !ok {
+		return
+	}
+
+	version, err := ifMatchVersion(c)
 	if err != nil {
-		util.InternalServerErrorWithError(c, "更新权限失败", err)
+		util.Fail(c, err)
 		return
 	}
 
-	util.SuccessWithMessage(c, "权限更新成功", permission)
+	permission, err := h.permissionService.PatchPermission(c.Request.Context(), id, version, func(permission *model.Permission) error {
+		doc := permissionPatch{DisplayName: permission.DisplayName, Description: permission.Description, Status: permission.Status}
+		if err := patch.apply(&doc, "description"); err != nil {
+			return err
+		}
+		permission.DisplayName, permission.Description, permission.Status = doc.DisplayName, doc.Description, doc.Status
+		return nil
+	})
+	if err != nil {
+		util.Fail(c, err)
+		return
+	}
+
+	setETag(c, permission.Version)
+	util.SuccessWithMessage(c, i18n.M(i18n.MsgPermissionUpdated), permission)
 }
 
 // DeletePermission 删除权限
 // @Summary      删除权限
-// @Description  根据权限ID删除权限
+// @Description  根据权限ID删除权限（软删除）。仍有角色使用该权限时返回 409 和这些角色，force=true 时同时从这些角色中移除该权限
 // @Tags         权限管理
 // @Accept       json
 // @Produce      json
 // @Param        id            path      int     true  "权限ID"
 // @Param        Authorization header    string  true  "Bearer {token}"  default(Bearer )
+// @Param        If-Match      header    string  false "GET 返回的 ETag，版本不一致时返回 412"
+// @Param        force         query     bool    false "同时从角色中移除该权限"  default(false)
 // @Success      200           {object}  util.Response
 // @Failure      400           {object}  util.Response
 // @Failure      401           {object}  util.Response
+// @Failure      404           {object}  util.Response
+// @Failure      409           {object}  util.Response{data=service.Dependents}
+// @Failure      412           {object}  util.Response
 // @Failure      500           {object}  util.Response
 // @Router       /permissions/{id} [delete]
 func (h *PermissionHandler) DeletePermission(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
+	id, ok := parseID(c, i18n.MsgResourcePermission)
+	if !ok {
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		util.Fail(c, err)
+		return
+	}
+
+	force := c.Query("force") == "true"
+	err = h.permissionService.DeletePermission(c.Request.Context(), uint(id), version, force)
+	if err != nil {
+		util.Fail(c, err)
+		return
+	}
+
+	util.SuccessWithMessage(c, i18n.M(i18n.MsgPermissionDeleted), nil)
+}
+
+// RestorePermission 恢复权限
+// @Summary      恢复已删除的权限
+// @Description  恢复已软删除的权限，名称已被其他权限使用时返回 409
+// @Tags         权限管理
+// @Accept       json
+// @Produce      json
+// @Param        id            path      int     true  "权限ID"
+// @Param        Authorization header    string  true  "Bearer {token}"  default(Bearer )
+// @Param        If-Match      header    string  false "GET 返回的 ETag，版本不一致时返回 412"
+// @Success      200           {object}  util.Response{data=PermissionResponse}
+// @Failure      400           {object}  util.Response
+// @Failure      401           {object}  util.Response
+// @Failure      404           {object}  util.Response
+// @Failure      409           {object}  util.Response
+// @Failure      412           {object}  util.Response
+// @Failure      500           {object}  util.Response
+// @Router       /permissions/{id}/restore [post]
+func (h *PermissionHandler) RestorePermission(c *gin.Context) {
+	id, ok := parseID(c, i18n.MsgResourcePermission)
+	if !ok {
+		return
+	}
+
+	version, err := ifMatchVersion(c)
 	if err != nil {
-		util.BadRequest(c, "无效的权限ID")
+		util.Fail(c, err)
 		return
 	}
 
-	err = h.permissionService.DeletePermission(uint(id))
+	permission, err := h.permissionService.RestorePermission(c.Request.Context(), uint(id), version)
 	if err != nil {
-		util.InternalServerErrorWithError(c, "删除权限失败", err)
+		util.Fail(c, err)
+		return
+	}
+
+	setETag(c, permission.Version)
+	util.SuccessWithMessage(c, i18n.M(i18n.MsgPermissionRestored), permission)
+}
+
+// PurgePermission 彻底删除权限
+// @Summary      彻底删除权限
+// @Description  从数据库中删除已软删除的权限及其关联，不可恢复；未删除的权限需要先删除，否则返回 409
+// @Tags         权限管理
+// @Accept       json
+// @Produce      json
+// @Param        id            path      int     true  "权限ID"
+// @Param        Authorization header    string  true  "Bearer {token}"  default(Bearer )
+// @Success      200           {object}  util.Response
+// @Failure      400           {object}  util.Response
+// @Failure      401           {object}  util.Response
+// @Failure      404           {object}  util.Response
+// @Failure      409           {object}  util.Response
+// @Failure      500           {object}  util.Response
+// @Router       /permissions/{id}/purge [delete]
+func (h *PermissionHandler) PurgePermission(c *gin.Context) {
+	id, ok := parseID(c, i18n.MsgResourcePermission)
+	if !ok {
+		return
+	}
+
+	if err := h.permissionService.PurgePermission(c.Request.Context(), uint(id)); err != nil {
+		util.Fail(c, err)
 		return
 	}
 
-	util.SuccessWithMessage(c, "权限删除成功", nil)
+	util.SuccessWithMessage(c, i18n.M(i18n.MsgPermissionPurged), nil)
 }
 
 // ListPermissions 获取权限列表
 // @Summary      获取权限列表
-// @Description  分页获取权限列表
+// @Description  分页获取权限列表，支持过滤、排序和搜索。过滤参数格式为 filter[字段]=值 或 filter[字段][操作符]=值，操作符为 eq、ne、gt、gte、lt、lte、like、in（in 的多个值用逗号分隔）；可过滤和排序的字段: id、name、display_name、resource、action、status、created_at、updated_at；deleted_at 只能过滤，需配合 include_deleted 使用
 // @Tags         权限管理
 // @Accept       json
 // @Produce      json
 // @Param        page          query     int     false  "页码"      default(1)
-// @Param        page_size     query     int     false  "每页数量"   default(10)
+// @Param        page_size     query     int     false  "每页数量（最大 100）"   default(10)
+// @Param        cursor        query     string  false  "游标分页：为空表示第一页，之后使用响应中的 next_cursor/prev_cursor，不能与 page 同时使用"
+// @Param        sort          query     string  false  "排序字段，多个用逗号分隔，前缀 - 表示降序"  example(-created_at,name)
+// @Param        q             query     string  false  "关键字，模糊匹配 name、display_name、description"
+// @Param        include_deleted query   string  false  "包含已软删除的记录：true 包含，only 只返回已删除的记录"  Enums(true, false, only)
+// @Param        fields        query     string  false  "只返回这些字段，逗号分隔，id 总是返回；可选: id、name、display_name、description、resource、action、status、version、created_at、updated_at、deleted_at"  example(id,name)
+// @Param        include       query     string  false  "展开的关联，逗号分隔，为空表示不展开；可选: roles。未指定 fields 和 include 时展开 roles"
 // @Param        Authorization header    string  false  "Bearer {token}"  default(Bearer )
-// @Success      200           {object}  util.Response{data=[]PermissionResponse}
+// @Success      200           {object}  util.Response{data=util.PageData{list=[]PermissionResponse}}
 // @Failure      400           {object}  util.Response
 // @Failure      401           {object}  util.Response
 // @Router       /permissions [get]
 func (h *PermissionHandler) ListPermissions(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	spec, page, err := parseListQuery(c)
+	if err != nil {
+		util.Fail(c, err)
+		return
+	}
 
-	permissions, total, err := h.permissionService.ListPermissions(page, pageSize)
+	permissions, info, err := h.permissionService.ListPermissions(c.Request.Context(), spec, page)
 	if err != nil {
-		util.InternalServerErrorWithError(c, "获取权限列表失败", err)
+		util.Fail(c, err)
 		return
 	}
 
-	util.SuccessWithPagination(c, permissions, total, page, pageSize)
+	data, ok := render(c, spec.Projection, permissions)
+	if !ok {
+		return
+	}
+	util.SuccessWithPagination(c, data, info)
+}
+
+// BatchPermissions 批量操作权限
+// @Summary      批量创建、更新、删除权限
+// @Description  一次请求执行多个创建、更新、删除操作，每个操作的 data 与单个创建、更新接口的请求体相同，每个操作单独记录审计日志。
+// @Description  需要请求中出现的各操作类型对应的权限（permission:create/permission:update/permission:delete）。
+// @Description  atomic 模式（默认）在同一个事务中执行，任一操作失败时全部回滚，响应状态码为失败操作的状态码，其他操作的 status 为 424；
+// @Description  best_effort 模式逐个执行，互不影响，始终返回 200，通过每个操作的 status 和 error 判断结果
+// @Tags         权限管理
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header    string        true  "Bearer {token}"  default(Bearer )
+// @Param        batch         body      BatchRequest  true  "批量操作"
+// @Success      200           {object}  util.Response{data=BatchResponse}
+// @Failure      400           {object}  util.Response{data=BatchResponse}
+// @Failure      401           {object}  util.Response
+// @Failure      403           {object}  util.Response
+// @Failure      404           {object}  util.Response{data=BatchResponse}
+// @Failure      409           {object}  util.Response{data=BatchResponse}
+// @Failure      412           {object}  util.Response{data=BatchResponse}
+// @Failure      413           {object}  util.Response
+// @Failure      500           {object}  util.Response{data=BatchResponse}
+// @Router       /permissions:batch [post]
+func (h *PermissionHandler) BatchPermissions(c *gin.Context) {
+	var ops []service.PermissionBatchOp
+	handleBatch(c, h.configManager, batchProcessor{
+		decode: func(op BatchOperation) error {
+			item := service.PermissionBatchOp{Op: op.Op, ID: op.ID, Version: op.Version, Status: -1}
+			switch op.Op {
+			case service.BatchCreate:
+				var req CreatePermissionRequest
+				if err := bindBatchData(op.Data, &req); err != nil {
+					return err
+				}
+				item.Name, item.DisplayName, item.Description = req.Name, req.DisplayName, req.Description
+				item.Resource, item.Action = req.Resource, req.Action
+			case service.BatchUpdate:
+				var req UpdatePermissionRequest
+				if err := bindBatchData(op.Data, &req); err != nil {
+					return err
+				}
+				if req.DisplayName != nil {
+					item.DisplayName = *req.DisplayName
+				}
+				if req.Description != nil {
+					item.Description = *req.Description
+				}
+				if req.Status != nil {
+					item.Status = *req.Status
+				}
+			}
+			ops = append(ops, item)
+			return nil
+		},
+		execute: func(atomic bool) []service.BatchResult {
+			return h.permissionService.BatchPermissions(c.Request.Context(), ops, atomic)
+		},
+	})
 }
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"go_web/internal/apperr"
+	"go_web/internal/i18n"
+	"go_web/internal/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bindJSON 解析并校验 JSON 请求体，失败时返回错误响应（校验错误逐字段列出）
+func bindJSON(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		util.Fail(c, requestError(err))
+		return false
+	}
+	return true
+}
+
+// requestError 请求参数解析错误，无法识别的错误按 BAD_REQUEST 处理而不是服务器错误
+func requestError(err error) error {
+	if e := apperr.From(err); !errors.Is(e, apperr.ErrInternal) {
+		return e
+	}
+	return apperr.ErrBadRequest.Wrap(err)
+}
+
+// parseID 解析路径参数中的记录ID，失败时返回 INVALID_ID，name 为资源名称的消息码，如 i18n.MsgResourceUser
+func parseID(c *gin.Context, resource string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		util.Fail(c, apperr.ErrInvalidID.WithMessage(i18n.MsgInvalidIDOf, i18n.M(resource)))
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -1,9 +1,12 @@
 package handler
 
 import (
-	"strconv"
 	"time"
 
+	"go_web/internal/config"
+	"go_web/internal/i18n"
+	"go_web/internal/model"
+	"go_web/internal/query"
 	"go_web/internal/service"
 	"go_web/internal/util"
 
@@ -11,11 +14,12 @@ import (
 )
 
 type RoleHandler struct {
-	roleService service.RoleService
+	roleService   service.RoleService
+	configManager *config.Manager
 }
 
-func NewRoleHandler(roleService service.RoleService) *RoleHandler {
-	return &RoleHandler{roleService: roleService}
+func NewRoleHandler(roleService service.RoleService, configManager *config.Manager) *RoleHandler {
+	return &RoleHandler{roleService: roleService, configManager: configManager}
 }
 
 type CreateRoleRequest struct {
@@ -24,6 +28,13 @@ type CreateRoleRequest struct {
 	Description string `json:"description" example:"系统管理员，拥有所有权限"`            // 角色描述
 }
 
+// rolePatch PATCH 合并后的角色信息，按 binding 标签校验合并结果
+type rolePatch struct {
+	DisplayName string `json:"display_name" binding:"required"`
+	Description string `json:"description"`
+	Status      int    `json:"status" binding:"oneof=0 1"`
+}
+
 type UpdateRoleRequest struct {
 	DisplayName *string `json:"display_name" example:"管理员"`  // 显示名称（可选）
 	Description *string `json:"description" example:"系统管理员"` // 角色描述（可选）
@@ -47,6 +58,7 @@ type RoleResponse struct {
 	DisplayName string    `json:"display_name" example:"管理员"`                // 显示名称
 	Description string    `json:"description" example:"系统管理员"`               // 角色描述
 	Status      int       `json:"status" example:"1"`                        // 状态：1-启用，0-禁用
+	Version     uint      `json:"version" example:"1"`                       // 版本号，每次更新加一
 }
 
 // CreateRole 创建角色
@@ -64,18 +76,17 @@ type RoleResponse struct {
 // @Router       /roles [post]
 func (h *RoleHandler) CreateRole(c *gin.Context) {
 	var req CreateRoleRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		util.BadRequestWithError(c, "请求参数错误", err)
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	role, err := h.roleService.CreateRole(req.Name, req.DisplayName, req.Description)
+	role, err := h.roleService.CreateRole(c.Request.Context(), req.Name, req.DisplayName, req.Description)
 	if err != nil {
-		util.InternalServerErrorWithError(c, "创建角色失败", err)
+		util.Fail(c, err)
 		return
 	}
 
-	util.CreatedWithMessage(c, "角色创建成功", role)
+	util.CreatedWithMessage(c, i18n.M(i18n.MsgRoleCreated), role)
 }
 
 // GetRole 获取角色详情
@@ -85,27 +96,39 @@ func (h *RoleHandler) CreateRole(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        id            path      int     true  "角色ID"
+// @Param        fields        query     string  false  "只返回这些字段，逗号分隔，id 总是返回；可选: id、name、display_name、description、status、version、created_at、updated_at、deleted_at"  example(id,name)
+// @Param        include       query     string  false  "展开的关联，逗号分隔，为空表示不展开；可选: permissions、users。未指定 fields 和 include 时展开 permissions、users"
 // @Param        Authorization header    string  true  "Bearer {token}"  default(Bearer )
 // @Success      200           {object}  util.Response{data=RoleResponse}
+// @Header       200           {string}  ETag  "角色的版本号，更新或删除时通过 If-Match 带回"
 // @Failure      400           {object}  util.Response
 // @Failure      401           {object}  util.Response
 // @Failure      404           {object}  util.Response
 // @Router       /roles/{id} [get]
 func (h *RoleHandler) GetRole(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
+	id, ok := parseID(c, i18n.MsgResourceRole)
+	if !ok {
+		return
+	}
+
+	proj, err := query.ParseProjection(c.Request.URL.Query())
 	if err != nil {
-		util.BadRequest(c, "无效的角色ID")
+		util.Fail(c, err)
 		return
 	}
 
-	role, err := h.roleService.GetRoleByID(uint(id))
+	role, err := h.roleService.GetRoleProjected(c.Request.Context(), uint(id), proj)
 	if err != nil {
-		util.NotFound(c, "角色不存在")
+		util.Fail(c, err)
 		return
 	}
 
-	util.Success(c, role)
+	data, ok := render(c, proj, role)
+	if !ok {
+		return
+	}
+	setETag(c, role.Version)
+	util.Success(c, data)
 }
 
 // UpdateRole 更新角色
@@ -117,22 +140,30 @@ func (h *RoleHandler) GetRole(c *gin.Context) {
 // @Param        id            path      int               true  "角色ID"
 // @Param        Authorization header    string             true  "Bearer {token}"  default(Bearer )
 // @Param        role          body      UpdateRoleRequest  true  "角色信息"
+// @Param        If-Match      header    string             false "GET 返回的 ETag，版本不一致时返回 412"
 // @Success      200           {object}  util.Response{data=RoleResponse}
+// @Header       200           {string}  ETag  "更新后的版本号"
 // @Failure      400           {object}  util.Response
 // @Failure      401           {object}  util.Response
+// @Failure      404           {object}  util.Response
+// @Failure      409           {object}  util.Response
+// @Failure      412           {object}  util.Response
 // @Failure      500           {object}  util.Response
 // @Router       /roles/{id} [put]
 func (h *RoleHandler) UpdateRole(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
-	if err != nil {
-		util.BadRequest(c, "无效的角色ID")
+	id, ok := parseID(c, i18n.MsgResourceRole)
+	if !ok {
 		return
 	}
 
 	var req UpdateRoleRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		util.BadRequestWithError(c, "请求参数错误", err)
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		util.Fail(c, err)
 		return
 	}
 
@@ -151,69 +182,216 @@ func (h *RoleHandler) UpdateRole(c *gin.Context) {
 		status = *req.Status
 	}
 
-	role, err := h.roleService.UpdateRole(uint(id), displayName, description, status)
+	role, err := h.roleService.UpdateRole(c.Request.Context(), uint(id), version, displayName, description, status)
+	if err != nil {
+		util.Fail(c, err)
+		return
+	}
+
+	setETag(c, role.Version)
+	util.SuccessWithMessage(c, i18n.M(i18n.MsgRoleUpdated), role)
+}
+
+// PatchRole 部分更新角色
+// @Summary      部分更新角色（JSON Merge Patch）
+// @Description  按 RFC 7396 合并修改角色：请求中没有的字段不修改，description 为 null 或空字符串时清空描述，display_name、status 不能为 null。
+// @Description  合并后的结果需通过校验：display_name 不能为空，status 为 0 或 1。只写入有变化的字段，审计日志只记录这些字段；没有变化时不写入，版本号不变
+// @Tags         角色管理
+// @Accept       application/merge-patch+json,json
+// @Produce      json
+// @Param        id            path      int                true  "角色ID"
+// @Param        Authorization header    string             true  "Bearer {token}"  default(Bearer )
+// @Param        role          body      UpdateRoleRequest  true  "要修改的字段"
+// @Param        If-Match      header    string             false "GET 返回的 ETag，版本不一致时返回 412"
+// @Success      200           {object}  util.Response{data=RoleResponse}
+// @Header       200           {string}  ETag  "更新后的版本号"
+// @Failure      400           {object}  util.Response
+// @Failure      401           {object}  util.Response
+// @Failure      404           {object}  util.Response
+// @Failure      409           {object}  util.Response
+// @Failure      412           {object}  util.Response
+// @Failure      415           {object}  util.Response
+// @Failure      500           {object}  util.Response
+// @Router       /roles/{id} [patch]
+func (h *RoleHandler) PatchRole(c *gin.Context) {
+	id, ok := parseID(c, i18n.MsgResourceRole)
+	if !ok {
+		return
+	}
+
+	patch, ok := bindMergePatch(c)
+	if !ok {
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		util.Fail(c, err)
+		return
+	}
+
+	role, err := h.roleService.PatchRole(c.Request.Context(), id, version, func(role *model.Role) error {
+		doc := rolePatch{DisplayName: role.DisplayName, Description: role.Description, Status: role.Status}
+		if err := patch.apply(&doc, "description"); err != nil {
+			return err
+		}
+		role.DisplayName, role.Description, role.Status = doc.DisplayName, doc.Description, doc.Status
+		return nil
+	})
 	if err != nil {
-		util.InternalServerErrorWithError(c, "更新角色失败", err)
+		util.Fail(c, err)
 		return
 	}
 
-	util.SuccessWithMessage(c, "角色更新成功", role)
+	setETag(c, role.Version)
+	util.SuccessWithMessage(c, i18n.M(i18n.MsgRoleUpdated), role)
 }
 
 // DeleteRole 删除角色
 // @Summary      删除角色
-// @Description  根据角色ID删除角色
+// @Description  根据角色ID删除角色（软删除）。仍有用户拥有该角色时返回 409 和这些用户，force=true 时同时解除该角色与所有用户、权限的关联
 // @Tags         角色管理
 // @Accept       json
 // @Produce      json
 // @Param        id            path      int     true  "角色ID"
 // @Param        Authorization header    string  true  "Bearer {token}"  default(Bearer )
+// @Param        If-Match      header    string  false "GET 返回的 ETag，版本不一致时返回 412"
+// @Param        force         query     bool    false "同时解除该角色与所有用户、权限的关联"  default(false)
 // @Success      200           {object}  util.Response
 // @Failure      400           {object}  util.Response
 // @Failure      401           {object}  util.Response
+// @Failure      404           {object}  util.Response
+// @Failure      409           {object}  util.Response{data=service.Dependents}
+// @Failure      412           {object}  util.Response
 // @Failure      500           {object}  util.Response
 // @Router       /roles/{id} [delete]
 func (h *RoleHandler) DeleteRole(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
+	id, ok := parseID(c, i18n.MsgResourceRole)
+	if !ok {
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		util.Fail(c, err)
+		return
+	}
+
+	force := c.Query("force") == "true"
+	err = h.roleService.DeleteRole(c.Request.Context(), uint(id), version, force)
+	if err != nil {
+		util.Fail(c, err)
+		return
+	}
+
+	util.SuccessWithMessage(c, i18n.M(i18n.MsgRoleDeleted), nil)
+}
+
+// RestoreRole 恢复角色
+// @Summary      恢复已删除的角色
+// @Description  恢复已软删除的角色，名称已被其他角色使用时返回 409
+// @Tags         角色管理
+// @Accept       json
+// @Produce      json
+// @Param        id            path      int     true  "角色ID"
+// @Param        Authorization header    string  true  "Bearer {token}"  default(Bearer )
+// @Param        If-Match      header    string  false "GET 返回的 ETag，版本不一致时返回 412"
+// @Success      200           {object}  util.Response{data=RoleResponse}
+// @Failure      400           {object}  util.Response
+// @Failure      401           {object}  util.Response
+// @Failure      404           {object}  util.Response
+// @Failure      409           {object}  util.Response
+// @Failure      412           {object}  util.Response
+// @Failure      500           {object}  util.Response
+// @Router       /roles/{id}/restore [post]
+func (h *RoleHandler) RestoreRole(c *gin.Context) {
+	id, ok := parseID(c, i18n.MsgResourceRole)
+	if !ok {
+		return
+	}
+
+	version, err := ifMatchVersion(c)
 	if err != nil {
-		util.BadRequest(c, "无效的角色ID")
+		util.Fail(c, err)
 		return
 	}
 
-	err = h.roleService.DeleteRole(uint(id))
+	role, err := h.roleService.RestoreRole(c.Request.Context(), uint(id), version)
 	if err != nil {
-		util.InternalServerErrorWithError(c, "删除角色失败", err)
+		util.Fail(c, err)
+		return
+	}
+
+	setETag(c, role.Version)
+	util.SuccessWithMessage(c, i18n.M(i18n.MsgRoleRestored), role)
+}
+
+// PurgeRole 彻底删除角色
+// @Summary      彻底删除角色
+// @Description  从数据库中删除已软删除的角色及其关联，不可恢复；未删除的角色需要先删除，否则返回 409
+// @Tags         角色管理
+// @Accept       json
+// @Produce      json
+// @Param        id            path      int     true  "角色ID"
+// @Param        Authorization header    string  true  "Bearer {token}"  default(Bearer )
+// @Success      200           {object}  util.Response
+// @Failure      400           {object}  util.Response
+// @Failure      401           {object}  util.Response
+// @Failure      404           {object}  util.Response
+// @Failure      409           {object}  util.Response
+// @Failure      500           {object}  util.Response
+// @Router       /roles/{id}/purge [delete]
+func (h *RoleHandler) PurgeRole(c *gin.Context) {
+	id, ok := parseID(c, i18n.MsgResourceRole)
+	if !ok {
 		return
 	}
 
-	util.SuccessWithMessage(c, "角色删除成功", nil)
+	if err := h.roleService.PurgeRole(c.Request.Context(), uint(id)); err != nil {
+		util.Fail(c, err)
+		return
+	}
+
+	util.SuccessWithMessage(c, i18n.M(i18n.MsgRolePurged), nil)
 }
 
 // ListRoles 获取角色列表
 // @Summary      获取角色列表
-// @Description  分页获取角色列表
+// @Description  分页获取角色列表，支持过滤、排序和搜索。过滤参数格式为 filter[字段]=值 或 filter[字段][操作符]=值，操作符为 eq、ne、gt、gte、lt、lte、like、in（in 的多个值用逗号分隔）；可过滤和排序的字段: id、name、display_name、status、created_at、updated_at；deleted_at 只能过滤，需配合 include_deleted 使用
 // @Tags         角色管理
 // @Accept       json
 // @Produce      json
 // @Param        page          query     int     false  "页码"      default(1)
-// @Param        page_size     query     int     false  "每页数量"   default(10)
+// @Param        page_size     query     int     false  "每页数量（最大 100）"   default(10)
+// @Param        cursor        query     string  false  "游标分页：为空表示第一页，之后使用响应中的 next_cursor/prev_cursor，不能与 page 同时使用"
+// @Param        sort          query     string  false  "排序字段，多个用逗号分隔，前缀 - 表示降序"  example(-created_at,name)
+// @Param        q             query     string  false  "关键字，模糊匹配 name、display_name、description"
+// @Param        include_deleted query   string  false  "包含已软删除的记录：true 包含，only 只返回已删除的记录"  Enums(true, false, only)
+// @Param        fields        query     string  false  "只返回这些字段，逗号分隔，id 总是返回；可选: id、name、display_name、description、status、version、created_at、updated_at、deleted_at"  example(id,name)
+// @Param        include       query     string  false  "展开的关联，逗号分隔，为空表示不展开；可选: permissions、users。未指定 fields 和 include 时展开 permissions"
 // @Param        Authorization header    string  false  "Bearer {token}"  default(Bearer )
-// @Success      200           {object}  util.Response{data=[]RoleResponse}
+// @Success      200           {object}  util.Response{data=util.PageData{list=[]RoleResponse}}
 // @Failure      400           {object}  util.Response
 // @Failure      401           {object}  util.Response
 // @Router       /roles [get]
 func (h *RoleHandler) ListRoles(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	spec, page, err := parseListQuery(c)
+	if err != nil {
+		util.Fail(c, err)
+		return
+	}
 
-	roles, total, err := h.roleService.ListRoles(page, pageSize)
+	roles, info, err := h.roleService.ListRoles(c.Request.Context(), spec, page)
 	if err != nil {
-		util.InternalServerErrorWithError(c, "获取角色列表失败", err)
+		util.Fail(c, err)
 		return
 	}
 
-	util.SuccessWithPagination(c, roles, total, page, pageSize)
+	data, ok := render(c, spec.Projection, roles)
+	if !ok {
+		return
+	}
+	util.SuccessWithPagination(c, data, info)
 }
 
 // AssignPermissions 分配权限给角色
@@ -231,26 +409,23 @@ func (h *RoleHandler) ListRoles(c *gin.Context) {
 // @Failure      500           {object}  util.Response
 // @Router       /roles/{id}/permissions [post]
 func (h *RoleHandler) AssignPermissions(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
-	if err != nil {
-		util.BadRequest(c, "无效的角色ID")
+	id, ok := parseID(c, i18n.MsgResourceRole)
+	if !ok {
 		return
 	}
 
 	var req AssignPermissionsRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		util.BadRequestWithError(c, "请求参数错误", err)
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	err = h.roleService.AssignPermissions(uint(id), req.PermissionIDs)
+	err := h.roleService.AssignPermissions(c.Request.Context(), uint(id), req.PermissionIDs)
 	if err != nil {
-		util.InternalServerErrorWithError(c, "分配权限失败", err)
+		util.Fail(c, err)
 		return
 	}
 
-	util.SuccessWithMessage(c, "权限分配成功", nil)
+	util.SuccessWithMessage(c, i18n.M(i18n.MsgRolePermissionsAssigned), nil)
 }
 
 // RemovePermissions 移除角色的权限
@@ -268,26 +443,23 @@ func (h *RoleHandler) AssignPermissions(c *gin.Context) {
 // @Failure      500           {object}  util.Response
 // @Router       /roles/{id}/permissions [delete]
 func (h *RoleHandler) RemovePermissions(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
-	if err != nil {
-		util.BadRequest(c, "无效的角色ID")
+	id, ok := parseID(c, i18n.MsgResourceRole)
+	if !ok {
 		return
 	}
 
 	var req AssignPermissionsRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		util.BadRequestWithError(c, "请求参数错误", err)
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	err = h.roleService.RemovePermissions(uint(id), req.PermissionIDs)
+	err := h.roleService.RemovePermissions(c.Request.Context(), uint(id), req.PermissionIDs)
 	if err != nil {
-		util.InternalServerErrorWithError(c, "移除权限失败", err)
+		util.Fail(c, err)
 		return
 	}
 
-	util.SuccessWithMessage(c, "权限移除成功", nil)
+	util.SuccessWithMessage(c, i18n.M(i18n.MsgRolePermissionsRemoved), nil)
 }
 
 // GetRolePermissions 获取角色的权限列表
@@ -303,16 +475,14 @@ func (h *RoleHandler) RemovePermissions(c *gin.Context) {
 // @Failure      401           {object}  util.Response
 // @Router       /roles/{id}/permissions [get]
 func (h *RoleHandler) GetRolePermissions(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
-	if err != nil {
-		util.BadRequest(c, "无效的角色ID")
+	id, ok := parseID(c, i18n.MsgResourceRole)
+	if !ok {
 		return
 	}
 
-	permissions, err := h.roleService.GetRolePermissions(uint(id))
+	permissions, err := h.roleService.GetRolePermissions(c.Request.Context(), uint(id))
 	if err != nil {
-		util.InternalServerErrorWithError(c, "获取权限列表失败", err)
+		util.Fail(c, err)
 		return
 	}
 
@@ -334,26 +504,23 @@ func (h *RoleHandler) GetRolePermissions(c *gin.Context) {
 // @Failure      500           {object}  util.Response
 // @Router       /roles/{id}/users [post]
 func (h *RoleHandler) AssignUsers(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
-	if err != nil {
-		util.BadRequest(c, "无效的角色ID")
+	id, ok := parseID(c, i18n.MsgResourceRole)
+	if !ok {
 		return
 	}
 
 	var req AssignUsersRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		util.BadRequestWithError(c, "请求参数错误", err)
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	err = h.roleService.AssignUsers(uint(id), req.UserIDs)
+	err := h.roleService.AssignUsers(c.Request.Context(), uint(id), req.UserIDs)
 	if err != nil {
-		util.InternalServerErrorWithError(c, "分配用户失败", err)
+		util.Fail(c, err)
 		return
 	}
 
-	util.SuccessWithMessage(c, "用户分配成功", nil)
+	util.SuccessWithMessage(c, i18n.M(i18n.MsgRoleUsersAssigned), nil)
 }
 
 // RemoveUsers 移除角色的用户
@@ -371,26 +538,23 @@ func (h *RoleHandler) AssignUsers(c *gin.Context) {
 // @Failure      500           {object}  util.Response
 // @Router       /roles/{id}/users [delete]
 func (h *RoleHandler) RemoveUsers(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
-	if err != nil {
-		util.BadRequest(c, "无效的角色ID")
+	id, ok := parseID(c, i18n.MsgResourceRole)
+	if !ok {
 		return
 	}
 
 	var req AssignUsersRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		util.BadRequestWithError(c, "请求参数错误", err)
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	err = h.roleService.RemoveUsers(uint(id), req.UserIDs)
+	err := h.roleService.RemoveUsers(c.Request.Context(), uint(id), req.UserIDs)
 	if err != nil {
-		util.InternalServerErrorWithError(c, "移除用户失败", err)
+		util.Fail(c, err)
 		return
 	}
 
-	util.SuccessWithMessage(c, "用户移除成功", nil)
+	util.SuccessWithMessage(c, i18n.M(i18n.MsgRoleUsersRemoved), nil)
 }
 
 // GetRoleUsers 获取角色的用户列表
@@ -406,18 +570,73 @@ func (h *RoleHandler) RemoveUsers(c *gin.Context) {
 // @Failure      401           {object}  util.Response
 // @Router       /roles/{id}/users [get]
 func (h *RoleHandler) GetRoleUsers(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
-	if err != nil {
-		util.BadRequest(c, "无效的角色ID")
+	id, ok := parseID(c, i18n.MsgResourceRole)
+	if !ok {
 		return
 	}
 
-	users, err := h.roleService.GetRoleUsers(uint(id))
+	users, err := h.roleService.GetRoleUsers(c.Request.Context(), uint(id))
 	if err != nil {
-		util.InternalServerErrorWithError(c, "获取用户列表失败", err)
+		util.Fail(c, err)
 		return
 	}
 
 	util.Success(c, users)
 }
+
+// BatchRoles 批量操作角色
+// @Summary      批量创建、更新、删除角色
+// @Description  一次请求执行多个创建、更新、删除操作，每个操作的 data 与单个创建、更新接口的请求体相同，每个操作单独记录审计日志。
+// @Description  需要请求中出现的各操作类型对应的权限（role:create/role:update/role:delete）。
+// @Description  atomic 模式（默认）在同一个事务中执行，任一操作失败时全部回滚，响应状态码为失败操作的状态码，其他操作的 status 为 424；
+// @Description  best_effort 模式逐个执行，互不影响，始终返回 200，通过每个操作的 status 和 error 判断结果
+// @Tags         角色管理
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header    string        true  "Bearer {token}"  default(Bearer )
+// @Param        batch         body      BatchRequest  true  "批量操作"
+// @Success      200           {object}  util.Response{data=BatchResponse}
+// @Failure      400           {object}  util.Response{data=BatchResponse}
+// @Failure      401           {object}  util.Response
+// @Failure      403           {object}  util.Response
+// @Failure      404           {object}  util.Response{data=BatchResponse}
+// @Failure      409           {object}  util.Response{data=BatchResponse}
+// @Failure      412           {object}  util.Response{data=BatchResponse}
+// @Failure      413           {object}  util.Response
+// @Failure      500           {object}  util.Response{data=BatchResponse}
+// @Router       /roles:batch [post]
+func (h *RoleHandler) BatchRoles(c *gin.Context) {
+	var ops []service.RoleBatchOp
+	handleBatch(c, h.configManager, batchProcessor{
+		decode: func(op BatchOperation) error {
+			item := service.RoleBatchOp{Op: op.Op, ID: op.ID, Version: op.Version, Status: -1}
+			switch op.Op {
+			case service.BatchCreate:
+				var req CreateRoleRequest
+				if err := bindBatchData(op.Data, &req); err != nil {
+					return err
+				}
+				item.Name, item.DisplayName, item.Description = req.Name, req.DisplayName, req.Description
+			case service.BatchUpdate:
+				var req UpdateRoleRequest
+				if err := bindBatchData(op.Data, &req); err != nil {
+					return err
+				}
+				if req.DisplayName != nil {
+					item.DisplayName = *req.DisplayName
+				}
+				if req.Description != nil {
+					item.Description = *req.Description
+				}
+				if req.Status != nil {
+					item.Status = *req.Status
+				}
+			}
+			ops = append(ops, item)
+			return nil
+		},
+		execute: func(atomic bool) []service.BatchResult {
+			return h.roleService.BatchRoles(c.Request.Context(), ops, atomic)
+		},
+	})
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"strconv"
+
+	"go_web/internal/apperr"
+	"go_web/internal/i18n"
+	"go_web/internal/query"
+	"go_web/internal/repository"
+	"go_web/internal/service"
+	"go_web/internal/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SecurityEventHandler struct {
+	securityEventService service.SecurityEventService
+}
+
+func NewSecurityEventHandler(securityEventService service.SecurityEventService) *SecurityEventHandler {
+	return &SecurityEventHandler{securityEventService: securityEventService}
+}
+
+// ListSecurityEvents 安全事件列表
+// @Summary      获取安全事件列表
+// @Description  按条件分页查询登录失败、无效 Token、未登录访问、权限不足等安全事件
+// @Tags         安全事件
+// @Accept       json
+// @Produce      json
+// @Param        event_type    query     string  false  "事件类型（login_failed/invalid_token/unauthenticated/forbidden）"
+// @Param        user_id       query     int     false  "用户ID"
+// @Param        email         query     string  false  "用户邮箱"
+// @Param        ip            query     string  false  "客户端IP"
+// @Param        start_time    query     string  false  "起始时间（RFC3339 或 2006-01-02，包含）"
+// @Param        end_time      query     string  false  "结束时间（RFC3339 或 2006-01-02，不包含）"
+// @Param        page          query     int     false  "页码"      default(1)
+// @Param        page_size     query     int     false  "每页数量（最大 100）"   default(10)
+// @Param        cursor        query     string  false  "游标分页：为空表示第一页，之后使用响应中的 next_cursor/prev_cursor，不能与 page 同时使用"
+// @Param        Authorization header    string  true   "Bearer {token}"  default(Bearer )
+// @Success      200           {object}  util.Response{data=util.PageData{list=[]model.SecurityEvent}}
+// @Failure      400           {object}  util.Response
+// @Failure      401           {object}  util.Response
+// @Failure      403           {object}  util.Response
+// @Failure      500           {object}  util.Response
+// @Router       /security-events [get]
+func (h *SecurityEventHandler) ListSecurityEvents(c *gin.Context) {
+	page, err := query.ParsePage(c.Request.URL.Query())
+	if err != nil {
+		util.Fail(c, err)
+		return
+	}
+
+	filter, err := parseSecurityEventFilter(c)
+	if err != nil {
+		util.Fail(c, err)
+		return
+	}
+
+	events, info, err := h.securityEventService.ListEvents(c.Request.Context(), filter, page)
+	if err != nil {
+		util.Fail(c, err)
+		return
+	}
+
+	util.SuccessWithPagination(c, events, info)
+}
+
+// parseSecurityEventFilter 从查询参数解析安全事件过滤条件
+func parseSecurityEventFilter(c *gin.Context) (repository.SecurityEventFilter, error) {
+	filter := repository.SecurityEventFilter{
+		EventType: c.Query("event_type"),
+		Email:     c.Query("email"),
+		IP:        c.Query("ip"),
+	}
+
+	if userID := c.Query("user_id"); userID != "" {
+		id, err := strconv.ParseUint(userID, 10, 32)
+		if err != nil {
+			return filter, apperr.ErrBadRequest.WithMessage(i18n.MsgInvalidIDValue, i18n.M(i18n.MsgResourceUser), userID)
+		}
+		filter.UserID = uint(id)
+	}
+
+	startTime, err := service.ParseFilterTime(c.Query("start_time"))
+	if err != nil {
+		return filter, err
+	}
+	filter.StartTime = startTime
+
+	endTime, err := service.ParseFilterTime(c.Query("end_time"))
+	if err != nil {
+		return filter, err
+	}
+	filter.EndTime = endTime
+
+	return filter, nil
+}
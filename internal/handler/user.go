@@ -1,9 +1,12 @@
 package handler
 
 import (
-	"strconv"
 	"time"
 
+	"go_web/internal/config"
+	"go_web/internal/i18n"
+	"go_web/internal/model"
+	"go_web/internal/query"
 	"go_web/internal/service"
 	"go_web/internal/util"
 
@@ -11,11 +14,13 @@ import (
 )
 
 type UserHandler struct {
-	userService service.UserService
+	userService         service.UserService
+	userTransferService service.UserTransferService
+	configManager       *config.Manager
 }
 
-func NewUserHandler(userService service.UserService) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService service.UserService, userTransferService service.UserTransferService, configManager *config.Manager) *UserHandler {
+	return &UserHandler{userService: userService, userTransferService: userTransferService, configManager: configManager}
 }
 
 type CreateUserRequest struct {
@@ -25,8 +30,16 @@ type CreateUserRequest struct {
 }
 
 type UpdateUserRequest struct {
-	Name   *string `json:"name" example:"李四"`  // 用户姓名（可选）
-	Status *int    `json:"status" example:"1"` // 用户状态：1-正常，0-禁用（可选）
+	Name     *string `json:"name" example:"李四"`                                     // 用户姓名（可选）
+	Status   *int    `json:"status" example:"1"`                                    // 用户状态：1-正常，0-禁用（可选）
+	Language *string `json:"language" binding:"omitempty,oneof=zh en" example:"en"` // 接口提示信息的语言偏好：zh/en（可选）
+}
+
+// userPatch PATCH 合并后的用户信息，按 binding 标签校验合并结果
+type userPatch struct {
+	Name     string `json:"name" binding:"required"`
+	Status   int    `json:"status" binding:"oneof=0 1"`
+	Language string `json:"language" binding:"omitempty,oneof=zh en"`
 }
 
 // UserResponse 用户响应结构体（用于 Swagger 文档）
@@ -37,6 +50,8 @@ type UserResponse struct {
 	Name      string    `json:"name" example:"张三"`                         // 用户姓名
 	Email     string    `json:"email" example:"zhangsan@example.com"`      // 用户邮箱
 	Status    int       `json:"status" example:"1"`                        // 用户状态：1-正常，0-禁用
+	Version   uint      `json:"version" example:"1"`                       // 版本号，每次更新加一
+	Language  string    `json:"language" example:"zh"`                     // 语言偏好，为空时按 Accept-Language 协商
 }
 
 // CreateUser 创建用户
@@ -54,18 +69,17 @@ type UserResponse struct {
 // @Router       /users [post]
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var req CreateUserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		util.BadRequestWithError(c, "请求参数错误", err)
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	user, err := h.userService.CreateUser(req.Name, req.Email, req.Password)
+	user, err := h.userService.CreateUser(c.Request.Context(), req.Name, req.Email, req.Password)
 	if err != nil {
-		util.InternalServerErrorWithError(c, "创建用户失败", err)
+		util.Fail(c, err)
 		return
 	}
 
-	util.CreatedWithMessage(c, "用户创建成功", user)
+	util.CreatedWithMessage(c, i18n.M(i18n.MsgUserCreated), user)
 }
 
 // GetUser 获取用户详情
@@ -75,27 +89,39 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        id            path      int     true  "用户ID"
+// @Param        fields        query     string  false  "只返回这些字段，逗号分隔，id 总是返回；可选: id、name、email、status、version、language、created_at、updated_at、deleted_at"  example(id,name)
+// @Param        include       query     string  false  "展开的关联，逗号分隔，为空表示不展开；可选: roles、roles.permissions。未指定 fields 和 include 时展开 roles、roles.permissions"
 // @Param        Authorization header    string  true  "Bearer {token}"  default(Bearer )
 // @Success      200           {object}  util.Response{data=UserResponse}
+// @Header       200           {string}  ETag  "用户的版本号，更新或删除时通过 If-Match 带回"
 // @Failure      400           {object}  util.Response
 // @Failure      401           {object}  util.Response
 // @Failure      404           {object}  util.Response
 // @Router       /users/{id} [get]
 func (h *UserHandler) GetUser(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
+	id, ok := parseID(c, i18n.MsgResourceUser)
+	if !ok {
+		return
+	}
+
+	proj, err := query.ParseProjection(c.Request.URL.Query())
 	if err != nil {
-		util.BadRequest(c, "无效的用户ID")
+		util.Fail(c, err)
 		return
 	}
 
-	user, err := h.userService.GetUserByID(uint(id))
+	user, err := h.userService.GetUserProjected(c.Request.Context(), uint(id), proj)
 	if err != nil {
-		util.NotFound(c, "用户不存在")
+		util.Fail(c, err)
 		return
 	}
 
-	util.Success(c, user)
+	data, ok := render(c, proj, user)
+	if !ok {
+		return
+	}
+	setETag(c, user.Version)
+	util.Success(c, data)
 }
 
 // UpdateUser 更新用户
@@ -107,27 +133,35 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 // @Param        id            path      int                true  "用户ID"
 // @Param        Authorization header    string             true  "Bearer {token}"  default(Bearer )
 // @Param        user          body      UpdateUserRequest  true  "用户信息"
+// @Param        If-Match      header    string             false "GET 返回的 ETag，版本不一致时返回 412"
 // @Success      200           {object}  util.Response{data=UserResponse}
+// @Header       200           {string}  ETag  "更新后的版本号"
 // @Failure      400           {object}  util.Response
 // @Failure      401           {object}  util.Response
+// @Failure      404           {object}  util.Response
+// @Failure      409           {object}  util.Response
+// @Failure      412           {object}  util.Response
 // @Failure      500           {object}  util.Response
 // @Router       /users/{id} [put]
 func (h *UserHandler) UpdateUser(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
-	if err != nil {
-		util.BadRequest(c, "无效的用户ID")
+	id, ok := parseID(c, i18n.MsgResourceUser)
+	if !ok {
 		return
 	}
 
 	var req UpdateUserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		util.BadRequestWithError(c, "请求参数错误", err)
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		util.Fail(c, err)
 		return
 	}
 
 	// 处理指针参数
-	var name string
+	var name, language string
 	var status int = -1 // -1表示不更新
 	if req.Name != nil {
 		name = *req.Name
@@ -135,14 +169,73 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 	if req.Status != nil {
 		status = *req.Status
 	}
+	if req.Language != nil {
+		language = *req.Language
+	}
 
-	user, err := h.userService.UpdateUser(uint(id), name, status)
+	user, err := h.userService.UpdateUser(c.Request.Context(), uint(id), version, name, status, language)
 	if err != nil {
-		util.InternalServerErrorWithError(c, "更新用户失败", err)
+		util.Fail(c, err)
 		return
 	}
 
-	util.SuccessWithMessage(c, "用户更新成功", user)
+	setETag(c, user.Version)
+	util.SuccessWithMessage(c, i18n.M(i18n.MsgUserUpdated), user)
+}
+
+// PatchUser 部分更新用户
+// @Summary      部分更新用户（JSON Merge Patch）
+// @Description  按 RFC 7396 合并修改用户：请求中没有的字段不修改，language 为 null 时清除语言偏好（改为按 Accept-Language 协商），name、status 不能为 null。
+// @Description  合并后的结果需通过校验：name 不能为空，status 为 0 或 1，language 为 zh、en 或空。只写入有变化的字段，审计日志只记录这些字段；没有变化时不写入，版本号不变
+// @Tags         用户管理
+// @Accept       application/merge-patch+json,json
+// @Produce      json
+// @Param        id            path      int                true  "用户ID"
+// @Param        Authorization header    string             true  "Bearer {token}"  default(Bearer )
+// @Param        user          body      UpdateUserRequest  true  "要修改的字段"
+// @Param        If-Match      header    string             false "GET 返回的 ETag，版本不一致时返回 412"
+// @Success      200           {object}  util.Response{data=UserResponse}
+// @Header       200           {string}  ETag  "更新后的版本号"
+// @Failure      400           {object}  util.Response
+// @Failure      401           {object}  util.Response
+// @Failure      404           {object}  util.Response
+// @Failure      409           {object}  util.Response
+// @Failure      412           {object}  util.Response
+// @Failure      415           {object}  util.Response
+// @Failure      500           {object}  util.Response
+// @Router       /users/{id} [patch]
+func (h *UserHandler) PatchUser(c *gin.Context) {
+	id, ok := parseID(c, i18n.MsgResourceUser)
+	if !ok {
+		return
+	}
+
+	patch, ok := bindMergePatch(c)
+	if !ok {
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		util.Fail(c, err)
+		return
+	}
+
+	user, err := h.userService.PatchUser(c.Request.Context(), id, version, func(user *model.User) error {
+		doc := userPatch{Name: user.Name, Status: user.Status, Language: user.Language}
+		if err := patch.apply(&doc, "language"); err != nil {
+			return err
+		}
+		user.Name, user.Status, user.Language = doc.Name, doc.Status, doc.Language
+		return nil
+	})
+	if err != nil {
+		util.Fail(c, err)
+		return
+	}
+
+	setETag(c, user.Version)
+	util.SuccessWithMessage(c, i18n.M(i18n.MsgUserUpdated), user)
 }
 
 // DeleteUser 删除用户
@@ -153,62 +246,196 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 // @Produce      json
 // @Param        id            path      int     true  "用户ID"
 // @Param        Authorization header    string  true  "Bearer {token}"  default(Bearer )
+// @Param        If-Match      header    string  false "GET 返回的 ETag，版本不一致时返回 412"
 // @Success      200           {object}  util.Response
 // @Failure      400           {object}  util.Response
 // @Failure      401           {object}  util.Response
+// @Failure      404           {object}  util.Response
+// @Failure      412           {object}  util.Response
 // @Failure      500           {object}  util.Response
 // @Router       /users/{id} [delete]
 func (h *UserHandler) DeleteUser(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
+	id, ok := parseID(c, i18n.MsgResourceUser)
+	if !ok {
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		util.Fail(c, err)
+		return
+	}
+
+	err = h.userService.DeleteUser(c.Request.Context(), uint(id), version)
+	if err != nil {
+		util.Fail(c, err)
+		return
+	}
+
+	util.SuccessWithMessage(c, i18n.M(i18n.MsgUserDeleted), nil)
+}
+
+// RestoreUser 恢复用户
+// @Summary      恢复已删除的用户
+// @Description  恢复已软删除的用户，邮箱已被其他用户使用时返回 409
+// @Tags         用户管理
+// @Accept       json
+// @Produce      json
+// @Param        id            path      int     true  "用户ID"
+// @Param        Authorization header    string  true  "Bearer {token}"  default(Bearer )
+// @Param        If-Match      header    string  false "GET 返回的 ETag，版本不一致时返回 412"
+// @Success      200           {object}  util.Response{data=UserResponse}
+// @Failure      400           {object}  util.Response
+// @Failure      401           {object}  util.Response
+// @Failure      404           {object}  util.Response
+// @Failure      409           {object}  util.Response
+// @Failure      412           {object}  util.Response
+// @Failure      500           {object}  util.Response
+// @Router       /users/{id}/restore [post]
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	id, ok := parseID(c, i18n.MsgResourceUser)
+	if !ok {
+		return
+	}
+
+	version, err := ifMatchVersion(c)
 	if err != nil {
-		util.BadRequest(c, "无效的用户ID")
+		util.Fail(c, err)
 		return
 	}
 
-	err = h.userService.DeleteUser(uint(id))
+	user, err := h.userService.RestoreUser(c.Request.Context(), uint(id), version)
 	if err != nil {
-		util.InternalServerErrorWithError(c, "删除用户失败", err)
+		util.Fail(c, err)
 		return
 	}
 
-	util.SuccessWithMessage(c, "用户删除成功", nil)
+	setETag(c, user.Version)
+	util.SuccessWithMessage(c, i18n.M(i18n.MsgUserRestored), user)
+}
+
+// PurgeUser 彻底删除用户
+// @Summary      彻底删除用户
+// @Description  从数据库中删除已软删除的用户及其关联，不可恢复；未删除的用户需要先删除，否则返回 409
+// @Tags         用户管理
+// @Accept       json
+// @Produce      json
+// @Param        id            path      int     true  "用户ID"
+// @Param        Authorization header    string  true  "Bearer {token}"  default(Bearer )
+// @Success      200           {object}  util.Response
+// @Failure      400           {object}  util.Response
+// @Failure      401           {object}  util.Response
+// @Failure      404           {object}  util.Response
+// @Failure      409           {object}  util.Response
+// @Failure      500           {object}  util.Response
+// @Router       /users/{id}/purge [delete]
+func (h *UserHandler) PurgeUser(c *gin.Context) {
+	id, ok := parseID(c, i18n.MsgResourceUser)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.PurgeUser(c.Request.Context(), uint(id)); err != nil {
+		util.Fail(c, err)
+		return
+	}
+
+	util.SuccessWithMessage(c, i18n.M(i18n.MsgUserPurged), nil)
 }
 
 // ListUsers 用户列表
 // @Summary      获取用户列表
-// @Description  分页获取用户列表
+// @Description  分页获取用户列表，支持过滤、排序和搜索。过滤参数格式为 filter[字段]=值 或 filter[字段][操作符]=值，操作符为 eq、ne、gt、gte、lt、lte、like、in（in 的多个值用逗号分隔）；可过滤和排序的字段: id、name、email、status、created_at、updated_at；deleted_at 只能过滤，需配合 include_deleted 使用
 // @Tags         用户管理
 // @Accept       json
 // @Produce      json
 // @Param        page          query     int     false  "页码"      default(1)
-// @Param        page_size     query     int     false  "每页数量"   default(10)
+// @Param        page_size     query     int     false  "每页数量（最大 100）"   default(10)
+// @Param        cursor        query     string  false  "游标分页：为空表示第一页，之后使用响应中的 next_cursor/prev_cursor，不能与 page 同时使用"
+// @Param        sort          query     string  false  "排序字段，多个用逗号分隔，前缀 - 表示降序"  example(-created_at,name)
+// @Param        q             query     string  false  "关键字，模糊匹配 name、email"
+// @Param        include_deleted query   string  false  "包含已软删除的记录：true 包含，only 只返回已删除的记录"  Enums(true, false, only)
+// @Param        fields        query     string  false  "只返回这些字段，逗号分隔，id 总是返回；可选: id、name、email、status、version、language、created_at、updated_at、deleted_at"  example(id,name)
+// @Param        include       query     string  false  "展开的关联，逗号分隔，为空表示不展开；可选: roles、roles.permissions。未指定 fields 和 include 时展开 roles"
 // @Param        Authorization header    string  false  "Bearer {token}"  default(Bearer )
-// @Success      200           {object}  util.Response{data=object{list=[]UserResponse,total=int,page=int,page_size=int}}
+// @Success      200           {object}  util.Response{data=util.PageData{list=[]UserResponse}}
+// @Failure      400           {object}  util.Response
 // @Failure      401           {object}  util.Response
 // @Failure      500           {object}  util.Response
 // @Router       /users [get]
 func (h *UserHandler) ListUsers(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-
-	if page < 1 {
-		page = 1
-	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 10
+	spec, page, err := parseListQuery(c)
+	if err != nil {
+		util.Fail(c, err)
+		return
 	}
 
-	users, total, err := h.userService.ListUsers(page, pageSize)
+	users, info, err := h.userService.ListUsers(c.Request.Context(), spec, page)
 	if err != nil {
-		util.InternalServerErrorWithError(c, "获取用户列表失败", err)
+		util.Fail(c, err)
+		return
+	}
+
+	data, ok := render(c, spec.Projection, users)
+	if !ok {
 		return
 	}
+	util.SuccessWithPagination(c, data, info)
+}
 
-	util.Success(c, gin.H{
-		"list":      users,
-		"total":     total,
-		"page":      page,
-		"page_size": pageSize,
+// BatchUsers 批量操作用户
+// @Summary      批量创建、更新、删除用户
+// @Description  一次请求执行多个创建、更新、删除操作，每个操作的 data 与单个创建、更新接口的请求体相同，每个操作单独记录审计日志。
+// @Description  需要请求中出现的各操作类型对应的权限（user:create/user:update/user:delete）。
+// @Description  atomic 模式（默认）在同一个事务中执行，任一操作失败时全部回滚，响应状态码为失败操作的状态码，其他操作的 status 为 424；
+// @Description  best_effort 模式逐个执行，互不影响，始终返回 200，通过每个操作的 status 和 error 判断结果
+// @Tags         用户管理
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header    string        true  "Bearer {token}"  default(Bearer )
+// @Param        batch         body      BatchRequest  true  "批量操作"
+// @Success      200           {object}  util.Response{data=BatchResponse}
+// @Failure      400           {object}  util.Response{data=BatchResponse}
+// @Failure      401           {object}  util.Response
+// @Failure      403           {object}  util.Response
+// @Failure      404           {object}  util.Response{data=BatchResponse}
+// @Failure      409           {object}  util.Response{data=BatchResponse}
+// @Failure      412           {object}  util.Response{data=BatchResponse}
+// @Failure      413           {object}  util.Response
+// @Failure      500           {object}  util.Response{data=BatchResponse}
+// @Router       /users:batch [post]
+func (h *UserHandler) BatchUsers(c *gin.Context) {
+	var ops []service.UserBatchOp
+	handleBatch(c, h.configManager, batchProcessor{
+		decode: func(op BatchOperation) error {
+			item := service.UserBatchOp{Op: op.Op, ID: op.ID, Version: op.Version, Status: -1}
+			switch op.Op {
+			case service.BatchCreate:
+				var req CreateUserRequest
+				if err := bindBatchData(op.Data, &req); err != nil {
+					return err
+				}
+				item.Name, item.Email, item.Password = req.Name, req.Email, req.Password
+			case service.BatchUpdate:
+				var req UpdateUserRequest
+				if err := bindBatchData(op.Data, &req); err != nil {
+					return err
+				}
+				if req.Name != nil {
+					item.Name = *req.Name
+				}
+				if req.Status != nil {
+					item.Status = *req.Status
+				}
+				if req.Language != nil {
+					item.Language = *req.Language
+				}
+			}
+			ops = append(ops, item)
+			return nil
+		},
+		execute: func(atomic bool) []service.BatchResult {
+			return h.userService.BatchUsers(c.Request.Context(), ops, atomic)
+		},
 	})
 }
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go_web/internal/apperr"
+	"go_web/internal/i18n"
+	"go_web/internal/query"
+	"go_web/internal/service"
+	"go_web/internal/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// userImportMaxBytes 导入文件的大小上限
+const userImportMaxBytes = 10 << 20
+
+// ExportUsers 导出用户
+// @Summary      导出用户
+// @Description  按列表接口的过滤、排序、搜索条件流式导出用户及其角色名称，列为 id、name、email、status、roles（多个用 ; 分隔）、created_at。
+// @Description  过滤语法与字段见获取用户列表接口
+// @Tags         用户管理
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        format        query     string  false  "导出格式（csv/xlsx）"  default(csv)
+// @Param        sort          query     string  false  "排序字段，多个用逗号分隔，前缀 - 表示降序"  example(-created_at,name)
+// @Param        q             query     string  false  "关键字，模糊匹配 name、email"
+// @Param        Authorization header    string  true   "Bearer {token}"  default(Bearer )
+// @Success      200           {file}    file
+// @Failure      400           {object}  util.Response
+// @Failure      401           {object}  util.Response
+// @Failure      403           {object}  util.Response
+// @Router       /users/export [get]
+func (h *UserHandler) ExportUsers(c *gin.Context) {
+	format := c.DefaultQuery("format", service.ExportFormatCSV)
+	var contentType string
+	switch format {
+	case service.ExportFormatCSV:
+		contentType = "text/csv; charset=utf-8"
+	case service.ExportFormatXLSX:
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		util.Fail(c, apperr.ErrUnsupportedFormat.WithMessage(i18n.MsgExportFormat, format))
+		return
+	}
+
+	spec, err := query.Parse(c.Request.URL.Query())
+	if err != nil {
+		util.Fail(c, err)
+		return
+	}
+
+	fileName := fmt.Sprintf("users_%s.%s", time.Now().Format("20060102150405"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileName))
+	c.Status(http.StatusOK)
+
+	err = h.userTransferService.ExportUsers(c.Request.Context(), spec, format, c.Writer)
+	if err == nil {
+		return
+	}
+	// 数据已开始写出时只能记录错误，无法再修改响应状态
+	if c.Writer.Written() {
+		_ = c.Error(err)
+		return
+	}
+	c.Header("Content-Type", "")
+	c.Header("Content-Disposition", "")
+	util.Fail(c, err)
+}
+
+// ImportUsers 导入用户
+// @Summary      导入用户
+// @Description  上传 CSV 或 xlsx 文件批量创建用户并分配角色。首行为表头，按名称识别 name、email、password、roles 列（roles 可选，多个角色用 ; 或 , 分隔），其他列忽略；xlsx 读取第一个工作表。
+// @Description  逐行校验必填项、邮箱格式、密码长度、文件内重复邮箱、已存在的邮箱和不存在的角色，有任一行校验失败时不导入并返回 422 和逐行错误报告。
+// @Description  dry_run=true 时只返回校验报告；否则在一个事务中创建全部用户并分配角色，写入失败时全部回滚并返回 409 和报告。需要 user:create 和 role:update 权限
+// @Tags         用户管理
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file          formData  file    true   "导入文件（.csv/.xlsx，最大 10MB）"
+// @Param        format        query     string  false  "文件格式（csv/xlsx），为空时按文件扩展名判断"
+// @Param        dry_run       query     bool    false  "仅校验并返回预览报告，不写入"  default(false)
+// @Param        Authorization header    string  true   "Bearer {token}"  default(Bearer )
+// @Success      200           {object}  util.Response{data=service.UserImportReport}  "预览"
+// @Success      201           {object}  util.Response{data=service.UserImportReport}  "导入成功"
+// @Failure      400           {object}  util.Response
+// @Failure      401           {object}  util.Response
+// @Failure      403           {object}  util.Response
+// @Failure      409           {object}  util.Response{data=service.UserImportReport}
+// @Failure      422           {object}  util.Response{data=service.UserImportReport}
+// @Failure      500           {object}  util.Response
+// @Router       /users/import [post]
+func (h *UserHandler) ImportUsers(c *gin.Context) {
+	dryRun := c.Query("dry_run") == "true"
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, userImportMaxBytes)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		util.Fail(c, apperr.ErrBadRequest.WithMessage(i18n.MsgImportFileRequired).Wrap(err))
+		return
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+	}
+	if format != service.ExportFormatCSV && format != service.ExportFormatXLSX {
+		util.Fail(c, apperr.ErrUnsupportedFormat.WithMessage(i18n.MsgImportFormat, format))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		util.Fail(c, apperr.ErrBadRequest.WithMessage(i18n.MsgImportFileReadFailed).Wrap(err))
+		return
+	}
+	defer file.Close()
+
+	report, err := h.userTransferService.ImportUsers(c.Request.Context(), format, file, dryRun)
+	switch {
+	case err == nil && dryRun:
+		util.SuccessWithMessage(c, i18n.M(i18n.MsgUserImportValidated), report)
+	case err == nil:
+		util.CreatedWithMessage(c, i18n.M(i18n.MsgUsersImported, report.Imported), report)
+	case report != nil:
+		// 逐行校验或写入失败时附带导入报告
+		util.FailWithData(c, err, report)
+	default:
+		util.Fail(c, err)
+	}
+}
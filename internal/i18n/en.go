@@ -0,0 +1,194 @@
+package i18n
+
+// en 英文消息表，缺少的消息码回退到中文
+var en = map[string]string{
+	// 错误码（apperr）
+	"BAD_REQUEST":                "Invalid request parameters",
+	"VALIDATION_FAILED":          "Request validation failed",
+	"INVALID_ID":                 "Invalid ID",
+	"INVALID_QUERY":              "Invalid query parameters",
+	"INVALID_IF_MATCH":           "Invalid If-Match, expected the ETag returned by GET, e.g. \"3\"",
+	"UNSUPPORTED_FORMAT":         "Unsupported file format",
+	"UNAUTHORIZED":               "Unauthorized, please log in first",
+	"PERMISSION_DENIED":          "Permission denied",
+	"NOT_FOUND":                  "Resource not found",
+	"VERSION_CONFLICT":           "The record was modified by another request, please refresh and retry",
+	"VERSION_MISMATCH":           "Record version mismatch, please refresh and retry",
+	"RECORD_NOT_DELETED":         "Record is not deleted",
+	"DUPLICATE_RECORD":           "The record conflicts with an existing record",
+	"REQUEST_TOO_LARGE":          "Request body too large",
+	"UNSUPPORTED_MEDIA_TYPE":     "Unsupported request content type",
+	"RATE_LIMITED":               "Too many requests, please try again later",
+	"INTERNAL_ERROR":             "Internal server error",
+	"INVALID_CREDENTIALS":        "Invalid email or password",
+	"USER_DISABLED":              "User is disabled",
+	"USER_NOT_FOUND":             "User not found",
+	"USER_EMAIL_TAKEN":           "Email already exists",
+	"ROLE_NOT_FOUND":             "Role not found",
+	"ROLE_IN_USE":                "Role is still assigned to users",
+	"ROLE_NAME_TAKEN":            "Role name already exists",
+	"PERMISSION_NOT_FOUND":       "Permission not found",
+	"PERMISSION_IN_USE":          "Permission is still used by roles",
+	"PERMISSION_NAME_TAKEN":      "Permission name already exists",
+	"BATCH_ABORTED":              "Another operation in the batch failed, this operation was rolled back or not executed",
+	"IMPORT_FILE_INVALID":        "Unable to parse the import file",
+	"IMPORT_ROWS_INVALID":        "Import validation failed, no users were imported",
+	"IMPORT_FAILED":              "Import failed, all changes were rolled back",
+	"AUDIT_LOG_NOT_FOUND":        "Audit log not found",
+	"REQUEST_AUDIT_NOT_FOUND":    "Request audit not found",
+	"AUDIT_REVERT_NOT_SUPPORTED": "This audit log cannot be reverted",
+	"AUDIT_REVERT_CONFLICT":      "Reverted data conflicts with an existing record",
+
+	// 成功提示
+	MsgOK:                      "Success",
+	MsgCreated:                 "Created successfully",
+	MsgServiceHealthy:          "Service is healthy",
+	MsgUserCreated:             "User created",
+	MsgUserUpdated:             "User updated",
+	MsgUserDeleted:             "User deleted",
+	MsgUserRestored:            "User restored",
+	MsgUserPurged:              "User purged",
+	MsgRoleCreated:             "Role created",
+	MsgRoleUpdated:             "Role updated",
+	MsgRoleDeleted:             "Role deleted",
+	MsgRoleRestored:            "Role restored",
+	MsgRolePurged:              "Role purged",
+	MsgRolePermissionsAssigned: "Permissions assigned",
+	MsgRolePermissionsRemoved:  "Permissions removed",
+	MsgRoleUsersAssigned:       "Users assigned",
+	MsgRoleUsersRemoved:        "Users removed",
+	MsgPermissionCreated:       "Permission created",
+	MsgPermissionUpdated:       "Permission updated",
+	MsgPermissionDeleted:       "Permission deleted",
+	MsgPermissionRestored:      "Permission restored",
+	MsgPermissionPurged:        "Permission purged",
+	MsgBatchSucceeded:          "Batch completed successfully",
+	MsgBatchPartiallyFailed:    "Batch completed, some operations failed",
+	MsgAuditLogReverted:        "Reverted successfully",
+	MsgUserImportValidated:     "Validation passed, ready to import",
+	MsgUsersImported:           "Imported %d users",
+
+	// 资源名称
+	MsgResourceUser:       "user",
+	MsgResourceRole:       "role",
+	MsgResourcePermission: "permission",
+	MsgResourceAuditLog:   "audit log",
+	MsgResourceRecord:     "record",
+
+	// 请求与认证
+	MsgRouteNotFound:         "Endpoint not found",
+	MsgBodyInvalidJSON:       "Request body is not valid JSON",
+	MsgBodyEmpty:             "Request body must not be empty",
+	MsgBodyTooLarge:          "Request body must not exceed %d bytes",
+	MsgBodyReadFailed:        "Failed to read request body",
+	MsgBodyNotObject:         "Request body must be a JSON object",
+	MsgPatchContentType:      "Content-Type must be application/merge-patch+json or application/json",
+	MsgInvalidIDOf:           "Invalid %s ID",
+	MsgInvalidIDValue:        "Invalid %s ID: %s",
+	MsgInvalidTime:           "Invalid time format: %s",
+	MsgExportFormat:          "Unsupported export format: %s",
+	MsgVersionMismatchAt:     "Record version mismatch, please refresh and retry (current version %d)",
+	MsgNotLoggedIn:           "Not logged in",
+	MsgInvalidUserIDClaim:    "Malformed user ID",
+	MsgPermissionCheckFailed: "Permission check failed",
+	MsgTokenFailed:           "Failed to generate token",
+	MsgPasswordHashFailed:    "Failed to hash password",
+
+	// 删除仍被引用的记录
+	MsgRoleInUse:       "Role is still assigned to %d users, use force=true to delete it and unassign them",
+	MsgPermissionInUse: "Permission is still used by %d roles, use force=true to delete it and remove it from them",
+
+	// 批量操作
+	MsgBatchRolledBack:  "Batch failed, all operations were rolled back",
+	MsgBatchModeUnknown: "Unknown batch mode: %s (expected atomic/best_effort)",
+	MsgBatchCount:       "Number of operations must be 1-%d, got %d",
+	MsgBatchIDRequired:  "%s operation requires an id",
+	MsgBatchOpUnknown:   "Unknown operation: %q (expected create/update/delete)",
+
+	// 用户导入
+	MsgImportFileRequired:     "Please upload an import file (file field, up to 10MB)",
+	MsgImportFileReadFailed:   "Failed to read the import file",
+	MsgImportFormat:           "Unsupported file format %s, only csv and xlsx are supported",
+	MsgImportFileEmpty:        "Unable to parse the import file: file is empty",
+	MsgImportFileParse:        "Unable to parse the import file: %v",
+	MsgImportTooManyRows:      "Unable to parse the import file: more than %d data rows",
+	MsgImportNoRows:           "Unable to parse the import file: no data rows",
+	MsgImportNoSheet:          "Unable to parse the import file: no worksheet found",
+	MsgImportHeaderDuplicate:  "Unable to parse the import file: duplicate column %s in header",
+	MsgImportHeaderMissing:    "Unable to parse the import file: header is missing columns %s",
+	MsgImportRowFailed:        "Import failed, all changes were rolled back: row %d: %v",
+	MsgImportAssignFailed:     "Import failed, all changes were rolled back: failed to assign roles: %v",
+	MsgImportNameRequired:     "Name is required",
+	MsgImportNameTooLong:      "Name must not exceed 100 characters",
+	MsgImportEmailRequired:    "Email is required",
+	MsgImportEmailInvalid:     "Invalid email: %s",
+	MsgImportEmailDuplicate:   "Email duplicates row %d",
+	MsgImportEmailTaken:       "Email already exists",
+	MsgImportPasswordRequired: "Password is required",
+	MsgImportPasswordTooShort: "Password must be at least 6 characters",
+	MsgImportRoleNotFound:     "Role not found: %s",
+
+	// 审计回滚
+	MsgRevertTable:         "This audit log cannot be reverted: records in table %s are not supported",
+	MsgRevertAction:        "This audit log cannot be reverted: only update and delete can be reverted",
+	MsgRevertNoSnapshot:    "This audit log cannot be reverted: no old value snapshot",
+	MsgRevertBadSnapshot:   "This audit log cannot be reverted: failed to parse the old value snapshot: %v",
+	MsgRevertPurged:        "This audit log cannot be reverted: the record has been permanently deleted",
+	MsgRevertNotDeleted:    "This audit log cannot be reverted: the record is not deleted",
+	MsgRevertDeleted:       "This audit log cannot be reverted: the record is deleted, revert the delete first",
+	MsgRevertNoFields:      "This audit log cannot be reverted: the snapshot has no restorable fields",
+	MsgRevertValueTaken:    "Reverted data conflicts with an existing record: %s %v is used by another record",
+	MsgRevertLinkAction:    "This audit log cannot be reverted: only removed links (purge) can be reverted for join tables",
+	MsgRevertLinkTarget:    "This audit log cannot be reverted: linked %s %d does not exist or is deleted",
+	MsgRevertLinkExists:    "Reverted data conflicts with an existing record: the link already exists",
+	MsgFieldEmail:          "email",
+	MsgFieldRoleName:       "role name",
+	MsgFieldPermissionName: "permission name",
+
+	// 列表查询参数
+	MsgQueryInvalidCursor:             "Invalid query parameters: invalid cursor",
+	MsgQueryCursorSort:                "Invalid query parameters: cursor does not match the current sort, keep sort unchanged when paging",
+	MsgQueryPage:                      "Invalid query parameters: page must be a positive integer, got %q",
+	MsgQueryPageSize:                  "Invalid query parameters: page_size must be a positive integer, got %q",
+	MsgQueryPageWithCursor:            "Invalid query parameters: page and cursor cannot be used together",
+	MsgQueryFilterSyntax:              "Invalid query parameters: %s (expected filter[field] or filter[field][operator])",
+	MsgQueryEmptySort:                 "Invalid query parameters: empty field in sort",
+	MsgQuerySearch:                    "Invalid query parameters: keyword search q is not supported",
+	MsgQuerySort:                      "Invalid query parameters: sorting by %s is not supported",
+	MsgQueryFilter:                    "Invalid query parameters: filtering by %s is not supported",
+	MsgQueryOperator:                  "Invalid query parameters: field %s does not support operator %s (expected %s)",
+	MsgQueryInValues:                  "Invalid query parameters: filter[%s][in] accepts at most %d values",
+	MsgQueryIntValue:                  "Invalid query parameters: filter[%s] must be an integer, got %q",
+	MsgQueryTimeValue:                 "Invalid query parameters: filter[%s] must be an RFC3339 or 2006-01-02 time, got %q",
+	MsgQueryIncludeDeleted:            "Invalid query parameters: include_deleted must be true, false or only, got %q",
+	MsgQueryIncludeDeletedUnsupported: "Invalid query parameters: include_deleted is not supported by this list",
+	MsgQueryProjectionSyntax:          "Invalid query parameters: malformed name %q in fields/include",
+	MsgQueryField:                     "Invalid query parameters: field %s is not supported (expected %s)",
+	MsgQueryInclude:                   "Invalid query parameters: include %s is not supported (expected %s)",
+
+	// 请求体校验规则
+	MsgRuleRequired:  "is required",
+	MsgRuleEmail:     "must be a valid email address",
+	MsgRuleURL:       "must be a valid URL",
+	MsgRuleOneOf:     "must be one of: %s",
+	MsgRuleMinLength: "must be at least %s characters",
+	MsgRuleMinItems:  "must contain at least %s items",
+	MsgRuleMin:       "must be at least %s",
+	MsgRuleMaxLength: "must be at most %s characters",
+	MsgRuleMaxItems:  "must contain at most %s items",
+	MsgRuleMax:       "must be at most %s",
+	MsgRuleGreater:   "must be greater than %s",
+	MsgRuleLess:      "must be less than %s",
+	MsgRuleLength:    "must be exactly %s characters",
+	MsgRuleItems:     "must contain exactly %s items",
+	MsgRuleOther:     "failed the %s validation",
+	MsgRuleType:      "must be %s",
+	MsgRuleNotNull:   "must not be null",
+	MsgRuleReadOnly:  "does not exist or cannot be modified",
+	MsgTypeString:    "a string",
+	MsgTypeBool:      "a boolean",
+	MsgTypeInteger:   "an integer",
+	MsgTypeNumber:    "a number",
+	MsgTypeArray:     "an array",
+	MsgTypeObject:    "an object",
+}
@@ -0,0 +1,132 @@
+// Package i18n 接口提示信息的多语言支持
+//
+// 提示信息按消息码（错误码或 keys.go 中的消息码）在各语言的消息表中查找，找不到时回退到默认语言（中文），
+// 请求的语言由中间件根据用户偏好或 Accept-Language 协商后保存在 context 中
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 支持的语言
+const (
+	ZH = "zh"
+	EN = "en"
+
+	Default = ZH // 默认语言，没有偏好或无法协商时使用
+)
+
+// catalogs 语言 -> 消息码 -> 消息模板（fmt 格式）
+var catalogs = map[string]map[string]string{
+	ZH: zh,
+	EN: en,
+}
+
+// Localizer 可以按语言输出提示信息的值，作为消息参数时会按同一语言翻译
+type Localizer interface {
+	Localize(lang string) string
+}
+
+// Message 待翻译的提示信息
+type Message struct {
+	Key  string        // 消息码
+	Args []interface{} // 消息模板的参数，实现 Localizer 的参数按同一语言翻译
+}
+
+// M 创建待翻译的提示信息
+func M(key string, args ...interface{}) Message {
+	return Message{Key: key, Args: args}
+}
+
+// Localize 按语言输出提示信息
+func (m Message) Localize(lang string) string {
+	return T(lang, m.Key, m.Args...)
+}
+
+// T 按语言翻译消息码，lang 不支持或消息表中没有该消息码时使用默认语言，都没有时原样返回消息码
+func T(lang, key string, args ...interface{}) string {
+	format, ok := catalogs[lang][key]
+	if !ok {
+		if format, ok = catalogs[Default][key]; !ok {
+			format = key
+		}
+	}
+	if len(args) == 0 {
+		return format
+	}
+
+	localized := make([]interface{}, len(args))
+	for i, arg := range args {
+		if l, ok := arg.(Localizer); ok {
+			localized[i] = l.Localize(lang)
+		} else {
+			localized[i] = arg
+		}
+	}
+	return fmt.Sprintf(format, localized...)
+}
+
+// Supported 返回 lang 对应的受支持语言，只比较主语言标签（如 en-US -> en），不支持时返回 false
+func Supported(lang string) (string, bool) {
+	primary := strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(primary, "-_"); i >= 0 {
+		primary = primary[:i]
+	}
+	if _, ok := catalogs[primary]; ok {
+		return primary, true
+	}
+	return "", false
+}
+
+// Negotiate 根据 Accept-Language 选择语言：按 q 值从高到低取第一个受支持的语言，q=0 的语言不使用，都不支持时返回默认语言
+func Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if tag != "" && q > 0 {
+			candidates = append(candidates, candidate{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, c := range candidates {
+		if c.tag == "*" {
+			return Default
+		}
+		if lang, ok := Supported(c.tag); ok {
+			return lang
+		}
+	}
+	return Default
+}
+
+type langKey struct{}
+
+// WithLang 将请求的语言保存到 context
+func WithLang(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, langKey{}, lang)
+}
+
+// FromContext 返回 context 中的语言，没有时返回默认语言
+func FromContext(ctx context.Context) string {
+	if lang, ok := ctx.Value(langKey{}).(string); ok && lang != "" {
+		return lang
+	}
+	return Default
+}
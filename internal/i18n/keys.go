@@ -0,0 +1,174 @@
+package i18n
+
+// 错误的默认提示信息以错误码（apperr.Error.Code）作为消息码，这里只定义成功提示和错误的补充说明
+
+// 成功提示
+const (
+	MsgOK                      = "OK"
+	MsgCreated                 = "CREATED"
+	MsgServiceHealthy          = "SERVICE_HEALTHY"
+	MsgUserCreated             = "USER_CREATED"
+	MsgUserUpdated             = "USER_UPDATED"
+	MsgUserDeleted             = "USER_DELETED"
+	MsgUserRestored            = "USER_RESTORED"
+	MsgUserPurged              = "USER_PURGED"
+	MsgRoleCreated             = "ROLE_CREATED"
+	MsgRoleUpdated             = "ROLE_UPDATED"
+	MsgRoleDeleted             = "ROLE_DELETED"
+	MsgRoleRestored            = "ROLE_RESTORED"
+	MsgRolePurged              = "ROLE_PURGED"
+	MsgRolePermissionsAssigned = "ROLE_PERMISSIONS_ASSIGNED"
+	MsgRolePermissionsRemoved  = "ROLE_PERMISSIONS_REMOVED"
+	MsgRoleUsersAssigned       = "ROLE_USERS_ASSIGNED"
+	MsgRoleUsersRemoved        = "ROLE_USERS_REMOVED"
+	MsgPermissionCreated       = "PERMISSION_CREATED"
+	MsgPermissionUpdated       = "PERMISSION_UPDATED"
+	MsgPermissionDeleted       = "PERMISSION_DELETED"
+	MsgPermissionRestored      = "PERMISSION_RESTORED"
+	MsgPermissionPurged        = "PERMISSION_PURGED"
+	MsgBatchSucceeded          = "BATCH_SUCCEEDED"
+	MsgBatchPartiallyFailed    = "BATCH_PARTIALLY_FAILED"
+	MsgAuditLogReverted        = "AUDIT_LOG_REVERTED"
+	MsgUserImportValidated     = "USER_IMPORT_VALIDATED"
+	MsgUsersImported           = "USERS_IMPORTED" // 参数：导入数量
+)
+
+// 资源名称，作为其他消息的参数
+const (
+	MsgResourceUser       = "RESOURCE_USER"
+	MsgResourceRole       = "RESOURCE_ROLE"
+	MsgResourcePermission = "RESOURCE_PERMISSION"
+	MsgResourceAuditLog   = "RESOURCE_AUDIT_LOG"
+	MsgResourceRecord     = "RESOURCE_RECORD"
+)
+
+// 请求与认证
+const (
+	MsgRouteNotFound         = "ROUTE_NOT_FOUND"
+	MsgBodyInvalidJSON       = "BODY_INVALID_JSON"
+	MsgBodyEmpty             = "BODY_EMPTY"
+	MsgBodyTooLarge          = "BODY_TOO_LARGE" // 参数：字节数
+	MsgBodyReadFailed        = "BODY_READ_FAILED"
+	MsgBodyNotObject         = "BODY_NOT_OBJECT"
+	MsgPatchContentType      = "PATCH_CONTENT_TYPE"
+	MsgInvalidIDOf           = "INVALID_ID_OF"       // 参数：资源名称
+	MsgInvalidIDValue        = "INVALID_ID_VALUE"    // 参数：资源名称、参数值
+	MsgInvalidTime           = "INVALID_TIME"        // 参数：参数值
+	MsgExportFormat          = "EXPORT_FORMAT"       // 参数：格式
+	MsgVersionMismatchAt     = "VERSION_MISMATCH_AT" // 参数：当前版本号
+	MsgNotLoggedIn           = "NOT_LOGGED_IN"
+	MsgInvalidUserIDClaim    = "INVALID_USER_ID_CLAIM"
+	MsgPermissionCheckFailed = "PERMISSION_CHECK_FAILED"
+	MsgTokenFailed           = "TOKEN_FAILED"
+	MsgPasswordHashFailed    = "PASSWORD_HASH_FAILED"
+)
+
+// 删除仍被引用的记录
+const (
+	MsgRoleInUse       = "ROLE_IN_USE_COUNT"       // 参数：用户数
+	MsgPermissionInUse = "PERMISSION_IN_USE_COUNT" // 参数：角色数
+)
+
+// 批量操作
+const (
+	MsgBatchRolledBack  = "BATCH_ROLLED_BACK"
+	MsgBatchModeUnknown = "BATCH_MODE_UNKNOWN" // 参数：模式
+	MsgBatchCount       = "BATCH_COUNT"        // 参数：上限、当前数量
+	MsgBatchIDRequired  = "BATCH_ID_REQUIRED"  // 参数：操作类型
+	MsgBatchOpUnknown   = "BATCH_OP_UNKNOWN"   // 参数：操作类型
+)
+
+// 用户导入
+const (
+	MsgImportFileRequired     = "IMPORT_FILE_REQUIRED"
+	MsgImportFileReadFailed   = "IMPORT_FILE_READ_FAILED"
+	MsgImportFormat           = "IMPORT_FORMAT" // 参数：格式
+	MsgImportFileEmpty        = "IMPORT_FILE_EMPTY"
+	MsgImportFileParse        = "IMPORT_FILE_PARSE"    // 参数：解析错误
+	MsgImportTooManyRows      = "IMPORT_TOO_MANY_ROWS" // 参数：行数上限
+	MsgImportNoRows           = "IMPORT_NO_ROWS"
+	MsgImportNoSheet          = "IMPORT_NO_SHEET"
+	MsgImportHeaderDuplicate  = "IMPORT_HEADER_DUPLICATE" // 参数：列名
+	MsgImportHeaderMissing    = "IMPORT_HEADER_MISSING"   // 参数：列名
+	MsgImportRowFailed        = "IMPORT_ROW_FAILED"       // 参数：行号、错误
+	MsgImportAssignFailed     = "IMPORT_ASSIGN_FAILED"    // 参数：错误
+	MsgImportNameRequired     = "IMPORT_NAME_REQUIRED"
+	MsgImportNameTooLong      = "IMPORT_NAME_TOO_LONG"
+	MsgImportEmailRequired    = "IMPORT_EMAIL_REQUIRED"
+	MsgImportEmailInvalid     = "IMPORT_EMAIL_INVALID"   // 参数：邮箱
+	MsgImportEmailDuplicate   = "IMPORT_EMAIL_DUPLICATE" // 参数：首次出现的行号
+	MsgImportEmailTaken       = "IMPORT_EMAIL_TAKEN"
+	MsgImportPasswordRequired = "IMPORT_PASSWORD_REQUIRED"
+	MsgImportPasswordTooShort = "IMPORT_PASSWORD_TOO_SHORT"
+	MsgImportRoleNotFound     = "IMPORT_ROLE_NOT_FOUND" // 参数：角色名称
+)
+
+// 审计回滚
+const (
+	MsgRevertTable         = "REVERT_TABLE" // 参数：表名
+	MsgRevertAction        = "REVERT_ACTION"
+	MsgRevertNoSnapshot    = "REVERT_NO_SNAPSHOT"
+	MsgRevertBadSnapshot   = "REVERT_BAD_SNAPSHOT" // 参数：解析错误
+	MsgRevertPurged        = "REVERT_PURGED"
+	MsgRevertNotDeleted    = "REVERT_NOT_DELETED"
+	MsgRevertDeleted       = "REVERT_DELETED"
+	MsgRevertNoFields      = "REVERT_NO_FIELDS"
+	MsgRevertValueTaken    = "REVERT_VALUE_TAKEN" // 参数：字段名称、值
+	MsgRevertLinkAction    = "REVERT_LINK_ACTION"
+	MsgRevertLinkTarget    = "REVERT_LINK_TARGET" // 参数：资源名称、ID
+	MsgRevertLinkExists    = "REVERT_LINK_EXISTS"
+	MsgFieldEmail          = "FIELD_EMAIL"
+	MsgFieldRoleName       = "FIELD_ROLE_NAME"
+	MsgFieldPermissionName = "FIELD_PERMISSION_NAME"
+)
+
+// 列表查询参数
+const (
+	MsgQueryInvalidCursor             = "QUERY_INVALID_CURSOR"
+	MsgQueryCursorSort                = "QUERY_CURSOR_SORT"
+	MsgQueryPage                      = "QUERY_PAGE"      // 参数：参数值
+	MsgQueryPageSize                  = "QUERY_PAGE_SIZE" // 参数：参数值
+	MsgQueryPageWithCursor            = "QUERY_PAGE_WITH_CURSOR"
+	MsgQueryFilterSyntax              = "QUERY_FILTER_SYNTAX" // 参数：参数名
+	MsgQueryEmptySort                 = "QUERY_EMPTY_SORT"
+	MsgQuerySearch                    = "QUERY_SEARCH"
+	MsgQuerySort                      = "QUERY_SORT"            // 参数：字段
+	MsgQueryFilter                    = "QUERY_FILTER"          // 参数：字段
+	MsgQueryOperator                  = "QUERY_OPERATOR"        // 参数：字段、操作符、可选操作符
+	MsgQueryInValues                  = "QUERY_IN_VALUES"       // 参数：字段、上限
+	MsgQueryIntValue                  = "QUERY_INT_VALUE"       // 参数：字段、参数值
+	MsgQueryTimeValue                 = "QUERY_TIME_VALUE"      // 参数：字段、参数值
+	MsgQueryIncludeDeleted            = "QUERY_INCLUDE_DELETED" // 参数：参数值
+	MsgQueryIncludeDeletedUnsupported = "QUERY_INCLUDE_DELETED_UNSUPPORTED"
+	MsgQueryProjectionSyntax          = "QUERY_PROJECTION_SYNTAX" // 参数：名称
+	MsgQueryField                     = "QUERY_FIELD"             // 参数：字段、可选的字段
+	MsgQueryInclude                   = "QUERY_INCLUDE"           // 参数：关联、可选的关联
+)
+
+// 请求体校验规则，参数为规则的参数（如 min=6 中的 6）
+const (
+	MsgRuleRequired  = "RULE_REQUIRED"
+	MsgRuleEmail     = "RULE_EMAIL"
+	MsgRuleURL       = "RULE_URL"
+	MsgRuleOneOf     = "RULE_ONEOF"
+	MsgRuleMinLength = "RULE_MIN_LENGTH"
+	MsgRuleMinItems  = "RULE_MIN_ITEMS"
+	MsgRuleMin       = "RULE_MIN"
+	MsgRuleMaxLength = "RULE_MAX_LENGTH"
+	MsgRuleMaxItems  = "RULE_MAX_ITEMS"
+	MsgRuleMax       = "RULE_MAX"
+	MsgRuleGreater   = "RULE_GT"
+	MsgRuleLess      = "RULE_LT"
+	MsgRuleLength    = "RULE_LEN"
+	MsgRuleItems     = "RULE_LEN_ITEMS"
+	MsgRuleOther     = "RULE_OTHER" // 参数：规则名称
+	MsgRuleType      = "RULE_TYPE"  // 参数：类型名称
+	MsgRuleNotNull   = "RULE_NOT_NULL"
+	MsgRuleReadOnly  = "RULE_READ_ONLY"
+	MsgTypeString    = "TYPE_STRING"
+	MsgTypeBool      = "TYPE_BOOL"
+	MsgTypeInteger   = "TYPE_INTEGER"
+	MsgTypeNumber    = "TYPE_NUMBER"
+	MsgTypeArray     = "TYPE_ARRAY"
+	MsgTypeObject    = "TYPE_OBJECT"
+)
@@ -0,0 +1,194 @@
+package i18n
+
+// zh 中文消息表（默认语言），新增消息码时必须在这里添加
+var zh = map[string]string{
+	// 错误码（apperr）
+	"BAD_REQUEST":                "请求参数错误",
+	"VALIDATION_FAILED":          "请求参数校验失败",
+	"INVALID_ID":                 "无效的ID",
+	"INVALID_QUERY":              "无效的查询参数",
+	"INVALID_IF_MATCH":           "无效的 If-Match，应为 GET 返回的 ETag，如 \"3\"",
+	"UNSUPPORTED_FORMAT":         "不支持的文件格式",
+	"UNAUTHORIZED":               "未授权，请先登录",
+	"PERMISSION_DENIED":          "权限不足，禁止访问",
+	"NOT_FOUND":                  "资源不存在",
+	"VERSION_CONFLICT":           "记录已被其他请求修改，请刷新后重试",
+	"VERSION_MISMATCH":           "记录版本不一致，请刷新后重试",
+	"RECORD_NOT_DELETED":         "记录未被删除",
+	"DUPLICATE_RECORD":           "记录与现有记录冲突",
+	"REQUEST_TOO_LARGE":          "请求体过大",
+	"UNSUPPORTED_MEDIA_TYPE":     "不支持的请求体类型",
+	"RATE_LIMITED":               "请求过于频繁，请稍后再试",
+	"INTERNAL_ERROR":             "服务器内部错误",
+	"INVALID_CREDENTIALS":        "邮箱或密码错误",
+	"USER_DISABLED":              "用户已被禁用",
+	"USER_NOT_FOUND":             "用户不存在",
+	"USER_EMAIL_TAKEN":           "邮箱已存在",
+	"ROLE_NOT_FOUND":             "角色不存在",
+	"ROLE_IN_USE":                "角色仍有用户，不能删除",
+	"ROLE_NAME_TAKEN":            "角色名称已存在",
+	"PERMISSION_NOT_FOUND":       "权限不存在",
+	"PERMISSION_IN_USE":          "权限仍被角色使用，不能删除",
+	"PERMISSION_NAME_TAKEN":      "权限名称已存在",
+	"BATCH_ABORTED":              "批量操作中有其他操作失败，本操作已回滚或未执行",
+	"IMPORT_FILE_INVALID":        "无法解析导入文件",
+	"IMPORT_ROWS_INVALID":        "导入数据校验未通过，未导入任何用户",
+	"IMPORT_FAILED":              "导入失败，已全部回滚",
+	"AUDIT_LOG_NOT_FOUND":        "审计记录不存在",
+	"REQUEST_AUDIT_NOT_FOUND":    "请求审计记录不存在",
+	"AUDIT_REVERT_NOT_SUPPORTED": "该审计记录不支持回滚",
+	"AUDIT_REVERT_CONFLICT":      "回滚数据与现有记录冲突",
+
+	// 成功提示
+	MsgOK:                      "操作成功",
+	MsgCreated:                 "创建成功",
+	MsgServiceHealthy:          "服务运行正常",
+	MsgUserCreated:             "用户创建成功",
+	MsgUserUpdated:             "用户更新成功",
+	MsgUserDeleted:             "用户删除成功",
+	MsgUserRestored:            "用户已恢复",
+	MsgUserPurged:              "用户已彻底删除",
+	MsgRoleCreated:             "角色创建成功",
+	MsgRoleUpdated:             "角色更新成功",
+	MsgRoleDeleted:             "角色删除成功",
+	MsgRoleRestored:            "角色已恢复",
+	MsgRolePurged:              "角色已彻底删除",
+	MsgRolePermissionsAssigned: "权限分配成功",
+	MsgRolePermissionsRemoved:  "权限移除成功",
+	MsgRoleUsersAssigned:       "用户分配成功",
+	MsgRoleUsersRemoved:        "用户移除成功",
+	MsgPermissionCreated:       "权限创建成功",
+	MsgPermissionUpdated:       "权限更新成功",
+	MsgPermissionDeleted:       "权限删除成功",
+	MsgPermissionRestored:      "权限已恢复",
+	MsgPermissionPurged:        "权限已彻底删除",
+	MsgBatchSucceeded:          "批量操作成功",
+	MsgBatchPartiallyFailed:    "批量操作完成，部分操作失败",
+	MsgAuditLogReverted:        "回滚成功",
+	MsgUserImportValidated:     "校验通过，可以导入",
+	MsgUsersImported:           "成功导入 %d 个用户",
+
+	// 资源名称
+	MsgResourceUser:       "用户",
+	MsgResourceRole:       "角色",
+	MsgResourcePermission: "权限",
+	MsgResourceAuditLog:   "审计日志",
+	MsgResourceRecord:     "记录",
+
+	// 请求与认证
+	MsgRouteNotFound:         "接口不存在",
+	MsgBodyInvalidJSON:       "请求体不是有效的 JSON",
+	MsgBodyEmpty:             "请求体不能为空",
+	MsgBodyTooLarge:          "请求体不能超过 %d 字节",
+	MsgBodyReadFailed:        "读取请求体失败",
+	MsgBodyNotObject:         "请求体必须是 JSON 对象",
+	MsgPatchContentType:      "Content-Type 应为 application/merge-patch+json 或 application/json",
+	MsgInvalidIDOf:           "无效的%sID",
+	MsgInvalidIDValue:        "无效的%sID: %s",
+	MsgInvalidTime:           "无效的时间格式: %s",
+	MsgExportFormat:          "不支持的导出格式: %s",
+	MsgVersionMismatchAt:     "记录版本不一致，请刷新后重试（当前版本 %d）",
+	MsgNotLoggedIn:           "未登录",
+	MsgInvalidUserIDClaim:    "用户ID格式错误",
+	MsgPermissionCheckFailed: "权限检查失败",
+	MsgTokenFailed:           "生成 token 失败",
+	MsgPasswordHashFailed:    "密码加密失败",
+
+	// 删除仍被引用的记录
+	MsgRoleInUse:       "角色仍被 %d 个用户使用，确认删除并解除这些用户的角色请使用 force=true",
+	MsgPermissionInUse: "权限仍被 %d 个角色使用，确认删除并从这些角色中移除请使用 force=true",
+
+	// 批量操作
+	MsgBatchRolledBack:  "批量操作失败，已全部回滚",
+	MsgBatchModeUnknown: "未知的批量操作模式: %s（可选 atomic/best_effort）",
+	MsgBatchCount:       "操作数量应为 1-%d，当前为 %d",
+	MsgBatchIDRequired:  "%s 操作缺少 id",
+	MsgBatchOpUnknown:   "未知的操作类型: %q（可选 create/update/delete）",
+
+	// 用户导入
+	MsgImportFileRequired:     "请上传导入文件（file 字段，最大 10MB）",
+	MsgImportFileReadFailed:   "读取导入文件失败",
+	MsgImportFormat:           "不支持的文件格式 %s，仅支持 csv 和 xlsx",
+	MsgImportFileEmpty:        "无法解析导入文件: 文件为空",
+	MsgImportFileParse:        "无法解析导入文件: %v",
+	MsgImportTooManyRows:      "无法解析导入文件: 数据行数超过上限 %d",
+	MsgImportNoRows:           "无法解析导入文件: 没有数据行",
+	MsgImportNoSheet:          "无法解析导入文件: 文件中没有工作表",
+	MsgImportHeaderDuplicate:  "无法解析导入文件: 表头中 %s 列重复",
+	MsgImportHeaderMissing:    "无法解析导入文件: 表头缺少 %s 列",
+	MsgImportRowFailed:        "导入失败，已全部回滚: 第 %d 行: %v",
+	MsgImportAssignFailed:     "导入失败，已全部回滚: 分配角色失败: %v",
+	MsgImportNameRequired:     "姓名不能为空",
+	MsgImportNameTooLong:      "姓名不能超过 100 个字符",
+	MsgImportEmailRequired:    "邮箱不能为空",
+	MsgImportEmailInvalid:     "邮箱格式不正确: %s",
+	MsgImportEmailDuplicate:   "邮箱与第 %d 行重复",
+	MsgImportEmailTaken:       "邮箱已存在",
+	MsgImportPasswordRequired: "密码不能为空",
+	MsgImportPasswordTooShort: "密码至少 6 位",
+	MsgImportRoleNotFound:     "角色不存在: %s",
+
+	// 审计回滚
+	MsgRevertTable:         "该审计记录不支持回滚: 不支持回滚 %s 表的记录",
+	MsgRevertAction:        "该审计记录不支持回滚: 仅支持回滚 update 和 delete 操作",
+	MsgRevertNoSnapshot:    "该审计记录不支持回滚: 审计记录中没有旧值快照",
+	MsgRevertBadSnapshot:   "该审计记录不支持回滚: 解析旧值快照失败: %v",
+	MsgRevertPurged:        "该审计记录不支持回滚: 记录已被彻底删除，无法恢复",
+	MsgRevertNotDeleted:    "该审计记录不支持回滚: 记录未处于删除状态",
+	MsgRevertDeleted:       "该审计记录不支持回滚: 记录已被删除，请先回滚删除操作",
+	MsgRevertNoFields:      "该审计记录不支持回滚: 旧值快照中没有可恢复的字段",
+	MsgRevertValueTaken:    "回滚数据与现有记录冲突: %s %v 已被其他记录使用",
+	MsgRevertLinkAction:    "该审计记录不支持回滚: 关联表仅支持回滚解除关联（purge）操作",
+	MsgRevertLinkTarget:    "该审计记录不支持回滚: 关联的%s %d 不存在或已删除",
+	MsgRevertLinkExists:    "回滚数据与现有记录冲突: 关联已存在",
+	MsgFieldEmail:          "邮箱",
+	MsgFieldRoleName:       "角色名称",
+	MsgFieldPermissionName: "权限名称",
+
+	// 列表查询参数
+	MsgQueryInvalidCursor:             "无效的查询参数: 无效的 cursor",
+	MsgQueryCursorSort:                "无效的查询参数: cursor 与当前排序不一致，翻页时请保持 sort 参数不变",
+	MsgQueryPage:                      "无效的查询参数: page 应为正整数，当前为 %q",
+	MsgQueryPageSize:                  "无效的查询参数: page_size 应为正整数，当前为 %q",
+	MsgQueryPageWithCursor:            "无效的查询参数: page 和 cursor 不能同时使用",
+	MsgQueryFilterSyntax:              "无效的查询参数: %s（应为 filter[字段] 或 filter[字段][操作符]）",
+	MsgQueryEmptySort:                 "无效的查询参数: sort 中有空的排序字段",
+	MsgQuerySearch:                    "无效的查询参数: 不支持关键字搜索 q",
+	MsgQuerySort:                      "无效的查询参数: 不支持按 %s 排序",
+	MsgQueryFilter:                    "无效的查询参数: 不支持按 %s 过滤",
+	MsgQueryOperator:                  "无效的查询参数: 字段 %s 不支持操作符 %s（可选 %s）",
+	MsgQueryInValues:                  "无效的查询参数: filter[%s][in] 最多 %d 个值",
+	MsgQueryIntValue:                  "无效的查询参数: filter[%s] 应为整数，当前为 %q",
+	MsgQueryTimeValue:                 "无效的查询参数: filter[%s] 应为 RFC3339 或 2006-01-02 格式的时间，当前为 %q",
+	MsgQueryIncludeDeleted:            "无效的查询参数: include_deleted 应为 true、false 或 only，当前为 %q",
+	MsgQueryIncludeDeletedUnsupported: "无效的查询参数: 该列表不支持 include_deleted",
+	MsgQueryProjectionSyntax:          "无效的查询参数: fields/include 中的名称 %q 格式错误",
+	MsgQueryField:                     "无效的查询参数: 不支持返回字段 %s（可选 %s）",
+	MsgQueryInclude:                   "无效的查询参数: 不支持展开关联 %s（可选 %s）",
+
+	// 请求体校验规则
+	MsgRuleRequired:  "不能为空",
+	MsgRuleEmail:     "邮箱格式不正确",
+	MsgRuleURL:       "URL 格式不正确",
+	MsgRuleOneOf:     "必须是以下值之一: %s",
+	MsgRuleMinLength: "长度不能少于 %s 个字符",
+	MsgRuleMinItems:  "至少需要 %s 项",
+	MsgRuleMin:       "不能小于 %s",
+	MsgRuleMaxLength: "长度不能超过 %s 个字符",
+	MsgRuleMaxItems:  "最多 %s 项",
+	MsgRuleMax:       "不能大于 %s",
+	MsgRuleGreater:   "必须大于 %s",
+	MsgRuleLess:      "必须小于 %s",
+	MsgRuleLength:    "长度必须为 %s 个字符",
+	MsgRuleItems:     "必须为 %s 项",
+	MsgRuleOther:     "未通过 %s 校验",
+	MsgRuleType:      "类型应为 %s",
+	MsgRuleNotNull:   "不能为 null",
+	MsgRuleReadOnly:  "不存在或不允许修改",
+	MsgTypeString:    "字符串",
+	MsgTypeBool:      "布尔值",
+	MsgTypeInteger:   "整数",
+	MsgTypeNumber:    "数字",
+	MsgTypeArray:     "数组",
+	MsgTypeObject:    "对象",
+}
@@ -28,26 +28,10 @@ func NewAuditLogger(cfg *config.Config) *Logger {
 
 // newLoggerWithFile 创建指定文件路径的Logger
 func newLoggerWithFile(cfg *config.Config, filePath, defaultPath string) *Logger {
-	log := logrus.New()
+	log := &Logger{Logger: logrus.New()}
 
-	// 设置日志级别
-	level, err := logrus.ParseLevel(cfg.Log.Level)
-	if err != nil {
-		level = logrus.InfoLevel
-	}
-	log.SetLevel(level)
-
-	// 设置日志格式
-	if cfg.Log.Format == "json" {
-		log.SetFormatter(&logrus.JSONFormatter{
-			TimestampFormat: "2006-01-02 15:04:05",
-		})
-	} else {
-		log.SetFormatter(&logrus.TextFormatter{
-			FullTimestamp:   true,
-			TimestampFormat: "2006-01-02 15:04:05",
-		})
-	}
+	// 设置日志级别和格式
+	log.ApplyConfig(cfg)
 
 	// 设置输出
 	output := strings.ToLower(cfg.Log.Output)
@@ -84,7 +68,27 @@ func newLoggerWithFile(cfg *config.Config, filePath, defaultPath string) *Logger
 
 	log.SetOutput(io.MultiWriter(writers...))
 
-	return &Logger{Logger: log}
+	return log
+}
+
+// ApplyConfig 应用日志级别和格式，配置热更新时由配置管理器调用
+func (l *Logger) ApplyConfig(cfg *config.Config) {
+	level, err := logrus.ParseLevel(cfg.Log.Level)
+	if err != nil {
+		level = logrus.InfoLevel
+	}
+	l.SetLevel(level)
+
+	if cfg.Log.Format == "json" {
+		l.SetFormatter(&logrus.JSONFormatter{
+			TimestampFormat: "2006-01-02 15:04:05",
+		})
+	} else {
+		l.SetFormatter(&logrus.TextFormatter{
+			FullTimestamp:   true,
+			TimestampFormat: "2006-01-02 15:04:05",
+		})
+	}
 }
 
 // 提供便捷方法
@@ -2,47 +2,120 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
+	"time"
 
 	"go_web/internal/database"
 	"go_web/internal/logger"
+	"go_web/internal/util"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 )
 
 // AuditMiddleware 审计中间件
-func AuditMiddleware(log *logger.Logger) gin.HandlerFunc {
+// 在处理器执行完成后记录 HTTP 审计事件：写入审计日志文件，并通过审计写入器持久化到 http_audit_events 表
+func AuditMiddleware(log *logger.Logger, auditWriter *database.AuditWriter) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 记录请求信息到审计日志
-		auditInfo := map[string]interface{}{
-			"method":     c.Request.Method,
-			"path":       c.Request.URL.Path,
-			"client_ip":  c.ClientIP(),
-			"user_agent": c.Request.UserAgent(),
+		start := time.Now()
+
+		// 将客户端IP和操作来源设置到 request context，供数据库审计插件使用（用户ID由认证中间件设置）
+		ctx := context.WithValue(c.Request.Context(), database.AuditIPKey, c.ClientIP())
+		ctx = context.WithValue(ctx, database.AuditActorKey, database.AuditActorHTTP)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		// 健康检查和文档页面不记录审计事件
+		// 按名称分发的路由（如 /api/v1/:action）由分发处理器设置解析后的 route
+		route := c.FullPath()
+		if resolved := c.GetString("route"); resolved != "" {
+			route = resolved
+		}
+		if route == "/health" || strings.HasPrefix(route, "/swagger") {
+			return
 		}
 
-		// 获取用户ID（如果有认证中间件设置）
-		var userID uint
-		if uid, exists := c.Get("user_id"); exists {
-			if id, ok := uid.(uint); ok {
-				userID = id
-				auditInfo["user_id"] = id
-			} else if id, ok := uid.(uint64); ok {
-				userID = uint(id)
-				auditInfo["user_id"] = id
-			} else if id, ok := uid.(int); ok && id > 0 {
-				userID = uint(id)
-				auditInfo["user_id"] = id
-			}
+		event := &database.HTTPAuditEvent{
+			RequestID:       c.GetString("request_id"),
+			ClientRequestID: c.GetString("client_request_id"),
+			UserID:          contextUserID(c),
+			UserEmail:       c.GetString("user_email"),
+			Method:          c.Request.Method,
+			Route:           route,
+			Path:            util.Truncate(c.Request.URL.Path, 255),
+			Params:          requestParams(c),
+			Status:          c.Writer.Status(),
+			DurationMs:      time.Since(start).Milliseconds(),
+			ClientIP:        c.ClientIP(),
+			UserAgent:       util.Truncate(c.Request.UserAgent(), 255),
+			Permission:      c.GetString("permission"),
+			Decision:        c.GetString("permission_decision"),
 		}
 
-		// 将审计信息设置到 request context，供数据库审计插件使用
-		ctx := c.Request.Context()
-		ctx = context.WithValue(ctx, database.AuditUserIDKey, userID)
-		ctx = context.WithValue(ctx, database.AuditIPKey, c.ClientIP())
-		c.Request = c.Request.WithContext(ctx)
+		log.WithFields(logrus.Fields{
+			"request_id":        event.RequestID,
+			"client_request_id": event.ClientRequestID,
+			"user_id":           event.UserID,
+			"method":            event.Method,
+			"route":             event.Route,
+			"path":              event.Path,
+			"status":            event.Status,
+			"duration_ms":       event.DurationMs,
+			"client_ip":         event.ClientIP,
+			"user_agent":        event.UserAgent,
+			"permission":        event.Permission,
+			"decision":          event.Decision,
+		}).Info("审计日志")
+
+		auditWriter.WriteHTTPEvent(event)
+	}
+}
 
-		log.WithFields(auditInfo).Info("审计日志")
+// contextUserID 获取用户ID（由认证中间件设置）
+func contextUserID(c *gin.Context) uint {
+	uid, exists := c.Get("user_id")
+	if !exists {
+		return 0
+	}
 
-		c.Next()
+	switch v := uid.(type) {
+	case uint:
+		return v
+	case uint64:
+		return uint(v)
+	case int:
+		if v > 0 {
+			return uint(v)
+		}
+	}
+	return 0
+}
+
+// requestParams 将路径参数和查询参数序列化为 JSON
+func requestParams(c *gin.Context) string {
+	params := map[string]interface{}{}
+
+	if len(c.Params) > 0 {
+		pathParams := make(map[string]string, len(c.Params))
+		for _, p := range c.Params {
+			pathParams[p.Key] = p.Value
+		}
+		params["path"] = pathParams
+	}
+
+	if query := c.Request.URL.Query(); len(query) > 0 {
+		params["query"] = query
+	}
+
+	if len(params) == 0 {
+		return ""
+	}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return ""
 	}
+	return string(data)
 }
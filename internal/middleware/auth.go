@@ -1,16 +1,20 @@
 package middleware
 
 import (
+	"context"
 	"strings"
 
 	"go_web/internal/config"
+	"go_web/internal/database"
 	"go_web/internal/util"
 
 	"github.com/gin-gonic/gin"
 )
 
 // JWTAuthMiddleware JWT 认证中间件
-func JWTAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+// 每次请求使用配置管理器中的最新密钥校验 token，JWT 密钥支持轮换，轮换前的旧密钥在一个 token 有效期内仍然有效
+func JWTAuthMiddleware(configManager *config.Manager) gin.HandlerFunc {
+	keyring := util.NewJWTKeyring(configManager)
 	return func(c *gin.Context) {
 		// 1. 从 Authorization header 获取 token
 		authHeader := c.GetHeader("Authorization")
@@ -23,6 +27,7 @@ func JWTAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 		// 格式：Authorization: Bearer <token>
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) != 2 || parts[0] != "Bearer" {
+			c.Set("token_error", "Authorization 头格式错误")
 			c.Next()
 			return
 		}
@@ -30,9 +35,11 @@ func JWTAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 		token := parts[1]
 
 		// 3. 解析 JWT token
-		claims, err := util.ParseToken(cfg, token)
+		claims, err := keyring.Parse(token)
 		if err != nil {
-			c.Next() // token 无效，让后续中间件处理
+			// token 无效，记录原因供安全事件中间件使用，让后续中间件处理
+			c.Set("token_error", "无效的 token: "+err.Error())
+			c.Next()
 			return
 		}
 
@@ -40,6 +47,10 @@ func JWTAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
 
+		// 5. 将用户ID设置到 request context，供数据库审计插件使用
+		ctx := context.WithValue(c.Request.Context(), database.AuditUserIDKey, claims.UserID)
+		c.Request = c.Request.WithContext(ctx)
+
 		c.Next()
 	}
 }
@@ -1,6 +1,17 @@
 package middleware
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"go_web/internal/apperr"
+	"go_web/internal/config"
+	"go_web/internal/database"
+	"go_web/internal/i18n"
 	"go_web/internal/service"
 	"go_web/internal/util"
 
@@ -12,45 +23,129 @@ import (
 // action: 操作类型，如 "create", "read", "update", "delete"
 func RequirePermission(userService service.UserService, resource, action string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 获取用户ID（从认证中间件获取）
-		userID, exists := c.Get("user_id")
-		if !exists {
-			util.Unauthorized(c, "未登录")
+		authorize(c, userService, resource, []string{action})
+	}
+}
+
+// batchOperationMaxBytes 批量接口每个操作允许的请求体大小，请求体上限为该值乘以 server.batch_max_operations
+const batchOperationMaxBytes = 4 << 10
+
+// RequireBatchPermission 批量接口的权限校验中间件
+// 按请求体中各操作的类型（create/update/delete）校验 resource 对应的权限，缺少任何一个都拒绝整个请求；
+// 请求体格式错误或没有可识别的操作类型时在鉴权前返回 400，超过大小上限时返回 413；
+// 部分操作类型未知时只按可识别的操作鉴权，未知的操作由处理器在该操作的结果中返回 400
+func RequireBatchPermission(configManager *config.Manager, userService service.UserService, resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 在鉴权前读取整个请求体，需要限制大小，避免未授权的请求占用大量内存
+		maxBytes := int64(configManager.Current().Server.BatchMaxOperations) * batchOperationMaxBytes
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				util.Fail(c, err)
+			} else {
+				util.Fail(c, apperr.ErrBadRequest.WithMessage(i18n.MsgBodyReadFailed).Wrap(err))
+			}
 			c.Abort()
 			return
 		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
 
-		// 转换用户ID类型
-		var uid uint
-		switch v := userID.(type) {
-		case uint:
-			uid = v
-		case uint64:
-			uid = uint(v)
-		case int:
-			if v > 0 {
-				uid = uint(v)
+		var req struct {
+			Operations []struct {
+				Op string `json:"op"`
+			} `json:"operations"`
+		}
+		if err := json.NewDecoder(bytes.NewReader(body)).Decode(&req); err != nil {
+			util.Fail(c, err)
+			c.Abort()
+			return
+		}
+
+		var actions []string
+		for _, action := range []string{service.BatchCreate, service.BatchUpdate, service.BatchDelete} {
+			for _, op := range req.Operations {
+				if op.Op == action {
+					actions = append(actions, action)
+					break
+				}
+			}
+		}
+		if len(actions) == 0 {
+			if len(req.Operations) == 0 {
+				util.Fail(c, apperr.ErrBadRequest.WithMessage(i18n.MsgBatchCount, configManager.Current().Server.BatchMaxOperations, 0))
+			} else {
+				util.Fail(c, apperr.ErrBadRequest.WithMessage(i18n.MsgBatchOpUnknown, req.Operations[0].Op))
 			}
-		default:
-			util.Unauthorized(c, "用户ID格式错误")
 			c.Abort()
 			return
 		}
+		authorize(c, userService, resource, actions)
+	}
+}
+
+// authorize 校验当前用户是否拥有 resource 的全部 actions 权限，不满足时中止请求
+func authorize(c *gin.Context, userService service.UserService, resource string, actions []string) {
+	// 没有需要校验的权限时拒绝，审计记录不能在未校验的情况下记为 allowed
+	if len(actions) == 0 {
+		c.Set("permission_decision", database.PermissionDecisionError)
+		util.Fail(c, apperr.ErrForbidden)
+		c.Abort()
+		return
+	}
+
+	// 记录校验的权限，供审计中间件使用
+	permissions := make([]string, 0, len(actions))
+	for _, action := range actions {
+		permissions = append(permissions, resource+":"+action)
+	}
+	c.Set("permission", strings.Join(permissions, ","))
 
-		// 检查权限
-		hasPermission, err := userService.HasPermission(uid, resource, action)
+	// 获取用户ID（从认证中间件获取）
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Set("permission_decision", database.PermissionDecisionUnauthenticated)
+		util.Fail(c, apperr.ErrUnauthorized.WithMessage(i18n.MsgNotLoggedIn))
+		c.Abort()
+		return
+	}
+
+	// 转换用户ID类型
+	var uid uint
+	switch v := userID.(type) {
+	case uint:
+		uid = v
+	case uint64:
+		uid = uint(v)
+	case int:
+		if v > 0 {
+			uid = uint(v)
+		}
+	default:
+		c.Set("permission_decision", database.PermissionDecisionUnauthenticated)
+		util.Fail(c, apperr.ErrUnauthorized.WithMessage(i18n.MsgInvalidUserIDClaim))
+		c.Abort()
+		return
+	}
+
+	// 检查权限
+	for _, action := range actions {
+		hasPermission, err := userService.HasPermission(c.Request.Context(), uid, resource, action)
 		if err != nil {
-			util.InternalServerError(c, "权限检查失败")
+			c.Set("permission_decision", database.PermissionDecisionError)
+			util.Fail(c, apperr.ErrInternal.WithMessage(i18n.MsgPermissionCheckFailed).Wrap(err))
 			c.Abort()
 			return
 		}
 
 		if !hasPermission {
-			util.Forbidden(c, "权限不足，禁止访问")
+			c.Set("permission_decision", database.PermissionDecisionDenied)
+			util.Fail(c, apperr.ErrForbidden)
 			c.Abort()
 			return
 		}
-
-		c.Next()
 	}
+
+	c.Set("permission_decision", database.PermissionDecisionAllowed)
+	c.Next()
 }
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go_web/internal/config"
+	"go_web/internal/database"
+	"go_web/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// stubUserService 记录权限校验的调用，授予全部权限
+type stubUserService struct {
+	service.UserService
+	checked []string
+}
+
+func (s *stubUserService) HasPermission(ctx context.Context, userID uint, resource, action string) (bool, error) {
+	s.checked = append(s.checked, resource+":"+action)
+	return true, nil
+}
+
+func TestRequireBatchPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	configManager := config.NewManager(config.Default(), config.Options{})
+
+	tests := []struct {
+		name         string
+		body         string
+		wantStatus   int
+		wantChecked  []string
+		wantDecision string
+	}{
+		{name: "empty body", body: "", wantStatus: http.StatusBadRequest},
+		{name: "malformed body", body: `{"operations":[`, wantStatus: http.StatusBadRequest},
+		{name: "no operations", body: `{"operations":[]}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown ops only", body: `{"operations":[{"op":"drop"}]}`, wantStatus: http.StatusBadRequest},
+		{
+			name:         "known ops",
+			body:         `{"operations":[{"op":"delete"},{"op":"create"},{"op":"drop"}]}`,
+			wantStatus:   http.StatusOK,
+			wantChecked:  []string{"user:create", "user:delete"},
+			wantDecision: database.PermissionDecisionAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &stubUserService{}
+			var decision string
+			r := gin.New()
+			r.POST("/", func(c *gin.Context) {
+				c.Set("user_id", uint(1))
+				c.Next()
+				decision = c.GetString("permission_decision")
+			}, RequireBatchPermission(configManager, users, "user"), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if !reflect.DeepEqual(users.checked, tt.wantChecked) {
+				t.Errorf("checked permissions = %v, want %v", users.checked, tt.wantChecked)
+			}
+			if decision != tt.wantDecision {
+				t.Errorf("permission_decision = %q, want %q", decision, tt.wantDecision)
+			}
+		})
+	}
+}
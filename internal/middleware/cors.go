@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go_web/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSMiddleware 跨域中间件
+// 每次请求读取配置管理器中的最新配置，允许的来源支持热更新；未配置允许的来源时不处理跨域
+func CORSMiddleware(configManager *config.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		allowedOrigins := configManager.Current().CORS.AllowedOrigins
+		if origin == "" || len(allowedOrigins) == 0 {
+			c.Next()
+			return
+		}
+
+		if !originAllowed(origin, allowedOrigins) {
+			// 不允许的来源不返回跨域响应头，预检请求直接拒绝
+			if c.Request.Method == http.MethodOptions {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Add("Vary", "Origin")
+		header.Set("Access-Control-Allow-Credentials", "true")
+		header.Set("Access-Control-Expose-Headers", RequestIDHeader)
+
+		// 预检请求
+		if c.Request.Method == http.MethodOptions {
+			header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+			header.Set("Access-Control-Allow-Headers", "Authorization, Content-Type, "+RequestIDHeader)
+			header.Set("Access-Control-Max-Age", "600")
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// originAllowed 判断来源是否在允许列表中
+func originAllowed(origin string, allowedOrigins []string) bool {
+	for _, allowed := range allowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
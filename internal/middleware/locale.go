@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"go_web/internal/i18n"
+	"go_web/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LocaleMiddleware 语言协商中间件
+// 按 ?lang 查询参数、Accept-Language 选择提示信息的语言（都没有或不支持时使用中文），写入 request context 和 Content-Language 响应头
+func LocaleMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		if lang, ok := i18n.Supported(c.Query("lang")); ok {
+			c.Set("lang_explicit", true)
+			setLang(c, lang)
+		} else {
+			acceptLanguage := c.GetHeader("Accept-Language")
+			c.Set("lang_explicit", acceptLanguage != "")
+			setLang(c, i18n.Negotiate(acceptLanguage))
+		}
+		c.Next()
+	}
+}
+
+// UserLocaleMiddleware 用户语言偏好中间件，放在 JWT 认证中间件之后
+// 请求没有通过 ?lang 或 Accept-Language 指定语言时使用已登录用户的语言偏好（由用户服务缓存）；
+// 未登录、未设置或查询失败时保留默认语言
+func UserLocaleMiddleware(userService service.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool("lang_explicit") {
+			c.Next()
+			return
+		}
+		if userID, ok := c.Get("user_id"); ok {
+			if uid, ok := userID.(uint); ok {
+				language, err := userService.GetUserLanguage(c.Request.Context(), uid)
+				if lang, supported := i18n.Supported(language); err == nil && supported {
+					setLang(c, lang)
+				}
+			}
+		}
+		c.Next()
+	}
+}
+
+// setLang 设置当前请求的语言
+func setLang(c *gin.Context, lang string) {
+	c.Header("Content-Language", lang)
+	c.Request = c.Request.WithContext(i18n.WithLang(c.Request.Context(), lang))
+}
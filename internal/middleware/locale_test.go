@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go_web/internal/i18n"
+	"go_web/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// stubLanguageService 返回固定的语言偏好并记录查询次数
+type stubLanguageService struct {
+	service.UserService
+	calls int
+}
+
+func (s *stubLanguageService) GetUserLanguage(ctx context.Context, id uint) (string, error) {
+	s.calls++
+	return "en", nil
+}
+
+func TestUserLocaleMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		target         string
+		acceptLanguage string
+		wantLang       string
+		wantCalls      int
+	}{
+		{name: "user preference", target: "/", wantLang: "en", wantCalls: 1},
+		{name: "query parameter", target: "/?lang=zh", wantLang: "zh"},
+		{name: "accept language", target: "/", acceptLanguage: "zh-CN", wantLang: "zh"},
+		{name: "unsupported query parameter", target: "/?lang=fr", wantLang: "en", wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &stubLanguageService{}
+			var lang string
+			r := gin.New()
+			r.GET("/", LocaleMiddleware(), func(c *gin.Context) {
+				c.Set("user_id", uint(1))
+			}, UserLocaleMiddleware(users), func(c *gin.Context) {
+				lang = i18n.FromContext(c.Request.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			if lang != tt.wantLang {
+				t.Errorf("lang = %q, want %q", lang, tt.wantLang)
+			}
+			if users.calls != tt.wantCalls {
+				t.Errorf("GetUserLanguage calls = %d, want %d", users.calls, tt.wantCalls)
+			}
+		})
+	}
+}
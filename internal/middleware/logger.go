@@ -53,12 +53,14 @@ func LoggerMiddleware(log *logger.Logger) gin.HandlerFunc {
 		}
 
 		entry := log.WithFields(logrus.Fields{
-			"status":     statusCode,
-			"latency":    latency,
-			"client_ip":  clientIP,
-			"method":     method,
-			"path":       path,
-			"user_agent": c.Request.UserAgent(),
+			"request_id":        c.GetString("request_id"),
+			"client_request_id": c.GetString("client_request_id"),
+			"status":            statusCode,
+			"latency":           latency,
+			"client_ip":         clientIP,
+			"method":            method,
+			"path":              path,
+			"user_agent":        c.Request.UserAgent(),
 		})
 
 		// 如果有错误信息，记录到日志中
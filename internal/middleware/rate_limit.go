@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"go_web/internal/apperr"
+	"go_web/internal/config"
+	"go_web/internal/util"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// 限流器清理参数：超过 rateLimiterIdle 未访问的客户端限流器会被清理
+const (
+	rateLimiterIdle          = 3 * time.Minute
+	rateLimiterSweepInterval = time.Minute
+)
+
+// clientLimiter 单个客户端的限流器
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// ipRateLimiter 按客户端IP限流，限流参数变化时重建全部限流器
+type ipRateLimiter struct {
+	mu        sync.Mutex
+	limit     config.RateLimitConfig
+	clients   map[string]*clientLimiter
+	lastSweep time.Time
+}
+
+// allow 判断客户端本次请求是否允许通过
+func (l *ipRateLimiter) allow(ip string, limit config.RateLimitConfig) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if limit != l.limit {
+		l.limit = limit
+		l.clients = make(map[string]*clientLimiter)
+	}
+	if now.Sub(l.lastSweep) > rateLimiterSweepInterval {
+		for key, client := range l.clients {
+			if now.Sub(client.lastSeen) > rateLimiterIdle {
+				delete(l.clients, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	client, ok := l.clients[ip]
+	if !ok {
+		client = &clientLimiter{limiter: rate.NewLimiter(rate.Limit(limit.RequestsPerSecond), limit.Burst)}
+		l.clients[ip] = client
+	}
+	client.lastSeen = now
+	return client.limiter.AllowN(now, 1)
+}
+
+// RateLimitMiddleware 按客户端IP限流的中间件
+// 每次请求读取配置管理器中的最新配置，限流参数支持热更新；requests_per_second 为0时不限流
+func RateLimitMiddleware(configManager *config.Manager) gin.HandlerFunc {
+	limiter := &ipRateLimiter{clients: make(map[string]*clientLimiter)}
+	return func(c *gin.Context) {
+		limit := configManager.Current().RateLimit
+		if limit.RequestsPerSecond <= 0 {
+			c.Next()
+			return
+		}
+
+		// 健康检查和文档不限流
+		path := c.Request.URL.Path
+		if path == "/health" || strings.HasPrefix(path, "/swagger") {
+			c.Next()
+			return
+		}
+
+		if !limiter.allow(c.ClientIP(), limit) {
+			c.Header("Retry-After", "1")
+			util.Fail(c, apperr.ErrTooManyRequests)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"go_web/internal/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadYourWritesMiddleware 读己之写中间件
+// 请求中发生写入后，该请求后续的读操作都使用主库，避免配置只读副本时读到尚未同步的旧数据
+func ReadYourWritesMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(database.WithReadYourWrites(c.Request.Context()))
+		c.Next()
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"go_web/internal/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader 请求ID的 HTTP 头
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDMiddleware 请求ID中间件
+// 请求ID始终由服务端生成，写入响应头和 request context，用于关联 HTTP 审计事件和数据库审计日志；
+// 客户端传入的合法请求ID只作为 client_request_id 记录，不能用来合并或伪造其他请求的审计记录
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := newRequestID()
+		if clientRequestID := c.GetHeader(RequestIDHeader); isValidRequestID(clientRequestID) {
+			c.Set("client_request_id", clientRequestID)
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		// 设置到 request context，供数据库审计插件关联同一请求产生的审计记录
+		ctx := context.WithValue(c.Request.Context(), database.AuditRequestIDKey, requestID)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// newRequestID 生成随机请求ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// isValidRequestID 只接受长度不超过64、由字母数字和 -_. 组成的请求ID
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > 64 {
+		return false
+	}
+	for _, ch := range requestID {
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9':
+		case ch == '-', ch == '_', ch == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
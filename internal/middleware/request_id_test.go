@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name                string
+		header              string
+		wantClientRequestID string
+	}{
+		{name: "no header"},
+		{name: "valid client id", header: "client-abc.123", wantClientRequestID: "client-abc.123"},
+		{name: "invalid client id", header: "bad id\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requestID, clientRequestID string
+			r := gin.New()
+			r.Use(RequestIDMiddleware())
+			r.GET("/", func(c *gin.Context) {
+				requestID = c.GetString("request_id")
+				clientRequestID = c.GetString("client_request_id")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(RequestIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if len(requestID) != 32 || requestID == tt.header {
+				t.Errorf("request_id = %q, want a server-generated id", requestID)
+			}
+			if got := w.Header().Get(RequestIDHeader); got != requestID {
+				t.Errorf("response %s = %q, want %q", RequestIDHeader, got, requestID)
+			}
+			if clientRequestID != tt.wantClientRequestID {
+				t.Errorf("client_request_id = %q, want %q", clientRequestID, tt.wantClientRequestID)
+			}
+		})
+	}
+}
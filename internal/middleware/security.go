@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"context"
+
+	"go_web/internal/database"
+	"go_web/internal/model"
+	"go_web/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SecurityEventMiddleware 安全事件中间件
+// 在请求处理完成后，根据认证和权限校验中间件设置的结果记录无效 Token、未登录和权限不足事件
+func SecurityEventMiddleware(securityEventService service.SecurityEventService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		var eventType, reason string
+		if tokenError := c.GetString("token_error"); tokenError != "" {
+			eventType = model.SecurityEventInvalidToken
+			reason = tokenError
+		} else {
+			switch c.GetString("permission_decision") {
+			case database.PermissionDecisionDenied:
+				eventType = model.SecurityEventForbidden
+				reason = "缺少权限: " + c.GetString("permission")
+			case database.PermissionDecisionUnauthenticated:
+				eventType = model.SecurityEventUnauthenticated
+				reason = "未登录访问: " + c.GetString("permission")
+			}
+		}
+		if eventType == "" {
+			return
+		}
+
+		event := &model.SecurityEvent{
+			EventType: eventType,
+			UserID:    contextUserID(c),
+			Email:     c.GetString("user_email"),
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			Reason:    reason,
+			RequestID: c.GetString("request_id"),
+		}
+
+		// 响应已写出，客户端断开也要完成记录
+		ctx := context.WithoutCancel(c.Request.Context())
+		if err := securityEventService.RecordEvent(ctx, event); err != nil {
+			_ = c.Error(err)
+		}
+	}
+}
@@ -13,10 +13,11 @@ type Role struct {
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 
-	Name        string `gorm:"type:varchar(100);not null;uniqueIndex" json:"name"` // 角色名称，如：admin, editor, viewer
+	Name        string `gorm:"type:varchar(100);not null;uniqueIndex" json:"name"` // 角色名称，如：admin, editor, viewer（唯一索引只约束未删除的角色）
 	DisplayName string `gorm:"type:varchar(100);not null" json:"display_name"`     // 显示名称，如：管理员
 	Description string `gorm:"type:varchar(255)" json:"description"`               // 角色描述
 	Status      int    `gorm:"default:1" json:"status"`                            // 1: 启用, 0: 禁用
+	Version     uint   `gorm:"not null;default:1" json:"version"`                  // 版本号，每次更新加一，用于乐观锁和 ETag
 
 	// 关联关系
 	Users       []User       `gorm:"many2many:user_roles;" json:"users,omitempty"`
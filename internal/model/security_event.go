@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// 安全事件类型
+const (
+	SecurityEventLoginFailed     = "login_failed"    // 登录失败
+	SecurityEventInvalidToken    = "invalid_token"   // 无效或过期的 Token
+	SecurityEventUnauthenticated = "unauthenticated" // 未登录访问受保护资源
+	SecurityEventForbidden       = "forbidden"       // 权限不足被拒绝
+)
+
+// SecurityEvent 安全事件模型（认证与授权失败记录）
+type SecurityEvent struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	EventType string `gorm:"type:varchar(50);not null;index" json:"event_type"` // 事件类型
+	UserID    uint   `gorm:"index" json:"user_id"`                              // 用户ID（未知时为0）
+	Email     string `gorm:"type:varchar(100);index" json:"email"`              // 用户邮箱
+	IP        string `gorm:"type:varchar(50);index" json:"ip"`                  // 客户端IP
+	UserAgent string `gorm:"type:varchar(255)" json:"user_agent"`               // User-Agent
+	Method    string `gorm:"type:varchar(10)" json:"method"`                    // 请求方法
+	Path      string `gorm:"type:varchar(255)" json:"path"`                     // 请求路径
+	Reason    string `gorm:"type:varchar(255)" json:"reason"`                   // 失败原因
+	RequestID string `gorm:"type:varchar(64);index" json:"request_id"`          // 请求ID
+}
+
+// TableName 指定表名
+func (SecurityEvent) TableName() string {
+	return "security_events"
+}
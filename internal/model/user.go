@@ -14,9 +14,11 @@ type User struct {
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 
 	Name     string `gorm:"type:varchar(100);not null" json:"name"`
-	Email    string `gorm:"type:varchar(100);uniqueIndex;not null" json:"email"`
-	Password string `gorm:"type:varchar(255);not null" json:"-"` // 密码不返回给前端
-	Status   int    `gorm:"default:1" json:"status"`             // 1: 正常, 0: 禁用
+	Email    string `gorm:"type:varchar(100);uniqueIndex;not null" json:"email"`  // 唯一索引只约束未删除的用户（见迁移 0005）
+	Password string `gorm:"type:varchar(255);not null" json:"-"`                  // 密码不返回给前端
+	Status   int    `gorm:"default:1" json:"status"`                              // 1: 正常, 0: 禁用
+	Version  uint   `gorm:"not null;default:1" json:"version"`                    // 版本号，每次更新加一，用于乐观锁和 ETag
+	Language string `gorm:"type:varchar(10);not null;default:''" json:"language"` // 接口提示信息的语言偏好（zh/en），为空时按 Accept-Language 协商
 
 	// 关联关系
 	Roles []Role `gorm:"many2many:user_roles;" json:"roles,omitempty"`
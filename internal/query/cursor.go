@@ -0,0 +1,95 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"go_web/internal/i18n"
+
+	"gorm.io/gorm"
+)
+
+// cursor 游标：边界记录的排序字段值和翻页方向
+type cursor struct {
+	values []interface{}
+	before bool // true 表示取边界之前的记录（上一页）
+}
+
+// cursorPayload 游标编码前的内容，游标对客户端不透明（base64url 编码的 JSON）
+type cursorPayload struct {
+	Sort   string   `json:"s"` // 生成游标时的排序，与当前排序不一致时游标无效
+	Values []string `json:"v"`
+	Before bool     `json:"b,omitempty"`
+}
+
+// sortKey 排序的文本形式，如 -created_at,id
+func (q *compiled) sortKey() string {
+	items := make([]string, 0, len(q.order))
+	for _, item := range q.order {
+		if item.desc {
+			items = append(items, "-"+item.name)
+		} else {
+			items = append(items, item.name)
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+// encodeCursor 以记录 row 的排序字段值生成游标
+func (q *compiled) encodeCursor(tx *gorm.DB, row reflect.Value, before bool) (string, error) {
+	row = reflect.Indirect(row)
+	payload := cursorPayload{Sort: q.sortKey(), Before: before}
+	for _, item := range q.order {
+		field := tx.Statement.Schema.LookUpField(item.field.Column)
+		if field == nil {
+			return "", fmt.Errorf("模型 %s 没有字段 %s", tx.Statement.Schema.Name, item.field.Column)
+		}
+		value, _ := field.ValueOf(tx.Statement.Context, row)
+		switch v := value.(type) {
+		case time.Time:
+			payload.Values = append(payload.Values, v.Format(time.RFC3339Nano))
+		default:
+			payload.Values = append(payload.Values, fmt.Sprint(v))
+		}
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor 解析游标，并按字段类型解析边界值
+func decodeCursor(raw string, q *compiled) (*cursor, error) {
+	invalid := ErrInvalid.WithMessage(i18n.MsgQueryInvalidCursor)
+
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, invalid
+	}
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, invalid
+	}
+	if payload.Sort != q.sortKey() {
+		return nil, ErrInvalid.WithMessage(i18n.MsgQueryCursorSort)
+	}
+	if len(payload.Values) != len(q.order) {
+		return nil, invalid
+	}
+
+	c := &cursor{before: payload.Before}
+	for i, item := range q.order {
+		value, err := item.field.parse(item.name, payload.Values[i])
+		if err != nil {
+			return nil, invalid
+		}
+		c.values = append(c.values, value)
+	}
+	return c, nil
+}
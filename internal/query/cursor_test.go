@@ -0,0 +1,98 @@
+package query
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+	"time"
+
+	"go_web/internal/i18n"
+)
+
+// rawCursor 按游标的编码方式编码任意内容
+func rawCursor(payload string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(payload))
+}
+
+func TestDecodeCursor(t *testing.T) {
+	q, err := (&Spec{Sorts: []Sort{{Field: "created_at", Desc: true}}}).compile(testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		raw        string
+		wantValues []interface{}
+		wantBefore bool
+		wantKey    string
+	}{
+		{
+			name:       "next page",
+			raw:        rawCursor(`{"s":"-created_at,id","v":["2024-01-02T03:04:05.123456789Z","7"]}`),
+			wantValues: []interface{}{time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.UTC), int64(7)},
+		},
+		{
+			name:       "previous page",
+			raw:        rawCursor(`{"s":"-created_at,id","v":["2024-01-02T03:04:05Z","7"],"b":true}`),
+			wantValues: []interface{}{time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), int64(7)},
+			wantBefore: true,
+		},
+		{
+			name:    "not base64",
+			raw:     "!!!",
+			wantKey: i18n.MsgQueryInvalidCursor,
+		},
+		{
+			name:    "padded base64",
+			raw:     base64.URLEncoding.EncodeToString([]byte(`{"s":"-created_at,id","v":["2024-01-02T03:04:05Z","7"]}`)),
+			wantKey: i18n.MsgQueryInvalidCursor,
+		},
+		{
+			name:    "not json",
+			raw:     rawCursor("not json"),
+			wantKey: i18n.MsgQueryInvalidCursor,
+		},
+		{
+			name:    "sort changed",
+			raw:     rawCursor(`{"s":"name,id","v":["bob","7"]}`),
+			wantKey: i18n.MsgQueryCursorSort,
+		},
+		{
+			name:    "missing value",
+			raw:     rawCursor(`{"s":"-created_at,id","v":["2024-01-02T03:04:05Z"]}`),
+			wantKey: i18n.MsgQueryInvalidCursor,
+		},
+		{
+			name:    "extra value",
+			raw:     rawCursor(`{"s":"-created_at,id","v":["2024-01-02T03:04:05Z","7","8"]}`),
+			wantKey: i18n.MsgQueryInvalidCursor,
+		},
+		{
+			name:    "bad time",
+			raw:     rawCursor(`{"s":"-created_at,id","v":["yesterday","7"]}`),
+			wantKey: i18n.MsgQueryInvalidCursor,
+		},
+		{
+			name:    "bad id",
+			raw:     rawCursor(`{"s":"-created_at,id","v":["2024-01-02T03:04:05Z","x"]}`),
+			wantKey: i18n.MsgQueryInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := decodeCursor(tt.raw, q)
+			checkErr(t, err, tt.wantKey)
+			if tt.wantKey != "" {
+				return
+			}
+			if !reflect.DeepEqual(c.values, tt.wantValues) {
+				t.Errorf("values = %#v, want %#v", c.values, tt.wantValues)
+			}
+			if c.before != tt.wantBefore {
+				t.Errorf("before = %v, want %v", c.before, tt.wantBefore)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"go_web/internal/i18n"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 每页数量
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100 // 超过时按最大值返回
+)
+
+// Page 分页参数
+type Page struct {
+	Number int    // 页码，从 1 开始（偏移分页）
+	Size   int    // 每页数量
+	Keyset bool   // 是否为游标分页
+	Cursor string // 游标分页的位置，为空表示第一页
+}
+
+// PageInfo 分页信息，偏移分页返回 total 和 page，游标分页返回 next_cursor/prev_cursor（为空表示没有下一页/上一页）
+type PageInfo struct {
+	Total      *int64 `json:"total,omitempty"` // 过滤后的总数，游标分页不统计
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"page_size"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// ParsePage 解析分页参数：page 必须为正整数，page_size 必须为正整数且超过 MaxPageSize 时按 MaxPageSize 处理；
+// 带 cursor 参数（可以为空）时使用游标分页，不能同时指定 page
+func ParsePage(values url.Values) (Page, error) {
+	page := Page{Number: 1, Size: DefaultPageSize}
+
+	if raw := values.Get("page"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return page, ErrInvalid.WithMessage(i18n.MsgQueryPage, raw)
+		}
+		page.Number = n
+	}
+	if raw := values.Get("page_size"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return page, ErrInvalid.WithMessage(i18n.MsgQueryPageSize, raw)
+		}
+		page.Size = min(n, MaxPageSize)
+	}
+	if _, ok := values["cursor"]; ok {
+		if values.Get("page") != "" {
+			return page, ErrInvalid.WithMessage(i18n.MsgQueryPageWithCursor)
+		}
+		page.Keyset = true
+		page.Cursor = strings.TrimSpace(values.Get("cursor"))
+	}
+	return page, nil
+}
+
+// Find 按查询条件和分页参数查询列表，dest 为指向切片的指针
+// db 可以带有额外的过滤条件；spec 未指定返回的字段和关联时预加载 preloads，预加载只用于查询列表，不影响统计总数
+func Find(db *gorm.DB, schema Schema, spec *Spec, page Page, dest interface{}, preloads ...string) (*PageInfo, error) {
+	q, err := spec.compile(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	db = db.Session(&gorm.Session{})
+	if q.withDeleted {
+		db = db.Clauses(includeDeleted{})
+	}
+	for _, condition := range q.conditions {
+		db = db.Where(condition)
+	}
+	var projection *Projection
+	if spec != nil {
+		projection = spec.Projection
+	}
+	// 游标分页从记录中读取排序列的值，指定 fields 时也要查询这些列
+	orderColumns := make([]string, 0, len(q.order))
+	for _, item := range q.order {
+		orderColumns = append(orderColumns, item.field.Column)
+	}
+	list, err := projection.apply(db, schema, orderColumns, preloads)
+	if err != nil {
+		return nil, err
+	}
+
+	if page.Keyset {
+		return q.findKeyset(list, page, dest)
+	}
+
+	var total int64
+	if err := db.Model(dest).Count(&total).Error; err != nil {
+		return nil, err
+	}
+	err = list.Clauses(q.orderBy(false)).Offset((page.Number - 1) * page.Size).Limit(page.Size).Find(dest).Error
+	if err != nil {
+		return nil, err
+	}
+	return &PageInfo{Total: &total, Page: page.Number, PageSize: page.Size}, nil
+}
+
+// findKeyset 游标分页：以游标记录的排序字段值为边界，多取一条判断是否还有更多记录，不统计总数
+func (q *compiled) findKeyset(db *gorm.DB, page Page, dest interface{}) (*PageInfo, error) {
+	info := &PageInfo{PageSize: page.Size}
+
+	var c *cursor
+	if page.Cursor != "" {
+		var err error
+		if c, err = decodeCursor(page.Cursor, q); err != nil {
+			return nil, err
+		}
+		db = db.Where(q.seek(c.values, c.before))
+	}
+	before := c != nil && c.before
+
+	tx := db.Clauses(q.orderBy(before)).Limit(page.Size + 1).Find(dest)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	rows := reflect.ValueOf(dest).Elem()
+	more := rows.Len() > page.Size
+	if more {
+		rows.Set(rows.Slice(0, page.Size))
+	}
+	if before {
+		reverse(rows)
+	}
+	if rows.Len() == 0 {
+		return info, nil
+	}
+
+	first, last := rows.Index(0), rows.Index(rows.Len()-1)
+	// 向后翻页时，有游标说明前面还有记录；向前翻页时，来源页就在后面
+	hasPrev, hasNext := c != nil, more
+	if before {
+		hasPrev, hasNext = more, true
+	}
+	var err error
+	if hasPrev {
+		if info.PrevCursor, err = q.encodeCursor(tx, first, true); err != nil {
+			return nil, err
+		}
+	}
+	if hasNext {
+		if info.NextCursor, err = q.encodeCursor(tx, last, false); err != nil {
+			return nil, err
+		}
+	}
+	return info, nil
+}
+
+// seek 游标边界条件：(c1 > v1) OR (c1 = v1 AND c2 > v2) OR ...，降序列使用 <，before 为 true 时方向相反
+func (q *compiled) seek(values []interface{}, before bool) clause.Expression {
+	branches := make([]clause.Expression, 0, len(q.order))
+	for i, item := range q.order {
+		conditions := make([]clause.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, clause.Eq{Column: column(q.order[j].field.Column), Value: values[j]})
+		}
+		col := column(item.field.Column)
+		if item.desc != before {
+			conditions = append(conditions, clause.Lt{Column: col, Value: values[i]})
+		} else {
+			conditions = append(conditions, clause.Gt{Column: col, Value: values[i]})
+		}
+		branches = append(branches, clause.And(conditions...))
+	}
+	return or(branches)
+}
+
+// reverse 反转切片
+func reverse(rows reflect.Value) {
+	swap := reflect.Swapper(rows.Interface())
+	for i, j := 0, rows.Len()-1; i < j; i, j = i+1, j-1 {
+		swap(i, j)
+	}
+}
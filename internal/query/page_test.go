@@ -0,0 +1,222 @@
+package query
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testItem 分页测试使用的模型
+type testItem struct {
+	ID        uint
+	Name      string
+	CreatedAt time.Time
+}
+
+// newTestDB 在临时目录中创建 SQLite 数据库
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "query.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&testItem{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestSeek(t *testing.T) {
+	db := newTestDB(t)
+	q, err := (&Spec{Sorts: []Sort{{Field: "created_at", Desc: true}, {Field: "name"}}}).compile(testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	values := []interface{}{at, "bob", int64(7)}
+
+	tests := []struct {
+		name     string
+		before   bool
+		wantSQL  string
+		wantVars []interface{}
+	}{
+		{
+			name:   "next page",
+			before: false,
+			wantSQL: "SELECT * FROM `test_items` WHERE (`test_items`.`created_at` < ? OR " +
+				"(`test_items`.`created_at` = ? AND `test_items`.`name` > ?) OR " +
+				"(`test_items`.`created_at` = ? AND `test_items`.`name` = ? AND `test_items`.`id` > ?))",
+			wantVars: []interface{}{at, at, "bob", at, "bob", int64(7)},
+		},
+		{
+			name:   "previous page",
+			before: true,
+			wantSQL: "SELECT * FROM `test_items` WHERE (`test_items`.`created_at` > ? OR " +
+				"(`test_items`.`created_at` = ? AND `test_items`.`name` < ?) OR " +
+				"(`test_items`.`created_at` = ? AND `test_items`.`name` = ? AND `test_items`.`id` < ?))",
+			wantVars: []interface{}{at, at, "bob", at, "bob", int64(7)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt := db.Session(&gorm.Session{DryRun: true}).Where(q.seek(values, tt.before)).Find(&[]testItem{}).Statement
+			if got := stmt.SQL.String(); got != tt.wantSQL {
+				t.Errorf("SQL = %s\nwant  %s", got, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(stmt.Vars, tt.wantVars) {
+				t.Errorf("vars = %#v, want %#v", stmt.Vars, tt.wantVars)
+			}
+		})
+	}
+}
+
+func TestSeekSingleColumn(t *testing.T) {
+	db := newTestDB(t)
+	q, err := (&Spec{}).compile(testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 只有一个排序列时不能生成单独的 OR 条件，否则会与前面的条件以 OR 连接
+	stmt := db.Session(&gorm.Session{DryRun: true}).
+		Where("name = ?", "bob").
+		Where(q.seek([]interface{}{int64(7)}, false)).
+		Find(&[]testItem{}).Statement
+	want := "SELECT * FROM `test_items` WHERE name = ? AND `test_items`.`id` > ?"
+	if got := stmt.SQL.String(); got != want {
+		t.Fatalf("SQL = %s\nwant  %s", got, want)
+	}
+}
+
+// TestFindKeyset 在 created_at 有重复值的数据上向后翻到最后一页，再从最后一页向前翻回第一页
+func TestFindKeyset(t *testing.T) {
+	db := newTestDB(t)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t0, t1, t2 := base, base.Add(time.Hour), base.Add(2*time.Hour)
+	// 创建时间与 ID 的顺序不一致，每个时间有多条记录
+	for i, at := range []time.Time{t1, t0, t2, t0, t1, t2, t0} {
+		item := &testItem{ID: uint(i + 1), Name: "item", CreatedAt: at}
+		if err := db.Create(item).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		sort []Sort
+		want []uint
+	}{
+		{
+			name: "created_at desc",
+			sort: []Sort{{Field: "created_at", Desc: true}},
+			want: []uint{3, 6, 1, 5, 2, 4, 7},
+		},
+		{
+			name: "created_at asc",
+			sort: []Sort{{Field: "created_at"}},
+			want: []uint{2, 4, 7, 1, 5, 3, 6},
+		},
+		{
+			name: "created_at desc id desc",
+			sort: []Sort{{Field: "created_at", Desc: true}, {Field: "id", Desc: true}},
+			want: []uint{6, 3, 5, 1, 7, 4, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, size := range []int{1, 2, 3, 7, 10} {
+			t.Run(fmt.Sprintf("%s/size=%d", tt.name, size), func(t *testing.T) {
+				spec := &Spec{Sorts: tt.sort}
+				pages := forwardPages(t, db, spec, size)
+				if got := flatten(pages); !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("forward = %v, want %v", got, tt.want)
+				}
+
+				back := backwardPages(t, db, spec, size)
+				if !reflect.DeepEqual(back, pages) {
+					t.Fatalf("backward = %v, want %v", back, pages)
+				}
+			})
+		}
+	}
+}
+
+// forwardPages 从第一页开始按 next_cursor 翻到最后一页，返回每页的 ID
+func forwardPages(t *testing.T, db *gorm.DB, spec *Spec, size int) [][]uint {
+	t.Helper()
+	var pages [][]uint
+	cursor := ""
+	for {
+		ids, info := findPage(t, db, spec, Page{Size: size, Keyset: true, Cursor: cursor})
+		if len(pages) == 0 && info.PrevCursor != "" {
+			t.Fatalf("first page has prev_cursor")
+		}
+		pages = append(pages, ids)
+		if info.NextCursor == "" {
+			return pages
+		}
+		if len(pages) > 20 {
+			t.Fatalf("too many pages: %v", pages)
+		}
+		cursor = info.NextCursor
+	}
+}
+
+// backwardPages 先翻到最后一页，再按 prev_cursor 翻回第一页，按从前到后的顺序返回每页的 ID
+func backwardPages(t *testing.T, db *gorm.DB, spec *Spec, size int) [][]uint {
+	t.Helper()
+	cursor := ""
+	var ids []uint
+	var info *PageInfo
+	for {
+		ids, info = findPage(t, db, spec, Page{Size: size, Keyset: true, Cursor: cursor})
+		if info.NextCursor == "" {
+			break
+		}
+		cursor = info.NextCursor
+	}
+
+	pages := [][]uint{ids}
+	for info.PrevCursor != "" {
+		ids, info = findPage(t, db, spec, Page{Size: size, Keyset: true, Cursor: info.PrevCursor})
+		if info.NextCursor == "" {
+			t.Fatalf("page before %v has no next_cursor", pages[0])
+		}
+		pages = append([][]uint{ids}, pages...)
+		if len(pages) > 20 {
+			t.Fatalf("too many pages: %v", pages)
+		}
+	}
+	return pages
+}
+
+func findPage(t *testing.T, db *gorm.DB, spec *Spec, page Page) ([]uint, *PageInfo) {
+	t.Helper()
+	var items []*testItem
+	info, err := Find(db.Model(&testItem{}), testSchema, spec, page, &items)
+	if err != nil {
+		t.Fatalf("Find(cursor=%q): %v", page.Cursor, err)
+	}
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids, info
+}
+
+func flatten(pages [][]uint) []uint {
+	var ids []uint
+	for _, page := range pages {
+		ids = append(ids, page...)
+	}
+	return ids
+}
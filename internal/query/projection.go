@@ -0,0 +1,186 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go_web/internal/i18n"
+
+	"gorm.io/gorm"
+)
+
+// Projection 详情和列表接口返回的字段（fields）和展开的关联（include）
+//
+//	fields=id,name,email              只返回这些字段（JSON 字段名），id 总是返回
+//	include=roles,roles.permissions   展开的关联，嵌套关联用 . 连接；include= 表示不展开任何关联
+//
+// 未指定 fields 和 include 时返回全部字段并展开接口默认的关联；指定了 fields 时只展开 include 中的关联
+type Projection struct {
+	Fields   []string // 为 nil 表示全部字段
+	Includes []string // 为 nil 表示未指定 include
+}
+
+// projectionNamePattern fields 和 include 中的名称
+var projectionNamePattern = regexp.MustCompile(`^[a-z_]+(\.[a-z_]+)*$`)
+
+// ParseProjection 解析 fields 和 include 参数，两者都未指定时返回 nil
+// 只校验语法，字段和关联由查询时按模型的白名单（Schema）校验
+func ParseProjection(values url.Values) (*Projection, error) {
+	_, hasFields := values["fields"]
+	_, hasIncludes := values["include"]
+	if !hasFields && !hasIncludes {
+		return nil, nil
+	}
+
+	p := &Projection{}
+	var err error
+	if hasFields {
+		if p.Fields, err = splitNames(values.Get("fields")); err != nil {
+			return nil, err
+		}
+	}
+	if hasIncludes {
+		if p.Includes, err = splitNames(values.Get("include")); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// splitNames 解析逗号分隔的名称，忽略空项和重复项，返回非 nil 的切片
+func splitNames(raw string) ([]string, error) {
+	names := []string{}
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(raw, ",") {
+		name := strings.TrimSpace(item)
+		if name == "" || seen[name] {
+			continue
+		}
+		if !projectionNamePattern.MatchString(name) {
+			return nil, ErrInvalid.WithMessage(i18n.MsgQueryProjectionSyntax, name)
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// Project 按 fields 和 include 设置查询的列和预加载，p 为 nil 或两者都未指定时预加载 defaults（预加载名称，如 Roles.Permissions）
+func Project(db *gorm.DB, schema Schema, p *Projection, defaults ...string) (*gorm.DB, error) {
+	return p.apply(db, schema, nil, defaults)
+}
+
+// apply 同 Project，extra 为 fields 之外必须查询的列（如游标分页的排序列）
+func (p *Projection) apply(db *gorm.DB, schema Schema, extra []string, defaults []string) (*gorm.DB, error) {
+	if p == nil || (p.Fields == nil && p.Includes == nil) {
+		for _, name := range defaults {
+			db = db.Preload(name)
+		}
+		return db, nil
+	}
+
+	if p.Fields != nil {
+		// id 用于预加载关联，Keep 中的列（如 ETag 使用的 version）不返回但总是查询
+		columns := []string{"id"}
+		columns = append(columns, schema.Keep...)
+		columns = append(columns, extra...)
+		for _, name := range p.Fields {
+			if !contains(schema.Select, name) {
+				return nil, ErrInvalid.WithMessage(i18n.MsgQueryField, name, strings.Join(schema.Select, "/"))
+			}
+			columns = append(columns, name)
+		}
+		db = db.Select(unique(columns))
+	}
+
+	for _, name := range p.Includes {
+		preload, ok := schema.Includes[name]
+		if !ok {
+			return nil, ErrInvalid.WithMessage(i18n.MsgQueryInclude, name, strings.Join(includeNames(schema), "/"))
+		}
+		db = db.Preload(preload)
+	}
+	return db, nil
+}
+
+// Render 按 fields 裁剪响应数据（单个记录或记录切片），保留 id 和展开的关联，展开的关联为空时返回空数组；p 为 nil 时原样返回
+func (p *Projection) Render(data interface{}) (interface{}, error) {
+	if p == nil {
+		return data, nil
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var result interface{}
+	if err := decoder.Decode(&result); err != nil {
+		return nil, err
+	}
+
+	switch v := result.(type) {
+	case map[string]interface{}:
+		p.shape(v)
+	case []interface{}:
+		for _, item := range v {
+			if record, ok := item.(map[string]interface{}); ok {
+				p.shape(record)
+			}
+		}
+	}
+	return result, nil
+}
+
+// shape 裁剪单条记录
+func (p *Projection) shape(record map[string]interface{}) {
+	keep := map[string]bool{"id": true}
+	for _, name := range p.Fields {
+		keep[name] = true
+	}
+	for _, name := range p.Includes {
+		// 嵌套关联（roles.permissions）展开在顶层关联（roles）中
+		name = strings.SplitN(name, ".", 2)[0]
+		keep[name] = true
+		if _, ok := record[name]; !ok {
+			record[name] = []interface{}{}
+		}
+	}
+
+	if p.Fields == nil {
+		return
+	}
+	for name := range record {
+		if !keep[name] {
+			delete(record, name)
+		}
+	}
+}
+
+// includeNames 白名单中的关联名称，用于错误提示
+func includeNames(schema Schema) []string {
+	names := make([]string, 0, len(schema.Includes))
+	for name := range schema.Includes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// unique 去除重复项，保持顺序
+func unique(items []string) []string {
+	result := make([]string, 0, len(items))
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			result = append(result, item)
+		}
+	}
+	return result
+}
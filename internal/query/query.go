@@ -0,0 +1,134 @@
+// Package query 列表接口的过滤、排序、关键字搜索和分页
+//
+// 查询参数格式：
+//
+//	filter[字段]=值             等于
+//	filter[字段][操作符]=值     操作符见 Op* 常量，in 的多个值用逗号分隔
+//	sort=-created_at,name       逗号分隔，- 前缀表示降序
+//	q=关键字                    在模型指定的列中模糊匹配
+//	include_deleted=true        同时查询已软删除的记录，only 表示只查询已删除的记录（回收站）
+//	fields=id,name              只返回这些字段，见 Projection
+//	include=roles               展开的关联，见 Projection
+//
+//	page=2&page_size=20         偏移分页
+//	cursor=&page_size=20        游标分页，cursor 为空表示第一页，之后使用响应中的 next_cursor/prev_cursor
+//
+// Parse 和 ParsePage 只校验语法，字段和操作符由 Find 按模型的白名单（Schema）校验，
+// 列名只来自白名单，值全部作为参数绑定，不会拼接到 SQL 中
+package query
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go_web/internal/apperr"
+	"go_web/internal/i18n"
+)
+
+// ErrInvalid 查询参数无效（语法错误、未知字段、不支持的操作符或无法解析的值），错误码为 INVALID_QUERY
+var ErrInvalid = apperr.ErrInvalidQuery
+
+// 过滤操作符
+const (
+	OpEq   = "eq"   // 等于
+	OpNe   = "ne"   // 不等于
+	OpGt   = "gt"   // 大于
+	OpGte  = "gte"  // 大于等于
+	OpLt   = "lt"   // 小于
+	OpLte  = "lte"  // 小于等于
+	OpLike = "like" // 包含（不区分大小写）
+	OpIn   = "in"   // 属于，多个值用逗号分隔
+)
+
+// 已软删除记录的查询范围（include_deleted 参数）
+const (
+	DeletedExclude = ""     // 只查询未删除的记录（默认）
+	DeletedInclude = "true" // 同时查询已删除的记录
+	DeletedOnly    = "only" // 只查询已删除的记录
+)
+
+// maxInValues in 操作符最多允许的值数量
+const maxInValues = 100
+
+// Spec 列表查询条件
+type Spec struct {
+	Filters []Filter
+	Sorts   []Sort
+	Search  string
+	Deleted string // 已软删除记录的查询范围，见 Deleted* 常量
+
+	Projection *Projection // 返回的字段和展开的关联，为 nil 时返回全部字段并展开默认的关联
+}
+
+// Filter 过滤条件，同一字段的多个条件之间为 AND
+type Filter struct {
+	Field string
+	Op    string
+	Value string
+}
+
+// Sort 排序字段
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// filterKeyPattern 匹配 filter[字段] 和 filter[字段][操作符]
+var filterKeyPattern = regexp.MustCompile(`^filter\[([a-z_]+)\](?:\[([a-z]+)\])?$`)
+
+// Parse 从查询参数解析过滤、排序、搜索条件以及返回的字段和关联，其他参数（如 page）忽略
+func Parse(values url.Values) (*Spec, error) {
+	spec := &Spec{Search: strings.TrimSpace(values.Get("q"))}
+
+	projection, err := ParseProjection(values)
+	if err != nil {
+		return nil, err
+	}
+	spec.Projection = projection
+
+	// 按参数名排序，保证生成的 SQL 稳定
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if strings.HasPrefix(key, "filter") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		match := filterKeyPattern.FindStringSubmatch(key)
+		if match == nil {
+			return nil, ErrInvalid.WithMessage(i18n.MsgQueryFilterSyntax, key)
+		}
+		op := match[2]
+		if op == "" {
+			op = OpEq
+		}
+		for _, value := range values[key] {
+			spec.Filters = append(spec.Filters, Filter{Field: match[1], Op: op, Value: value})
+		}
+	}
+
+	switch raw := strings.TrimSpace(values.Get("include_deleted")); raw {
+	case "", "false":
+	case DeletedInclude, DeletedOnly:
+		spec.Deleted = raw
+	default:
+		return nil, ErrInvalid.WithMessage(i18n.MsgQueryIncludeDeleted, raw)
+	}
+
+	if raw := strings.TrimSpace(values.Get("sort")); raw != "" {
+		for _, item := range strings.Split(raw, ",") {
+			item = strings.TrimSpace(item)
+			desc := strings.HasPrefix(item, "-")
+			field := strings.TrimPrefix(item, "-")
+			if field == "" {
+				return nil, ErrInvalid.WithMessage(i18n.MsgQueryEmptySort)
+			}
+			spec.Sorts = append(spec.Sorts, Sort{Field: field, Desc: desc})
+		}
+	}
+
+	return spec, nil
+}
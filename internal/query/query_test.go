@@ -0,0 +1,153 @@
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"go_web/internal/i18n"
+)
+
+// testSchema 测试使用的查询白名单
+var testSchema = Schema{
+	Fields: map[string]Field{
+		"id":         {Column: "id", Type: Int, Ops: NumberOps, Sortable: true},
+		"name":       {Column: "name", Type: String, Ops: StringOps, Sortable: true},
+		"age":        {Column: "age", Type: Int, Ops: NumberOps},
+		"created_at": {Column: "created_at", Type: Time, Ops: TimeOps, Sortable: true},
+		"note":       {Column: "note", Type: String},
+	},
+	Search:     []string{"name"},
+	SoftDelete: true,
+}
+
+// checkErr 比较错误的提示信息，wantKey 为空表示不应出错
+func checkErr(t *testing.T, err error, wantKey string, wantArgs ...interface{}) {
+	t.Helper()
+	if wantKey == "" {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+	want := ErrInvalid.WithMessage(wantKey, wantArgs...)
+	if err == nil {
+		t.Fatalf("expected error %q, got nil", want.Error())
+	}
+	if err.Error() != want.Error() {
+		t.Fatalf("expected error %q, got %q", want.Error(), err.Error())
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		want     *Spec
+		wantKey  string
+		wantArgs []interface{}
+	}{
+		{
+			name:  "empty",
+			query: "",
+			want:  &Spec{},
+		},
+		{
+			name:  "filter defaults to eq",
+			query: "filter[name]=bob",
+			want:  &Spec{Filters: []Filter{{Field: "name", Op: OpEq, Value: "bob"}}},
+		},
+		{
+			name:  "filters sorted by key",
+			query: "filter[age][lt]=9&filter[age][gt]=3&filter[name][in]=a,b",
+			want: &Spec{Filters: []Filter{
+				{Field: "age", Op: OpGt, Value: "3"},
+				{Field: "age", Op: OpLt, Value: "9"},
+				{Field: "name", Op: OpIn, Value: "a,b"},
+			}},
+		},
+		{
+			name:  "repeated filter",
+			query: "filter[name][ne]=a&filter[name][ne]=b",
+			want: &Spec{Filters: []Filter{
+				{Field: "name", Op: OpNe, Value: "a"},
+				{Field: "name", Op: OpNe, Value: "b"},
+			}},
+		},
+		{
+			name:  "search trimmed",
+			query: "q=%20bob%20",
+			want:  &Spec{Search: "bob"},
+		},
+		{
+			name:  "sort",
+			query: "sort=-created_at,%20name",
+			want:  &Spec{Sorts: []Sort{{Field: "created_at", Desc: true}, {Field: "name"}}},
+		},
+		{
+			name:  "include deleted",
+			query: "include_deleted=true",
+			want:  &Spec{Deleted: DeletedInclude},
+		},
+		{
+			name:  "only deleted",
+			query: "include_deleted=only",
+			want:  &Spec{Deleted: DeletedOnly},
+		},
+		{
+			name:  "include deleted false",
+			query: "include_deleted=false",
+			want:  &Spec{},
+		},
+		{
+			name:  "projection",
+			query: "fields=id,name&page=2",
+			want:  &Spec{Projection: &Projection{Fields: []string{"id", "name"}}},
+		},
+		{
+			name:     "filter uppercase field",
+			query:    "filter[Name]=bob",
+			wantKey:  i18n.MsgQueryFilterSyntax,
+			wantArgs: []interface{}{"filter[Name]"},
+		},
+		{
+			name:     "filter without field",
+			query:    "filter=bob",
+			wantKey:  i18n.MsgQueryFilterSyntax,
+			wantArgs: []interface{}{"filter"},
+		},
+		{
+			name:     "bad include deleted",
+			query:    "include_deleted=yes",
+			wantKey:  i18n.MsgQueryIncludeDeleted,
+			wantArgs: []interface{}{"yes"},
+		},
+		{
+			name:    "empty sort item",
+			query:   "sort=name,,id",
+			wantKey: i18n.MsgQueryEmptySort,
+		},
+		{
+			name:    "sort only minus",
+			query:   "sort=-",
+			wantKey: i18n.MsgQueryEmptySort,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			spec, err := Parse(values)
+			checkErr(t, err, tt.wantKey, tt.wantArgs...)
+			if tt.wantKey != "" {
+				return
+			}
+			if !reflect.DeepEqual(spec, tt.want) {
+				t.Fatalf("Parse(%q) = %+v, want %+v", tt.query, spec, tt.want)
+			}
+		})
+	}
+}
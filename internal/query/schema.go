@@ -0,0 +1,247 @@
+package query
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"go_web/internal/i18n"
+
+	"gorm.io/gorm/clause"
+)
+
+// FieldType 字段类型，决定过滤值的解析方式
+type FieldType int
+
+const (
+	String FieldType = iota
+	Int
+	Time // RFC3339 或 2006-01-02（本地时间）
+)
+
+// 常用的操作符组合
+var (
+	StringOps = []string{OpEq, OpNe, OpLike, OpIn}
+	NumberOps = []string{OpEq, OpNe, OpGt, OpGte, OpLt, OpLte, OpIn}
+	TimeOps   = []string{OpGt, OpGte, OpLt, OpLte}
+)
+
+// Field 可查询的字段
+type Field struct {
+	Column   string // 数据库列名
+	Type     FieldType
+	Ops      []string // 允许的过滤操作符，为空表示不能过滤
+	Sortable bool     // 是否允许排序
+}
+
+// Schema 模型的查询白名单
+type Schema struct {
+	Fields      map[string]Field // 参数中的字段名 -> 字段
+	Search      []string         // q 模糊匹配的列，任一列匹配即可，为空表示不支持 q
+	DefaultSort []Sort           // 未指定 sort 时的排序，为空时按 id 升序
+	SoftDelete  bool             // 模型是否带有软删除字段（deleted_at），为 false 时不支持 include_deleted
+
+	Select   []string          // fields 可选的字段（JSON 字段名，与列名相同），为空表示不支持 fields
+	Keep     []string          // 指定 fields 时也总是查询的列（不返回），如 ETag 使用的 version
+	Includes map[string]string // include 可选的关联：参数中的名称（如 roles.permissions）-> 预加载名称（如 Roles.Permissions）
+}
+
+// likeEscape LIKE 的转义字符，使用 ! 而不是反斜杠，避免不同数据库对字符串中反斜杠的处理差异
+const likeEscape = "!"
+
+var likeEscaper = strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_")
+
+// idField 排序的最后一个字段，保证分页结果稳定
+var idField = Field{Column: "id", Type: Int, Sortable: true}
+
+// orderColumn 排序列
+type orderColumn struct {
+	name  string // 参数中的字段名
+	field Field
+	desc  bool
+}
+
+// compiled 按白名单校验后的查询条件
+type compiled struct {
+	conditions  []clause.Expression
+	order       []orderColumn
+	withDeleted bool // 包含已软删除的记录
+}
+
+// compile 按白名单校验查询条件，spec 为 nil 时不过滤并使用默认排序
+// 排序始终以 id 作为最后一个字段，保证分页结果稳定
+func (s *Spec) compile(schema Schema) (*compiled, error) {
+	if s == nil {
+		s = &Spec{}
+	}
+	result := &compiled{}
+
+	for _, f := range s.Filters {
+		condition, err := schema.condition(f)
+		if err != nil {
+			return nil, err
+		}
+		result.conditions = append(result.conditions, condition)
+	}
+	if s.Deleted != DeletedExclude {
+		if !schema.SoftDelete {
+			return nil, ErrInvalid.WithMessage(i18n.MsgQueryIncludeDeletedUnsupported)
+		}
+		result.withDeleted = true
+		if s.Deleted == DeletedOnly {
+			result.conditions = append(result.conditions, clause.Neq{Column: column("deleted_at"), Value: nil})
+		}
+	}
+	if s.Search != "" {
+		if len(schema.Search) == 0 {
+			return nil, ErrInvalid.WithMessage(i18n.MsgQuerySearch)
+		}
+		matches := make([]clause.Expression, 0, len(schema.Search))
+		for _, column := range schema.Search {
+			matches = append(matches, likeExpr(column, s.Search))
+		}
+		result.conditions = append(result.conditions, or(matches))
+	}
+
+	sorts := s.Sorts
+	if len(sorts) == 0 {
+		sorts = schema.DefaultSort
+	}
+	seen := make(map[string]bool)
+	for _, item := range sorts {
+		field, ok := schema.Fields[item.Field]
+		if !ok || !field.Sortable {
+			return nil, ErrInvalid.WithMessage(i18n.MsgQuerySort, item.Field)
+		}
+		if seen[field.Column] {
+			continue
+		}
+		seen[field.Column] = true
+		result.order = append(result.order, orderColumn{name: item.Field, field: field, desc: item.Desc})
+	}
+	if !seen[idField.Column] {
+		result.order = append(result.order, orderColumn{name: "id", field: idField})
+	}
+	return result, nil
+}
+
+// orderBy 排序子句，reverse 为 true 时各列反向排序（向前翻页时使用）
+func (q *compiled) orderBy(reverse bool) clause.OrderBy {
+	columns := make([]clause.OrderByColumn, 0, len(q.order))
+	for _, item := range q.order {
+		columns = append(columns, clause.OrderByColumn{Column: column(item.field.Column), Desc: item.desc != reverse})
+	}
+	return clause.OrderBy{Columns: columns}
+}
+
+// condition 将过滤条件转换为查询表达式
+func (schema Schema) condition(f Filter) (clause.Expression, error) {
+	field, ok := schema.Fields[f.Field]
+	if !ok || len(field.Ops) == 0 {
+		return nil, ErrInvalid.WithMessage(i18n.MsgQueryFilter, f.Field)
+	}
+	if !contains(field.Ops, f.Op) {
+		return nil, ErrInvalid.WithMessage(i18n.MsgQueryOperator, f.Field, f.Op, strings.Join(field.Ops, "/"))
+	}
+
+	col := column(field.Column)
+	switch f.Op {
+	case OpLike:
+		return likeExpr(field.Column, f.Value), nil
+	case OpIn:
+		raw := strings.Split(f.Value, ",")
+		if len(raw) > maxInValues {
+			return nil, ErrInvalid.WithMessage(i18n.MsgQueryInValues, f.Field, maxInValues)
+		}
+		values := make([]interface{}, 0, len(raw))
+		for _, item := range raw {
+			value, err := field.parse(f.Field, strings.TrimSpace(item))
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return clause.IN{Column: col, Values: values}, nil
+	}
+
+	value, err := field.parse(f.Field, f.Value)
+	if err != nil {
+		return nil, err
+	}
+	switch f.Op {
+	case OpNe:
+		return clause.Neq{Column: col, Value: value}, nil
+	case OpGt:
+		return clause.Gt{Column: col, Value: value}, nil
+	case OpGte:
+		return clause.Gte{Column: col, Value: value}, nil
+	case OpLt:
+		return clause.Lt{Column: col, Value: value}, nil
+	case OpLte:
+		return clause.Lte{Column: col, Value: value}, nil
+	default:
+		return clause.Eq{Column: col, Value: value}, nil
+	}
+}
+
+// parse 按字段类型解析过滤值
+func (field Field) parse(name, value string) (interface{}, error) {
+	switch field.Type {
+	case Int:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, ErrInvalid.WithMessage(i18n.MsgQueryIntValue, name, value)
+		}
+		return n, nil
+	case Time:
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t, nil
+		}
+		if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+			return t, nil
+		}
+		return nil, ErrInvalid.WithMessage(i18n.MsgQueryTimeValue, name, value)
+	default:
+		return value, nil
+	}
+}
+
+// likeExpr 不区分大小写的包含匹配，转义值中的通配符
+func likeExpr(name, value string) clause.Expression {
+	pattern := "%" + likeEscaper.Replace(strings.ToLower(value)) + "%"
+	return clause.Expr{
+		SQL:  "LOWER(?) LIKE ? ESCAPE '" + likeEscape + "'",
+		Vars: []interface{}{column(name), pattern},
+	}
+}
+
+// column 当前表的列，列名会按数据库方言加引号
+func column(name string) clause.Column {
+	return clause.Column{Table: clause.CurrentTable, Name: name}
+}
+
+// includeDeleted 使查询包含已软删除的记录
+// 与 Unscoped 不同，它只作用于当前语句，预加载的关联仍然只包含未删除的记录（GORM 以 soft_delete_enabled 子句标记已处理软删除条件）
+type includeDeleted struct{}
+
+func (includeDeleted) Name() string               { return "soft_delete_enabled" }
+func (includeDeleted) Build(clause.Builder)       {}
+func (includeDeleted) MergeClause(*clause.Clause) {}
+
+// or 组合多个条件，只有一个条件时直接返回该条件
+// （GORM 会把只有一个条件的 OrConditions 与前面的条件以 OR 连接）
+func or(exprs []clause.Expression) clause.Expression {
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	return clause.Or(exprs...)
+}
+
+func contains(items []string, item string) bool {
+	for _, v := range items {
+		if v == item {
+			return true
+		}
+	}
+	return false
+}
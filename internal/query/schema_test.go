@@ -0,0 +1,210 @@
+package query
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go_web/internal/i18n"
+
+	"gorm.io/gorm/clause"
+)
+
+func TestCompile(t *testing.T) {
+	manyValues := strings.TrimSuffix(strings.Repeat("1,", maxInValues+1), ",")
+
+	tests := []struct {
+		name        string
+		spec        *Spec
+		schema      *Schema // 为 nil 时使用 testSchema
+		wantSort    string
+		wantDeleted bool
+		wantConds   []clause.Expression // 为 nil 时不比较
+		wantKey     string
+		wantArgs    []interface{}
+	}{
+		{
+			name:     "nil spec",
+			spec:     nil,
+			wantSort: "id",
+		},
+		{
+			name:     "default sort",
+			spec:     &Spec{},
+			schema:   &Schema{Fields: testSchema.Fields, DefaultSort: []Sort{{Field: "created_at", Desc: true}}},
+			wantSort: "-created_at,id",
+		},
+		{
+			name:     "explicit sort ends with id",
+			spec:     &Spec{Sorts: []Sort{{Field: "name"}, {Field: "created_at", Desc: true}}},
+			wantSort: "name,-created_at,id",
+		},
+		{
+			name:     "explicit id keeps position",
+			spec:     &Spec{Sorts: []Sort{{Field: "id", Desc: true}, {Field: "name"}}},
+			wantSort: "-id,name",
+		},
+		{
+			name:     "duplicate sort ignored",
+			spec:     &Spec{Sorts: []Sort{{Field: "name"}, {Field: "name", Desc: true}}},
+			wantSort: "name,id",
+		},
+		{
+			name:     "int filter parsed",
+			spec:     &Spec{Filters: []Filter{{Field: "age", Op: OpGte, Value: "3"}}},
+			wantSort: "id",
+			wantConds: []clause.Expression{
+				clause.Gte{Column: column("age"), Value: int64(3)},
+			},
+		},
+		{
+			name:     "in filter trims values",
+			spec:     &Spec{Filters: []Filter{{Field: "age", Op: OpIn, Value: "1, 2"}}},
+			wantSort: "id",
+			wantConds: []clause.Expression{
+				clause.IN{Column: column("age"), Values: []interface{}{int64(1), int64(2)}},
+			},
+		},
+		{
+			name:     "time filter",
+			spec:     &Spec{Filters: []Filter{{Field: "created_at", Op: OpLt, Value: "2024-01-02T03:04:05Z"}}},
+			wantSort: "id",
+			wantConds: []clause.Expression{
+				clause.Lt{Column: column("created_at"), Value: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+			},
+		},
+		{
+			name:     "like escapes wildcards",
+			spec:     &Spec{Filters: []Filter{{Field: "name", Op: OpLike, Value: "50%_A!"}}},
+			wantSort: "id",
+			wantConds: []clause.Expression{
+				likeExpr("name", "50%_A!"),
+			},
+		},
+		{
+			name:      "search",
+			spec:      &Spec{Search: "bob"},
+			wantSort:  "id",
+			wantConds: []clause.Expression{likeExpr("name", "bob")},
+		},
+		{
+			name:        "include deleted",
+			spec:        &Spec{Deleted: DeletedInclude},
+			wantSort:    "id",
+			wantDeleted: true,
+			wantConds:   []clause.Expression{},
+		},
+		{
+			name:        "only deleted",
+			spec:        &Spec{Deleted: DeletedOnly},
+			wantSort:    "id",
+			wantDeleted: true,
+			wantConds: []clause.Expression{
+				clause.Neq{Column: column("deleted_at"), Value: nil},
+			},
+		},
+		{
+			name:     "unknown filter field",
+			spec:     &Spec{Filters: []Filter{{Field: "password", Op: OpEq, Value: "x"}}},
+			wantKey:  i18n.MsgQueryFilter,
+			wantArgs: []interface{}{"password"},
+		},
+		{
+			name:     "field without ops",
+			spec:     &Spec{Filters: []Filter{{Field: "note", Op: OpEq, Value: "x"}}},
+			wantKey:  i18n.MsgQueryFilter,
+			wantArgs: []interface{}{"note"},
+		},
+		{
+			name:     "operator not allowed",
+			spec:     &Spec{Filters: []Filter{{Field: "name", Op: OpGt, Value: "x"}}},
+			wantKey:  i18n.MsgQueryOperator,
+			wantArgs: []interface{}{"name", OpGt, "eq/ne/like/in"},
+		},
+		{
+			name:     "bad int",
+			spec:     &Spec{Filters: []Filter{{Field: "age", Op: OpEq, Value: "x"}}},
+			wantKey:  i18n.MsgQueryIntValue,
+			wantArgs: []interface{}{"age", "x"},
+		},
+		{
+			name:     "bad int in list",
+			spec:     &Spec{Filters: []Filter{{Field: "age", Op: OpIn, Value: "1,x"}}},
+			wantKey:  i18n.MsgQueryIntValue,
+			wantArgs: []interface{}{"age", "x"},
+		},
+		{
+			name:     "too many in values",
+			spec:     &Spec{Filters: []Filter{{Field: "age", Op: OpIn, Value: manyValues}}},
+			wantKey:  i18n.MsgQueryInValues,
+			wantArgs: []interface{}{"age", maxInValues},
+		},
+		{
+			name:     "bad time",
+			spec:     &Spec{Filters: []Filter{{Field: "created_at", Op: OpGt, Value: "yesterday"}}},
+			wantKey:  i18n.MsgQueryTimeValue,
+			wantArgs: []interface{}{"created_at", "yesterday"},
+		},
+		{
+			name:     "sort not allowed",
+			spec:     &Spec{Sorts: []Sort{{Field: "age"}}},
+			wantKey:  i18n.MsgQuerySort,
+			wantArgs: []interface{}{"age"},
+		},
+		{
+			name:     "sort unknown",
+			spec:     &Spec{Sorts: []Sort{{Field: "password"}}},
+			wantKey:  i18n.MsgQuerySort,
+			wantArgs: []interface{}{"password"},
+		},
+		{
+			name:    "search unsupported",
+			spec:    &Spec{Search: "bob"},
+			schema:  &Schema{Fields: testSchema.Fields},
+			wantKey: i18n.MsgQuerySearch,
+		},
+		{
+			name:    "include deleted unsupported",
+			spec:    &Spec{Deleted: DeletedInclude},
+			schema:  &Schema{Fields: testSchema.Fields},
+			wantKey: i18n.MsgQueryIncludeDeletedUnsupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := testSchema
+			if tt.schema != nil {
+				schema = *tt.schema
+			}
+			q, err := tt.spec.compile(schema)
+			checkErr(t, err, tt.wantKey, tt.wantArgs...)
+			if tt.wantKey != "" {
+				return
+			}
+			if got := q.sortKey(); got != tt.wantSort {
+				t.Errorf("sort = %q, want %q", got, tt.wantSort)
+			}
+			if q.withDeleted != tt.wantDeleted {
+				t.Errorf("withDeleted = %v, want %v", q.withDeleted, tt.wantDeleted)
+			}
+			if tt.wantConds != nil {
+				conds := q.conditions
+				if conds == nil {
+					conds = []clause.Expression{}
+				}
+				if !reflect.DeepEqual(conds, tt.wantConds) {
+					t.Errorf("conditions = %#v, want %#v", conds, tt.wantConds)
+				}
+			}
+		})
+	}
+}
+
+func TestLikeExprPattern(t *testing.T) {
+	expr := likeExpr("name", "50%_A!").(clause.Expr)
+	if got, want := expr.Vars[1], "%50!%!_a!!%"; got != want {
+		t.Fatalf("pattern = %q, want %q", got, want)
+	}
+}
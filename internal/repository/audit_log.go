@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"go_web/internal/database"
+
+	"gorm.io/gorm"
+)
+
+// AuditLogFilter 审计日志过滤条件
+type AuditLogFilter struct {
+	TableName string
+	RecordID  uint
+	Action    string
+	UserID    uint
+	Actor     string // 操作来源（http/cli）
+	RequestID string
+	StartTime *time.Time // 起始时间（包含）
+	EndTime   *time.Time // 结束时间（不包含）
+}
+
+type AuditLogRepository interface {
+	Create(ctx context.Context, auditLog *database.AuditLog) error
+	GetByID(ctx context.Context, id uint) (*database.AuditLog, error)
+	// FindInBatches 按主键顺序分批读取，避免一次性加载全部记录
+	FindInBatches(ctx context.Context, filter AuditLogFilter, batchSize int, fn func(batch []*database.AuditLog) error) error
+	// DeleteByIDRange 删除主键在 [minID, maxID] 区间且早于 before 的记录
+	DeleteByIDRange(ctx context.Context, minID, maxID uint, before time.Time) (int64, error)
+	// HTTP 请求审计
+	GetHTTPEventByRequestID(ctx context.Context, requestID string) (*database.HTTPAuditEvent, error)
+	ListByRequestID(ctx context.Context, requestID string) ([]*database.AuditLog, error)
+	// 回滚
+	// FindRecord 按主键查询审计记录对应的业务数据（包含已软删除的记录）
+	FindRecord(ctx context.Context, dest interface{}, id uint) error
+	// RevertRecord 在同一事务中将记录恢复为 values 并写入回滚审计日志
+	// unique 为需要校验唯一性的列及其值，已有其他未删除记录使用时返回 gorm.ErrDuplicatedKey
+	RevertRecord(ctx context.Context, model interface{}, id uint, values map[string]interface{}, unique map[string]interface{}, entry *database.AuditLog) error
+	// RestoreLink 在同一事务中重新建立被解除的关联 record 并写入回滚审计日志（entry.RecordID 设为新关联的ID）
+	// 已存在 keys 相同的关联（包括已软删除的）时返回 gorm.ErrDuplicatedKey
+	RestoreLink(ctx context.Context, record interface{}, keys map[string]interface{}, entry *database.AuditLog) error
+}
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+func (r *auditLogRepository) Create(ctx context.Context, auditLog *database.AuditLog) error {
+	return r.db.WithContext(ctx).Create(auditLog).Error
+}
+
+func (r *auditLogRepository) GetByID(ctx context.Context, id uint) (*database.AuditLog, error) {
+	var auditLog database.AuditLog
+	err := r.db.WithContext(ctx).First(&auditLog, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &auditLog, nil
+}
+
+func (r *auditLogRepository) FindInBatches(ctx context.Context, filter AuditLogFilter, batchSize int, fn func(batch []*database.AuditLog) error) error {
+	var batch []*database.AuditLog
+	result := r.applyFilter(r.db.WithContext(ctx).Model(&database.AuditLog{}), filter).
+		FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		})
+	return result.Error
+}
+
+func (r *auditLogRepository) DeleteByIDRange(ctx context.Context, minID, maxID uint, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("id BETWEEN ? AND ?", minID, maxID).
+		Where("created_at < ?", before).
+		Delete(&database.AuditLog{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *auditLogRepository) GetHTTPEventByRequestID(ctx context.Context, requestID string) (*database.HTTPAuditEvent, error) {
+	var event database.HTTPAuditEvent
+	err := r.db.WithContext(ctx).Where("request_id = ?", requestID).Order("id").First(&event).Error
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (r *auditLogRepository) ListByRequestID(ctx context.Context, requestID string) ([]*database.AuditLog, error) {
+	var auditLogs []*database.AuditLog
+	err := r.db.WithContext(ctx).Where("request_id = ?", requestID).Order("id").Find(&auditLogs).Error
+	return auditLogs, err
+}
+
+func (r *auditLogRepository) FindRecord(ctx context.Context, dest interface{}, id uint) error {
+	return r.db.WithContext(ctx).Unscoped().First(dest, id).Error
+}
+
+func (r *auditLogRepository) RevertRecord(ctx context.Context, model interface{}, id uint, values map[string]interface{}, unique map[string]interface{}, entry *database.AuditLog) error {
+	// 回滚操作由 entry 记录审计日志，跳过插件自动生成的 update 记录
+	ctx = context.WithValue(ctx, database.AuditSkipKey, true)
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 在写入的事务中校验唯一性；并发写入同一值时由唯一索引兜底，同样返回 gorm.ErrDuplicatedKey
+		for column, value := range unique {
+			var count int64
+			// 唯一索引只约束未删除的记录，已软删除的同名记录不冲突
+			err := tx.Model(model).Where(column+" = ?", value).Where("id <> ?", id).Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				return gorm.ErrDuplicatedKey
+			}
+		}
+
+		result := tx.Unscoped().Model(model).Where("id = ?", id).Updates(values)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Create(entry).Error
+	})
+}
+
+func (r *auditLogRepository) RestoreLink(ctx context.Context, record interface{}, keys map[string]interface{}, entry *database.AuditLog) error {
+	// 回滚操作由 entry 记录审计日志，跳过插件自动生成的 create 记录
+	ctx = context.WithValue(ctx, database.AuditSkipKey, true)
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 关联表的唯一索引包括已软删除的行
+		var count int64
+		if err := tx.Unscoped().Model(record).Where(keys).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return gorm.ErrDuplicatedKey
+		}
+
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+		entry.RecordID = uint(reflect.Indirect(reflect.ValueOf(record)).FieldByName("ID").Uint())
+		return tx.Create(entry).Error
+	})
+}
+
+// applyFilter 将过滤条件转换为查询条件
+func (r *auditLogRepository) applyFilter(db *gorm.DB, filter AuditLogFilter) *gorm.DB {
+	if filter.TableName != "" {
+		db = db.Where("table_name = ?", filter.TableName)
+	}
+	if filter.RecordID > 0 {
+		db = db.Where("record_id = ?", filter.RecordID)
+	}
+	if filter.Action != "" {
+		db = db.Where("action = ?", filter.Action)
+	}
+	if filter.UserID > 0 {
+		db = db.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Actor != "" {
+		db = db.Where("actor = ?", filter.Actor)
+	}
+	if filter.RequestID != "" {
+		db = db.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.StartTime != nil {
+		db = db.Where("created_at >= ?", *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		db = db.Where("created_at < ?", *filter.EndTime)
+	}
+	return db
+}
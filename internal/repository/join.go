@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"fmt"
+	"reflect"
+
+	"go_web/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// joinModels 关联表对应的模型切片，删除关联时按模型逐行删除，由审计插件为每一行记录带快照的 purge 日志
+var joinModels = map[string]func() interface{}{
+	"user_roles":       func() interface{} { return &[]*model.UserRole{} },
+	"role_permissions": func() interface{} { return &[]*model.RolePermission{} },
+}
+
+// deleteJoinRows 物理删除关联表 tables 中外键列 column 为 id 的行（包括已软删除的行），与接口移除关联的行为一致
+// 逐行通过模型删除而不是执行一条 DELETE 语句，审计日志中保留每一条被解除的关联，可以通过审计日志回滚恢复
+func deleteJoinRows(db *gorm.DB, column string, id uint, tables ...string) error {
+	for _, table := range tables {
+		newRows, ok := joinModels[table]
+		if !ok {
+			return fmt.Errorf("不支持的关联表: %s", table)
+		}
+
+		rows := newRows()
+		if err := db.Unscoped().Where(column+" = ?", id).Order("id").Find(rows).Error; err != nil {
+			return err
+		}
+		list := reflect.ValueOf(rows).Elem()
+		for i := 0; i < list.Len(); i++ {
+			if err := db.Unscoped().Delete(list.Index(i).Interface()).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -1,21 +1,50 @@
 package repository
 
 import (
-	"errors"
+	"context"
 
 	"go_web/internal/model"
+	"go_web/internal/query"
 
 	"gorm.io/gorm"
 )
 
+// permissionQuerySchema 权限列表允许的过滤、排序、搜索字段以及返回的字段和展开的关联
+var permissionQuerySchema = query.Schema{
+	Fields: map[string]query.Field{
+		"id":           {Column: "id", Type: query.Int, Ops: query.NumberOps, Sortable: true},
+		"name":         {Column: "name", Type: query.String, Ops: query.StringOps, Sortable: true},
+		"display_name": {Column: "display_name", Type: query.String, Ops: query.StringOps, Sortable: true},
+		"resource":     {Column: "resource", Type: query.String, Ops: query.StringOps, Sortable: true},
+		"action":       {Column: "action", Type: query.String, Ops: query.StringOps, Sortable: true},
+		"status":       {Column: "status", Type: query.Int, Ops: []string{query.OpEq, query.OpNe, query.OpIn}, Sortable: true},
+		"created_at":   {Column: "created_at", Type: query.Time, Ops: query.TimeOps, Sortable: true},
+		"updated_at":   {Column: "updated_at", Type: query.Time, Ops: query.TimeOps, Sortable: true},
+		"deleted_at":   {Column: "deleted_at", Type: query.Time, Ops: query.TimeOps}, // 只能过滤，配合 include_deleted 使用
+	},
+	Search:     []string{"name", "display_name", "description"},
+	SoftDelete: true,
+	Select:     []string{"id", "name", "display_name", "description", "resource", "action", "status", "version", "created_at", "updated_at", "deleted_at"},
+	Keep:       []string{"version"},
+	Includes:   map[string]string{"roles": "Roles"},
+}
+
 type PermissionRepository interface {
-	Create(permission *model.Permission) error
-	GetByID(id uint) (*model.Permission, error)
-	GetByName(name string) (*model.Permission, error)
-	Update(permission *model.Permission) error
-	Delete(id uint) error
-	List(offset, limit int) ([]*model.Permission, int64, error)
-	GetByResourceAndAction(resource, action string) (*model.Permission, error)
+	Create(ctx context.Context, permission *model.Permission) error
+	GetByID(ctx context.Context, id uint) (*model.Permission, error)
+	GetProjected(ctx context.Context, id uint, proj *query.Projection) (*model.Permission, error) // 按 fields 和 include 查询列和预加载关联，proj 为 nil 时同 GetByID
+	GetByName(ctx context.Context, name string) (*model.Permission, error)
+	Update(ctx context.Context, permission *model.Permission) error                                            // 按版本号条件更新，期间被修改时返回 apperr.ErrVersionConflict
+	Patch(ctx context.Context, permission *model.Permission, fields map[string]interface{}) error              // 只更新 fields 中的列，其他同 Update
+	Delete(ctx context.Context, id uint, version uint) error                                                   // version 为 0 时不校验版本号
+	GetWithDeleted(ctx context.Context, id uint) (*model.Permission, error)                                    // 包含已软删除的记录，不加载关联
+	Restore(ctx context.Context, permission *model.Permission) error                                           // 按版本号条件恢复已软删除的记录，期间被修改时返回 apperr.ErrVersionConflict
+	Purge(ctx context.Context, id uint) error                                                                  // 彻底删除已软删除的记录及其关联，记录不存在或未被删除时返回 gorm.ErrRecordNotFound
+	List(ctx context.Context, spec *query.Spec, page query.Page) ([]*model.Permission, *query.PageInfo, error) // spec 为 nil 时不过滤
+	GetByResourceAndAction(ctx context.Context, resource, action string) (*model.Permission, error)
+	// 删除前的引用检查
+	GetUsingRoles(ctx context.Context, permissionID uint, limit int) ([]*model.Role, int64, error) // 使用该权限的角色（不含已删除的角色和关联），最多返回 limit 个，以及总数
+	UnlinkRoles(ctx context.Context, permissionID uint) error                                      // 逐行物理删除该权限与所有角色的关联，每一行记录审计日志
 }
 
 type permissionRepository struct {
@@ -26,61 +55,98 @@ func NewPermissionRepository(db *gorm.DB) PermissionRepository {
 	return &permissionRepository{db: db}
 }
 
-func (r *permissionRepository) Create(permission *model.Permission) error {
-	return r.db.Create(permission).Error
+func (r *permissionRepository) Create(ctx context.Context, permission *model.Permission) error {
+	return conn(ctx, r.db).Create(permission).Error
 }
 
-func (r *permissionRepository) GetByID(id uint) (*model.Permission, error) {
-	var permission model.Permission
-	err := r.db.Preload("Roles").First(&permission, id).Error
+func (r *permissionRepository) GetByID(ctx context.Context, id uint) (*model.Permission, error) {
+	return r.GetProjected(ctx, id, nil)
+}
+
+func (r *permissionRepository) GetProjected(ctx context.Context, id uint, proj *query.Projection) (*model.Permission, error) {
+	db, err := query.Project(conn(ctx, r.db), permissionQuerySchema, proj, "Roles")
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, err
-		}
+		return nil, err
+	}
+
+	var permission model.Permission
+	if err := db.First(&permission, id).Error; err != nil {
 		return nil, err
 	}
 	return &permission, nil
 }
 
-func (r *permissionRepository) GetByName(name string) (*model.Permission, error) {
+func (r *permissionRepository) GetByName(ctx context.Context, name string) (*model.Permission, error) {
 	var permission model.Permission
-	err := r.db.Where("name = ?", name).First(&permission).Error
+	err := conn(ctx, r.db).Where("name = ?", name).First(&permission).Error
 	if err != nil {
 		return nil, err
 	}
 	return &permission, nil
 }
 
-func (r *permissionRepository) Update(permission *model.Permission) error {
-	return r.db.Save(permission).Error
+func (r *permissionRepository) Update(ctx context.Context, permission *model.Permission) error {
+	return updateWithVersion(conn(ctx, r.db), permission, &permission.Version)
 }
 
-func (r *permissionRepository) Delete(id uint) error {
-	return r.db.Delete(&model.Permission{}, id).Error
+func (r *permissionRepository) Patch(ctx context.Context, permission *model.Permission, fields map[string]interface{}) error {
+	return patchWithVersion(conn(ctx, r.db), permission, &permission.Version, fields)
 }
 
-func (r *permissionRepository) List(offset, limit int) ([]*model.Permission, int64, error) {
-	var permissions []*model.Permission
-	var total int64
+func (r *permissionRepository) Delete(ctx context.Context, id uint, version uint) error {
+	return deleteWithVersion(conn(ctx, r.db), &model.Permission{}, id, version)
+}
 
-	err := r.db.Model(&model.Permission{}).Count(&total).Error
-	if err != nil {
-		return nil, 0, err
+func (r *permissionRepository) GetWithDeleted(ctx context.Context, id uint) (*model.Permission, error) {
+	var permission model.Permission
+	if err := getWithDeleted(conn(ctx, r.db), &permission, id); err != nil {
+		return nil, err
 	}
+	return &permission, nil
+}
+
+func (r *permissionRepository) Restore(ctx context.Context, permission *model.Permission) error {
+	return restoreWithVersion(conn(ctx, r.db), permission, &permission.Version)
+}
+
+func (r *permissionRepository) Purge(ctx context.Context, id uint) error {
+	return purgeDeleted(conn(ctx, r.db), &model.Permission{}, id, "permission_id", "role_permissions")
+}
 
-	err = r.db.Preload("Roles").Offset(offset).Limit(limit).Find(&permissions).Error
+func (r *permissionRepository) List(ctx context.Context, spec *query.Spec, page query.Page) ([]*model.Permission, *query.PageInfo, error) {
+	var permissions []*model.Permission
+	info, err := query.Find(conn(ctx, r.db), permissionQuerySchema, spec, page, &permissions, "Roles")
 	if err != nil {
-		return nil, 0, err
+		return nil, nil, err
 	}
-
-	return permissions, total, nil
+	return permissions, info, nil
 }
 
-func (r *permissionRepository) GetByResourceAndAction(resource, action string) (*model.Permission, error) {
+func (r *permissionRepository) GetByResourceAndAction(ctx context.Context, resource, action string) (*model.Permission, error) {
 	var permission model.Permission
-	err := r.db.Where("resource = ? AND action = ?", resource, action).First(&permission).Error
+	err := conn(ctx, r.db).Where("resource = ? AND action = ?", resource, action).First(&permission).Error
 	if err != nil {
 		return nil, err
 	}
 	return &permission, nil
 }
+
+func (r *permissionRepository) GetUsingRoles(ctx context.Context, permissionID uint, limit int) ([]*model.Role, int64, error) {
+	db := conn(ctx, r.db).Model(&model.Role{}).
+		Joins("JOIN role_permissions ON role_permissions.role_id = roles.id").
+		Where("role_permissions.permission_id = ?", permissionID).
+		Where("role_permissions.deleted_at IS NULL").
+		Session(&gorm.Session{})
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil || total == 0 {
+		return nil, 0, err
+	}
+	var roles []*model.Role
+	err := db.Select("roles.id", "roles.name", "roles.display_name").Order("roles.id").Limit(limit).Find(&roles).Error
+	return roles, total, err
+}
+
+func (r *permissionRepository) UnlinkRoles(ctx context.Context, permissionID uint) error {
+	return deleteJoinRows(conn(ctx, r.db), "permission_id", permissionID, "role_permissions")
+}
@@ -1,28 +1,55 @@
 package repository
 
 import (
-	"errors"
+	"context"
 
 	"go_web/internal/model"
+	"go_web/internal/query"
 
 	"gorm.io/gorm"
 )
 
+// roleQuerySchema 角色列表允许的过滤、排序、搜索字段以及返回的字段和展开的关联
+var roleQuerySchema = query.Schema{
+	Fields: map[string]query.Field{
+		"id":           {Column: "id", Type: query.Int, Ops: query.NumberOps, Sortable: true},
+		"name":         {Column: "name", Type: query.String, Ops: query.StringOps, Sortable: true},
+		"display_name": {Column: "display_name", Type: query.String, Ops: query.StringOps, Sortable: true},
+		"status":       {Column: "status", Type: query.Int, Ops: []string{query.OpEq, query.OpNe, query.OpIn}, Sortable: true},
+		"created_at":   {Column: "created_at", Type: query.Time, Ops: query.TimeOps, Sortable: true},
+		"updated_at":   {Column: "updated_at", Type: query.Time, Ops: query.TimeOps, Sortable: true},
+		"deleted_at":   {Column: "deleted_at", Type: query.Time, Ops: query.TimeOps}, // 只能过滤，配合 include_deleted 使用
+	},
+	Search:     []string{"name", "display_name", "description"},
+	SoftDelete: true,
+	Select:     []string{"id", "name", "display_name", "description", "status", "version", "created_at", "updated_at", "deleted_at"},
+	Keep:       []string{"version"},
+	Includes:   map[string]string{"permissions": "Permissions", "users": "Users"},
+}
+
 type RoleRepository interface {
-	Create(role *model.Role) error
-	GetByID(id uint) (*model.Role, error)
-	GetByName(name string) (*model.Role, error)
-	Update(role *model.Role) error
-	Delete(id uint) error
-	List(offset, limit int) ([]*model.Role, int64, error)
+	Create(ctx context.Context, role *model.Role) error
+	GetByID(ctx context.Context, id uint) (*model.Role, error)
+	GetProjected(ctx context.Context, id uint, proj *query.Projection) (*model.Role, error) // 按 fields 和 include 查询列和预加载关联，proj 为 nil 时同 GetByID
+	GetByName(ctx context.Context, name string) (*model.Role, error)
+	Update(ctx context.Context, role *model.Role) error                                                  // 按版本号条件更新，期间被修改时返回 apperr.ErrVersionConflict
+	Patch(ctx context.Context, role *model.Role, fields map[string]interface{}) error                    // 只更新 fields 中的列，其他同 Update
+	Delete(ctx context.Context, id uint, version uint) error                                             // version 为 0 时不校验版本号
+	GetWithDeleted(ctx context.Context, id uint) (*model.Role, error)                                    // 包含已软删除的记录，不加载关联
+	Restore(ctx context.Context, role *model.Role) error                                                 // 按版本号条件恢复已软删除的记录，期间被修改时返回 apperr.ErrVersionConflict
+	Purge(ctx context.Context, id uint) error                                                            // 彻底删除已软删除的记录及其关联，记录不存在或未被删除时返回 gorm.ErrRecordNotFound
+	List(ctx context.Context, spec *query.Spec, page query.Page) ([]*model.Role, *query.PageInfo, error) // spec 为 nil 时不过滤
 	// 角色权限管理
-	AssignPermissions(roleID uint, permissionIDs []uint) error
-	RemovePermissions(roleID uint, permissionIDs []uint) error
-	GetPermissions(roleID uint) ([]*model.Permission, error)
+	AssignPermissions(ctx context.Context, roleID uint, permissionIDs []uint) error
+	RemovePermissions(ctx context.Context, roleID uint, permissionIDs []uint) error
+	GetPermissions(ctx context.Context, roleID uint) ([]*model.Permission, error)
 	// 用户角色管理
-	AssignUsers(roleID uint, userIDs []uint) error
-	RemoveUsers(roleID uint, userIDs []uint) error
-	GetUsers(roleID uint) ([]*model.User, error)
+	AssignUsers(ctx context.Context, roleID uint, userIDs []uint) error
+	RemoveUsers(ctx context.Context, roleID uint, userIDs []uint) error
+	GetUsers(ctx context.Context, roleID uint) ([]*model.User, error)
+	// 删除前的引用检查
+	GetHolders(ctx context.Context, roleID uint, limit int) ([]*model.User, int64, error) // 拥有该角色的用户（不含已删除的用户和关联），最多返回 limit 个，以及总数
+	UnlinkAll(ctx context.Context, roleID uint) error                                     // 逐行物理删除该角色与所有用户、权限的关联，每一行记录审计日志
 }
 
 type roleRepository struct {
@@ -33,130 +60,169 @@ func NewRoleRepository(db *gorm.DB) RoleRepository {
 	return &roleRepository{db: db}
 }
 
-func (r *roleRepository) Create(role *model.Role) error {
-	return r.db.Create(role).Error
+func (r *roleRepository) Create(ctx context.Context, role *model.Role) error {
+	return conn(ctx, r.db).Create(role).Error
 }
 
-func (r *roleRepository) GetByID(id uint) (*model.Role, error) {
-	var role model.Role
-	err := r.db.Preload("Permissions").Preload("Users").First(&role, id).Error
+func (r *roleRepository) GetByID(ctx context.Context, id uint) (*model.Role, error) {
+	return r.GetProjected(ctx, id, nil)
+}
+
+func (r *roleRepository) GetProjected(ctx context.Context, id uint, proj *query.Projection) (*model.Role, error) {
+	db, err := query.Project(conn(ctx, r.db), roleQuerySchema, proj, "Permissions", "Users")
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, err
-		}
+		return nil, err
+	}
+
+	var role model.Role
+	if err := db.First(&role, id).Error; err != nil {
 		return nil, err
 	}
 	return &role, nil
 }
 
-func (r *roleRepository) GetByName(name string) (*model.Role, error) {
+func (r *roleRepository) GetByName(ctx context.Context, name string) (*model.Role, error) {
 	var role model.Role
-	err := r.db.Where("name = ?", name).First(&role).Error
+	err := conn(ctx, r.db).Where("name = ?", name).First(&role).Error
 	if err != nil {
 		return nil, err
 	}
 	return &role, nil
 }
 
-func (r *roleRepository) Update(role *model.Role) error {
-	return r.db.Save(role).Error
+func (r *roleRepository) Update(ctx context.Context, role *model.Role) error {
+	return updateWithVersion(conn(ctx, r.db), role, &role.Version)
 }
 
-func (r *roleRepository) Delete(id uint) error {
-	return r.db.Delete(&model.Role{}, id).Error
+func (r *roleRepository) Patch(ctx context.Context, role *model.Role, fields map[string]interface{}) error {
+	return patchWithVersion(conn(ctx, r.db), role, &role.Version, fields)
 }
 
-func (r *roleRepository) List(offset, limit int) ([]*model.Role, int64, error) {
-	var roles []*model.Role
-	var total int64
+func (r *roleRepository) Delete(ctx context.Context, id uint, version uint) error {
+	return deleteWithVersion(conn(ctx, r.db), &model.Role{}, id, version)
+}
 
-	err := r.db.Model(&model.Role{}).Count(&total).Error
-	if err != nil {
-		return nil, 0, err
+func (r *roleRepository) GetWithDeleted(ctx context.Context, id uint) (*model.Role, error) {
+	var role model.Role
+	if err := getWithDeleted(conn(ctx, r.db), &role, id); err != nil {
+		return nil, err
 	}
+	return &role, nil
+}
+
+func (r *roleRepository) Restore(ctx context.Context, role *model.Role) error {
+	return restoreWithVersion(conn(ctx, r.db), role, &role.Version)
+}
+
+func (r *roleRepository) Purge(ctx context.Context, id uint) error {
+	return purgeDeleted(conn(ctx, r.db), &model.Role{}, id, "role_id", "role_permissions", "user_roles")
+}
 
-	err = r.db.Preload("Permissions").Offset(offset).Limit(limit).Find(&roles).Error
+func (r *roleRepository) List(ctx context.Context, spec *query.Spec, page query.Page) ([]*model.Role, *query.PageInfo, error) {
+	var roles []*model.Role
+	info, err := query.Find(conn(ctx, r.db), roleQuerySchema, spec, page, &roles, "Permissions")
 	if err != nil {
-		return nil, 0, err
+		return nil, nil, err
 	}
-
-	return roles, total, nil
+	return roles, info, nil
 }
 
-func (r *roleRepository) AssignPermissions(roleID uint, permissionIDs []uint) error {
+func (r *roleRepository) AssignPermissions(ctx context.Context, roleID uint, permissionIDs []uint) error {
 	var permissions []model.Permission
-	if err := r.db.Find(&permissions, permissionIDs).Error; err != nil {
+	if err := conn(ctx, r.db).Find(&permissions, permissionIDs).Error; err != nil {
 		return err
 	}
 
 	var role model.Role
-	if err := r.db.First(&role, roleID).Error; err != nil {
+	if err := conn(ctx, r.db).First(&role, roleID).Error; err != nil {
 		return err
 	}
 
-	return r.db.Model(&role).Association("Permissions").Append(permissions)
+	return conn(ctx, r.db).Model(&role).Association("Permissions").Append(permissions)
 }
 
-func (r *roleRepository) RemovePermissions(roleID uint, permissionIDs []uint) error {
+func (r *roleRepository) RemovePermissions(ctx context.Context, roleID uint, permissionIDs []uint) error {
 	var permissions []model.Permission
-	if err := r.db.Find(&permissions, permissionIDs).Error; err != nil {
+	if err := conn(ctx, r.db).Find(&permissions, permissionIDs).Error; err != nil {
 		return err
 	}
 
 	var role model.Role
-	if err := r.db.First(&role, roleID).Error; err != nil {
+	if err := conn(ctx, r.db).First(&role, roleID).Error; err != nil {
 		return err
 	}
 
-	return r.db.Model(&role).Association("Permissions").Delete(permissions)
+	return conn(ctx, r.db).Model(&role).Association("Permissions").Delete(permissions)
 }
 
-func (r *roleRepository) GetPermissions(roleID uint) ([]*model.Permission, error) {
+func (r *roleRepository) GetPermissions(ctx context.Context, roleID uint) ([]*model.Permission, error) {
 	var role model.Role
-	if err := r.db.First(&role, roleID).Error; err != nil {
+	if err := conn(ctx, r.db).First(&role, roleID).Error; err != nil {
 		return nil, err
 	}
 
 	var permissions []*model.Permission
-	err := r.db.Model(&role).Association("Permissions").Find(&permissions)
+	// 关联表的软删除不会自动过滤
+	err := conn(ctx, r.db).Model(&role).Where("role_permissions.deleted_at IS NULL").Association("Permissions").Find(&permissions)
 	return permissions, err
 }
 
-func (r *roleRepository) AssignUsers(roleID uint, userIDs []uint) error {
+func (r *roleRepository) AssignUsers(ctx context.Context, roleID uint, userIDs []uint) error {
 	var users []model.User
-	if err := r.db.Find(&users, userIDs).Error; err != nil {
+	if err := conn(ctx, r.db).Find(&users, userIDs).Error; err != nil {
 		return err
 	}
 
 	var role model.Role
-	if err := r.db.First(&role, roleID).Error; err != nil {
+	if err := conn(ctx, r.db).First(&role, roleID).Error; err != nil {
 		return err
 	}
 
-	return r.db.Model(&role).Association("Users").Append(users)
+	return conn(ctx, r.db).Model(&role).Association("Users").Append(users)
 }
 
-func (r *roleRepository) RemoveUsers(roleID uint, userIDs []uint) error {
+func (r *roleRepository) RemoveUsers(ctx context.Context, roleID uint, userIDs []uint) error {
 	var users []model.User
-	if err := r.db.Find(&users, userIDs).Error; err != nil {
+	if err := conn(ctx, r.db).Find(&users, userIDs).Error; err != nil {
 		return err
 	}
 
 	var role model.Role
-	if err := r.db.First(&role, roleID).Error; err != nil {
+	if err := conn(ctx, r.db).First(&role, roleID).Error; err != nil {
 		return err
 	}
 
-	return r.db.Model(&role).Association("Users").Delete(users)
+	return conn(ctx, r.db).Model(&role).Association("Users").Delete(users)
 }
 
-func (r *roleRepository) GetUsers(roleID uint) ([]*model.User, error) {
+func (r *roleRepository) GetUsers(ctx context.Context, roleID uint) ([]*model.User, error) {
 	var role model.Role
-	if err := r.db.First(&role, roleID).Error; err != nil {
+	if err := conn(ctx, r.db).First(&role, roleID).Error; err != nil {
 		return nil, err
 	}
 
 	var users []*model.User
-	err := r.db.Model(&role).Association("Users").Find(&users)
+	// 关联表的软删除不会自动过滤
+	err := conn(ctx, r.db).Model(&role).Where("user_roles.deleted_at IS NULL").Association("Users").Find(&users)
 	return users, err
 }
+
+func (r *roleRepository) GetHolders(ctx context.Context, roleID uint, limit int) ([]*model.User, int64, error) {
+	db := conn(ctx, r.db).Model(&model.User{}).
+		Joins("JOIN user_roles ON user_roles.user_id = users.id").
+		Where("user_roles.role_id = ?", roleID).
+		Where("user_roles.deleted_at IS NULL").
+		Session(&gorm.Session{})
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil || total == 0 {
+		return nil, 0, err
+	}
+	var users []*model.User
+	err := db.Select("users.id", "users.name", "users.email").Order("users.id").Limit(limit).Find(&users).Error
+	return users, total, err
+}
+
+func (r *roleRepository) UnlinkAll(ctx context.Context, roleID uint) error {
+	return deleteJoinRows(conn(ctx, r.db), "role_id", roleID, "user_roles", "role_permissions")
+}
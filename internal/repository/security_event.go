@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go_web/internal/model"
+	"go_web/internal/query"
+
+	"gorm.io/gorm"
+)
+
+// SecurityEventFilter 安全事件过滤条件
+type SecurityEventFilter struct {
+	EventType string
+	UserID    uint
+	Email     string
+	IP        string
+	StartTime *time.Time // 起始时间（包含）
+	EndTime   *time.Time // 结束时间（不包含）
+}
+
+// securityEventQuerySchema 安全事件按 id 倒序（最新的在前）分页
+var securityEventQuerySchema = query.Schema{
+	Fields:      map[string]query.Field{"id": {Column: "id", Type: query.Int, Sortable: true}},
+	DefaultSort: []query.Sort{{Field: "id", Desc: true}},
+}
+
+type SecurityEventRepository interface {
+	Create(ctx context.Context, event *model.SecurityEvent) error
+	List(ctx context.Context, filter SecurityEventFilter, page query.Page) ([]*model.SecurityEvent, *query.PageInfo, error)
+}
+
+type securityEventRepository struct {
+	db *gorm.DB
+}
+
+func NewSecurityEventRepository(db *gorm.DB) SecurityEventRepository {
+	return &securityEventRepository{db: db}
+}
+
+func (r *securityEventRepository) Create(ctx context.Context, event *model.SecurityEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+func (r *securityEventRepository) List(ctx context.Context, filter SecurityEventFilter, page query.Page) ([]*model.SecurityEvent, *query.PageInfo, error) {
+	var events []*model.SecurityEvent
+	info, err := query.Find(r.applyFilter(r.db.WithContext(ctx), filter), securityEventQuerySchema, nil, page, &events)
+	if err != nil {
+		return nil, nil, err
+	}
+	return events, info, nil
+}
+
+// applyFilter 将过滤条件转换为查询条件
+func (r *securityEventRepository) applyFilter(db *gorm.DB, filter SecurityEventFilter) *gorm.DB {
+	if filter.EventType != "" {
+		db = db.Where("event_type = ?", filter.EventType)
+	}
+	if filter.UserID > 0 {
+		db = db.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Email != "" {
+		db = db.Where("email = ?", filter.Email)
+	}
+	if filter.IP != "" {
+		db = db.Where("ip = ?", filter.IP)
+	}
+	if filter.StartTime != nil {
+		db = db.Where("created_at >= ?", *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		db = db.Where("created_at < ?", *filter.EndTime)
+	}
+	return db
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// getWithDeleted 按ID查询记录，包含已软删除的记录，不加载关联
+func getWithDeleted(db *gorm.DB, record interface{}, id uint) error {
+	return db.Unscoped().First(record, id).Error
+}
+
+// restoreWithVersion 以读取时的版本号为条件恢复已软删除的记录，成功后版本号加一
+// 期间记录被其他请求恢复、修改或彻底删除时返回 apperr.ErrVersionConflict
+func restoreWithVersion(db *gorm.DB, record interface{}, version *uint) error {
+	return patchWithVersion(db.Unscoped().Where("deleted_at IS NOT NULL"), record, version, map[string]interface{}{"deleted_at": nil})
+}
+
+// purgeDeleted 彻底删除已软删除的记录，以及关联表 joinTables 中外键列 column 指向该记录的行（包括已软删除的行）
+// 记录不存在或未被删除时返回 gorm.ErrRecordNotFound，关联表的删除随之回滚；
+// 关联表的每一行都会记录审计日志，调用方应通过 Transactor 执行，回滚时丢弃这些审计日志
+func purgeDeleted(db *gorm.DB, model interface{}, id uint, column string, joinTables ...string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteJoinRows(tx, column, id, joinTables...); err != nil {
+			return err
+		}
+
+		// 最后删除记录本身，审计插件记录 purge 后不会再有可能失败的语句；id 条件放在最前面，审计插件从第一个查询参数中取得记录ID
+		result := tx.Unscoped().Where("id = ?", id).Where("deleted_at IS NOT NULL").Delete(model)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+
+	"go_web/internal/database"
+
+	"gorm.io/gorm"
+)
+
+// Transactor 在同一个事务中执行多个仓储操作
+type Transactor interface {
+	// Transaction 在事务中执行 fn，fn 内通过传入的 ctx 调用的仓储方法都使用该事务，fn 返回错误或 panic 时回滚；
+	// 事务内产生的审计日志在提交后写入，回滚时丢弃。ctx 已在事务中时直接执行 fn
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txKeyType struct{}
+
+type transactor struct {
+	db *gorm.DB
+}
+
+func NewTransactor(db *gorm.DB) Transactor {
+	return &transactor{db: db}
+}
+
+func (t *transactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKeyType{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	ctx, buffer := database.WithAuditBuffer(ctx)
+	err := t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKeyType{}, tx))
+	})
+	if err != nil {
+		return err
+	}
+	buffer.Flush()
+	return nil
+}
+
+// conn 返回 ctx 所在的事务，不在事务中时返回 db
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKeyType{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
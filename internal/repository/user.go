@@ -1,26 +1,51 @@
 package repository
 
 import (
-	"errors"
+	"context"
 
 	"go_web/internal/model"
+	"go_web/internal/query"
 
 	"gorm.io/gorm"
 )
 
+// userQuerySchema 用户列表允许的过滤、排序、搜索字段以及返回的字段和展开的关联
+var userQuerySchema = query.Schema{
+	Fields: map[string]query.Field{
+		"id":         {Column: "id", Type: query.Int, Ops: query.NumberOps, Sortable: true},
+		"name":       {Column: "name", Type: query.String, Ops: query.StringOps, Sortable: true},
+		"email":      {Column: "email", Type: query.String, Ops: query.StringOps, Sortable: true},
+		"status":     {Column: "status", Type: query.Int, Ops: []string{query.OpEq, query.OpNe, query.OpIn}, Sortable: true},
+		"created_at": {Column: "created_at", Type: query.Time, Ops: query.TimeOps, Sortable: true},
+		"updated_at": {Column: "updated_at", Type: query.Time, Ops: query.TimeOps, Sortable: true},
+		"deleted_at": {Column: "deleted_at", Type: query.Time, Ops: query.TimeOps}, // 只能过滤，配合 include_deleted 使用
+	},
+	Search:     []string{"name", "email"},
+	SoftDelete: true,
+	Select:     []string{"id", "name", "email", "status", "version", "language", "created_at", "updated_at", "deleted_at"},
+	Keep:       []string{"version"},
+	Includes:   map[string]string{"roles": "Roles", "roles.permissions": "Roles.Permissions"},
+}
+
 type UserRepository interface {
-	Create(user *model.User) error
-	GetByID(id uint) (*model.User, error)
-	GetByEmail(email string) (*model.User, error)
-	Update(user *model.User) error
-	Delete(id uint) error
-	List(offset, limit int) ([]*model.User, int64, error)
+	Create(ctx context.Context, user *model.User) error
+	GetByID(ctx context.Context, id uint) (*model.User, error)
+	GetProjected(ctx context.Context, id uint, proj *query.Projection) (*model.User, error) // 按 fields 和 include 查询列和预加载关联，proj 为 nil 时同 GetByID
+	GetByEmail(ctx context.Context, email string) (*model.User, error)
+	GetLanguage(ctx context.Context, id uint) (string, error)                                            // 只查询语言偏好，不加载关联
+	Update(ctx context.Context, user *model.User) error                                                  // 按版本号条件更新，期间被修改时返回 apperr.ErrVersionConflict
+	Patch(ctx context.Context, user *model.User, fields map[string]interface{}) error                    // 只更新 fields 中的列，其他同 Update
+	Delete(ctx context.Context, id uint, version uint) error                                             // version 为 0 时不校验版本号
+	GetWithDeleted(ctx context.Context, id uint) (*model.User, error)                                    // 包含已软删除的记录，不加载关联
+	Restore(ctx context.Context, user *model.User) error                                                 // 按版本号条件恢复已软删除的记录，期间被修改时返回 apperr.ErrVersionConflict
+	Purge(ctx context.Context, id uint) error                                                            // 彻底删除已软删除的记录及其关联，记录不存在或未被删除时返回 gorm.ErrRecordNotFound
+	List(ctx context.Context, spec *query.Spec, page query.Page) ([]*model.User, *query.PageInfo, error) // spec 为 nil 时不过滤
 	// 用户角色管理
-	AssignRoles(userID uint, roleIDs []uint) error
-	RemoveRoles(userID uint, roleIDs []uint) error
-	GetRoles(userID uint) ([]*model.Role, error)
+	AssignRoles(ctx context.Context, userID uint, roleIDs []uint) error
+	RemoveRoles(ctx context.Context, userID uint, roleIDs []uint) error
+	GetRoles(ctx context.Context, userID uint) ([]*model.Role, error)
 	// 权限检查
-	HasPermission(userID uint, resource, action string) (bool, error)
+	HasPermission(ctx context.Context, userID uint, resource, action string) (bool, error)
 }
 
 type userRepository struct {
@@ -31,25 +56,30 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
-func (r *userRepository) Create(user *model.User) error {
-	return r.db.Create(user).Error
+func (r *userRepository) Create(ctx context.Context, user *model.User) error {
+	return conn(ctx, r.db).Create(user).Error
 }
 
-func (r *userRepository) GetByID(id uint) (*model.User, error) {
-	var user model.User
-	err := r.db.Preload("Roles").Preload("Roles.Permissions").First(&user, id).Error
+func (r *userRepository) GetByID(ctx context.Context, id uint) (*model.User, error) {
+	return r.GetProjected(ctx, id, nil)
+}
+
+func (r *userRepository) GetProjected(ctx context.Context, id uint, proj *query.Projection) (*model.User, error) {
+	db, err := query.Project(conn(ctx, r.db), userQuerySchema, proj, "Roles", "Roles.Permissions")
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, err
-		}
+		return nil, err
+	}
+
+	var user model.User
+	if err := db.First(&user, id).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepository) GetByEmail(email string) (*model.User, error) {
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
-	err := r.db.Where("email = ?", email).First(&user).Error
+	err := conn(ctx, r.db).Where("email = ?", email).First(&user).Error
 	if err != nil {
 		// 如果是记录不存在，返回错误以便上层判断
 		// 如果是其他错误，也返回错误
@@ -58,74 +88,95 @@ func (r *userRepository) GetByEmail(email string) (*model.User, error) {
 	return &user, nil
 }
 
-func (r *userRepository) Update(user *model.User) error {
-	return r.db.Save(user).Error
+func (r *userRepository) GetLanguage(ctx context.Context, id uint) (string, error) {
+	var user model.User
+	if err := conn(ctx, r.db).Select("id", "language").First(&user, id).Error; err != nil {
+		return "", err
+	}
+	return user.Language, nil
 }
 
-func (r *userRepository) Delete(id uint) error {
-	return r.db.Delete(&model.User{}, id).Error
+func (r *userRepository) Update(ctx context.Context, user *model.User) error {
+	return updateWithVersion(conn(ctx, r.db), user, &user.Version)
 }
 
-func (r *userRepository) List(offset, limit int) ([]*model.User, int64, error) {
-	var users []*model.User
-	var total int64
+func (r *userRepository) Patch(ctx context.Context, user *model.User, fields map[string]interface{}) error {
+	return patchWithVersion(conn(ctx, r.db), user, &user.Version, fields)
+}
 
-	err := r.db.Model(&model.User{}).Count(&total).Error
-	if err != nil {
-		return nil, 0, err
+func (r *userRepository) Delete(ctx context.Context, id uint, version uint) error {
+	return deleteWithVersion(conn(ctx, r.db), &model.User{}, id, version)
+}
+
+func (r *userRepository) GetWithDeleted(ctx context.Context, id uint) (*model.User, error) {
+	var user model.User
+	if err := getWithDeleted(conn(ctx, r.db), &user, id); err != nil {
+		return nil, err
 	}
+	return &user, nil
+}
 
-	err = r.db.Preload("Roles").Offset(offset).Limit(limit).Find(&users).Error
+func (r *userRepository) Restore(ctx context.Context, user *model.User) error {
+	return restoreWithVersion(conn(ctx, r.db), user, &user.Version)
+}
+
+func (r *userRepository) Purge(ctx context.Context, id uint) error {
+	return purgeDeleted(conn(ctx, r.db), &model.User{}, id, "user_id", "user_roles")
+}
+
+func (r *userRepository) List(ctx context.Context, spec *query.Spec, page query.Page) ([]*model.User, *query.PageInfo, error) {
+	var users []*model.User
+	info, err := query.Find(conn(ctx, r.db), userQuerySchema, spec, page, &users, "Roles")
 	if err != nil {
-		return nil, 0, err
+		return nil, nil, err
 	}
-
-	return users, total, nil
+	return users, info, nil
 }
 
-func (r *userRepository) AssignRoles(userID uint, roleIDs []uint) error {
+func (r *userRepository) AssignRoles(ctx context.Context, userID uint, roleIDs []uint) error {
 	var roles []model.Role
-	if err := r.db.Find(&roles, roleIDs).Error; err != nil {
+	if err := conn(ctx, r.db).Find(&roles, roleIDs).Error; err != nil {
 		return err
 	}
 
 	var user model.User
-	if err := r.db.First(&user, userID).Error; err != nil {
+	if err := conn(ctx, r.db).First(&user, userID).Error; err != nil {
 		return err
 	}
 
-	return r.db.Model(&user).Association("Roles").Append(roles)
+	return conn(ctx, r.db).Model(&user).Association("Roles").Append(roles)
 }
 
-func (r *userRepository) RemoveRoles(userID uint, roleIDs []uint) error {
+func (r *userRepository) RemoveRoles(ctx context.Context, userID uint, roleIDs []uint) error {
 	var roles []model.Role
-	if err := r.db.Find(&roles, roleIDs).Error; err != nil {
+	if err := conn(ctx, r.db).Find(&roles, roleIDs).Error; err != nil {
 		return err
 	}
 
 	var user model.User
-	if err := r.db.First(&user, userID).Error; err != nil {
+	if err := conn(ctx, r.db).First(&user, userID).Error; err != nil {
 		return err
 	}
 
-	return r.db.Model(&user).Association("Roles").Delete(roles)
+	return conn(ctx, r.db).Model(&user).Association("Roles").Delete(roles)
 }
 
-func (r *userRepository) GetRoles(userID uint) ([]*model.Role, error) {
+func (r *userRepository) GetRoles(ctx context.Context, userID uint) ([]*model.Role, error) {
 	var user model.User
-	if err := r.db.First(&user, userID).Error; err != nil {
+	if err := conn(ctx, r.db).First(&user, userID).Error; err != nil {
 		return nil, err
 	}
 
 	var roles []*model.Role
-	err := r.db.Model(&user).Association("Roles").Find(&roles)
+	// 关联表的软删除不会自动过滤
+	err := conn(ctx, r.db).Model(&user).Where("user_roles.deleted_at IS NULL").Association("Roles").Find(&roles)
 	return roles, err
 }
 
-// HasPermission 检查用户是否拥有指定资源与操作的权限
-func (r *userRepository) HasPermission(userID uint, resource, action string) (bool, error) {
+// HasPermission 检查用户是否拥有指定资源与操作的权限，已删除或已禁用的角色、权限以及已删除的关联不授予权限
+func (r *userRepository) HasPermission(ctx context.Context, userID uint, resource, action string) (bool, error) {
 	var count int64
-	err := r.db.Model(&model.Permission{}).
+	err := conn(ctx, r.db).Model(&model.Permission{}).
 		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
 		Joins("JOIN roles ON roles.id = role_permissions.role_id").
 		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
@@ -134,6 +185,9 @@ func (r *userRepository) HasPermission(userID uint, resource, action string) (bo
 		Where("permissions.action = ?", action).
 		Where("permissions.status = ?", 1).
 		Where("roles.status = ?", 1).
+		Where("roles.deleted_at IS NULL").
+		Where("role_permissions.deleted_at IS NULL").
+		Where("user_roles.deleted_at IS NULL").
 		Count(&count).Error
 
 	if err != nil {
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"go_web/internal/apperr"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// updateWithVersion 以读取时的版本号为条件更新记录的全部字段（不含关联），成功后版本号加一
+// 期间记录被其他请求修改或删除时返回 apperr.ErrVersionConflict，记录和版本号保持不变
+func updateWithVersion(db *gorm.DB, record interface{}, version *uint) error {
+	current := *version
+	*version = current + 1
+
+	result := db.Model(record).Where("version = ?", current).
+		Select("*").Omit("created_at", "deleted_at", clause.Associations).
+		Updates(record)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = apperr.ErrVersionConflict
+	}
+	if result.Error != nil {
+		*version = current
+	}
+	return result.Error
+}
+
+// deleteWithVersion 删除记录，version 不为 0 时只有版本号一致才删除
+// 版本号不一致时返回 apperr.ErrVersionConflict，记录不存在时返回 gorm.ErrRecordNotFound
+func deleteWithVersion(db *gorm.DB, model interface{}, id, version uint) error {
+	if version == 0 {
+		result := db.Delete(model, id)
+		if result.Error == nil && result.RowsAffected == 0 {
+			result.Error = gorm.ErrRecordNotFound
+		}
+		return result.Error
+	}
+
+	// id 条件放在最前面，审计插件从第一个查询参数中取得记录ID
+	result := db.Where("id = ?", id).Where("version = ?", version).Delete(model)
+	if result.Error != nil || result.RowsAffected > 0 {
+		return result.Error
+	}
+
+	var count int64
+	if err := db.Model(model).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return apperr.ErrVersionConflict
+}
+
+// patchWithVersion 以读取时的版本号为条件只更新 fields 中的列（列名为键），成功后版本号加一，并写回 record
+// 审计日志只记录这些列的新旧值；期间记录被其他请求修改或删除时返回 apperr.ErrVersionConflict
+func patchWithVersion(db *gorm.DB, record interface{}, version *uint, fields map[string]interface{}) error {
+	current := *version
+	values := make(map[string]interface{}, len(fields)+1)
+	for column, value := range fields {
+		values[column] = value
+	}
+	values["version"] = current + 1
+
+	result := db.Model(record).Where("version = ?", current).Updates(values)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = apperr.ErrVersionConflict
+	}
+	if result.Error != nil {
+		*version = current
+		return result.Error
+	}
+	*version = current + 1
+	return nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"go_web/internal/apperr"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// versionedItem 版本号测试使用的模型
+type versionedItem struct {
+	ID        uint
+	Name      string
+	Version   uint
+	DeletedAt gorm.DeletedAt
+}
+
+// newTestDB 在临时目录中创建 SQLite 数据库
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "repository.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&versionedItem{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestDeleteWithVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      uint
+		version uint
+		wantErr error
+	}{
+		{name: "unversioned", id: 1, version: 0},
+		{name: "unversioned missing", id: 999, version: 0, wantErr: gorm.ErrRecordNotFound},
+		{name: "versioned", id: 1, version: 1},
+		{name: "versioned missing", id: 999, version: 1, wantErr: gorm.ErrRecordNotFound},
+		{name: "stale version", id: 1, version: 2, wantErr: apperr.ErrVersionConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			if err := db.Create(&versionedItem{ID: 1, Name: "a", Version: 1}).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			err := deleteWithVersion(db, &versionedItem{}, tt.id, tt.version)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("deleteWithVersion() error = %v, want %v", err, tt.wantErr)
+			}
+
+			var count int64
+			if err := db.Model(&versionedItem{}).Count(&count).Error; err != nil {
+				t.Fatal(err)
+			}
+			wantCount := int64(1)
+			if tt.wantErr == nil {
+				wantCount = 0
+			}
+			if count != wantCount {
+				t.Errorf("live rows = %d, want %d", count, wantCount)
+			}
+		})
+	}
+}
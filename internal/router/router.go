@@ -1,9 +1,14 @@
 package router
 
 import (
+	"strings"
+
 	"go_web/docs/swagger" // Swagger 文档
+	"go_web/internal/apperr"
 	"go_web/internal/config"
+	"go_web/internal/database"
 	"go_web/internal/handler"
+	"go_web/internal/i18n"
 	"go_web/internal/middleware"
 	"go_web/internal/service"
 	"go_web/internal/util"
@@ -18,41 +23,79 @@ import (
 type RouterParams struct {
 	dig.In
 
-	Config            *config.Config
-	LoggerMiddleware  gin.HandlerFunc `name:"logger"`
-	AuditMiddleware   gin.HandlerFunc `name:"audit"`
-	JWTAuthMiddleware gin.HandlerFunc `name:"jwt"`
-	UserHandler       *handler.UserHandler
-	RoleHandler       *handler.RoleHandler
-	PermissionHandler *handler.PermissionHandler
-	AuthHandler       *handler.AuthHandler
-	UserService       service.UserService
+	Config               *config.Config
+	ConfigManager        *config.Manager
+	RequestIDMiddleware  gin.HandlerFunc `name:"requestID"`
+	LocaleMiddleware     gin.HandlerFunc `name:"locale"`
+	ReadYourWrites       gin.HandlerFunc `name:"readYourWrites"`
+	LoggerMiddleware     gin.HandlerFunc `name:"logger"`
+	AuditMiddleware      gin.HandlerFunc `name:"audit"`
+	SecurityMiddleware   gin.HandlerFunc `name:"security"`
+	CORSMiddleware       gin.HandlerFunc `name:"cors"`
+	RateLimitMiddleware  gin.HandlerFunc `name:"rateLimit"`
+	JWTAuthMiddleware    gin.HandlerFunc `name:"jwt"`
+	UserLocaleMiddleware gin.HandlerFunc `name:"userLocale"`
+	UserHandler          *handler.UserHandler
+	RoleHandler          *handler.RoleHandler
+	PermissionHandler    *handler.PermissionHandler
+	AuthHandler          *handler.AuthHandler
+	AuditLogHandler      *handler.AuditLogHandler
+	SecurityEventHandler *handler.SecurityEventHandler
+	UserService          service.UserService
+	AuditWriter          *database.AuditWriter
+	ReplicaResolver      *database.ReplicaResolver
 }
 
 func SetupRouter(params RouterParams) *gin.Engine {
 	cfg := params.Config
+	configManager := params.ConfigManager
+	requestIDMiddleware := params.RequestIDMiddleware
+	localeMiddleware := params.LocaleMiddleware
+	readYourWritesMiddleware := params.ReadYourWrites
 	loggerMiddleware := params.LoggerMiddleware
 	auditMiddleware := params.AuditMiddleware
+	securityMiddleware := params.SecurityMiddleware
+	corsMiddleware := params.CORSMiddleware
+	rateLimitMiddleware := params.RateLimitMiddleware
 	jwtAuthMiddleware := params.JWTAuthMiddleware
+	userLocaleMiddleware := params.UserLocaleMiddleware
 	userHandler := params.UserHandler
 	roleHandler := params.RoleHandler
 	permissionHandler := params.PermissionHandler
 	authHandler := params.AuthHandler
+	auditLogHandler := params.AuditLogHandler
+	securityEventHandler := params.SecurityEventHandler
 	userService := params.UserService
+	auditWriter := params.AuditWriter
+	replicaResolver := params.ReplicaResolver
 	// 在创建路由之前设置Gin模式
 	gin.SetMode(cfg.Server.Mode)
+	// 校验错误中的字段名使用 JSON 字段名
+	util.UseJSONFieldNames()
 
 	r := gin.Default()
 
 	// 全局中间件
+	r.Use(requestIDMiddleware)
+	r.Use(localeMiddleware)
+	r.Use(readYourWritesMiddleware)
 	r.Use(loggerMiddleware)
 	r.Use(auditMiddleware)
+	r.Use(securityMiddleware)
+	r.Use(corsMiddleware)
+	r.Use(rateLimitMiddleware)
 
 	// 健康检查
 	r.GET("/health", func(c *gin.Context) {
-		util.SuccessWithMessage(c, "服务运行正常", gin.H{
+		data := gin.H{
 			"status": "ok",
-		})
+			"audit":  auditWriter.Stats(),
+		}
+		// 配置了只读副本时返回各副本状态
+		if replicas := replicaResolver.Stats(); replicas != nil {
+			data["replicas"] = replicas
+		}
+		util.SuccessWithMessage(c, i18n.M(i18n.MsgServiceHealthy), data)
 	})
 
 	// Swagger UI 文档
@@ -68,16 +111,24 @@ func SetupRouter(params RouterParams) *gin.Engine {
 
 		// 需要认证的路由组
 		auth := api.Group("")
-		auth.Use(jwtAuthMiddleware) // 添加 JWT 认证中间件
+		auth.Use(jwtAuthMiddleware)    // 添加 JWT 认证中间件
+		auth.Use(userLocaleMiddleware) // 请求没有指定语言时使用已登录用户的语言偏好
 		{
 			// 用户相关路由
 			users := auth.Group("/users")
 			{
 				users.POST("", middleware.RequirePermission(userService, "user", "create"), userHandler.CreateUser)
 				users.GET("", middleware.RequirePermission(userService, "user", "read"), userHandler.ListUsers)
+				users.GET("/export", middleware.RequirePermission(userService, "user", "read"), userHandler.ExportUsers)
+				// 导入会分配角色，同时需要 role:update 权限
+				users.POST("/import", middleware.RequirePermission(userService, "user", "create"), middleware.RequirePermission(userService, "role", "update"), userHandler.ImportUsers)
 				users.GET("/:id", middleware.RequirePermission(userService, "user", "read"), userHandler.GetUser)
 				users.PUT("/:id", middleware.RequirePermission(userService, "user", "update"), userHandler.UpdateUser)
+				users.PATCH("/:id", middleware.RequirePermission(userService, "user", "update"), userHandler.PatchUser)
 				users.DELETE("/:id", middleware.RequirePermission(userService, "user", "delete"), userHandler.DeleteUser)
+				// 恢复是删除的逆操作，使用 delete 权限；彻底删除不可恢复，需要单独的 purge 权限
+				users.POST("/:id/restore", middleware.RequirePermission(userService, "user", "delete"), userHandler.RestoreUser)
+				users.DELETE("/:id/purge", middleware.RequirePermission(userService, "user", "purge"), userHandler.PurgeUser)
 			}
 
 			// 角色相关路由
@@ -87,7 +138,10 @@ func SetupRouter(params RouterParams) *gin.Engine {
 				roles.GET("", middleware.RequirePermission(userService, "role", "read"), roleHandler.ListRoles)
 				roles.GET("/:id", middleware.RequirePermission(userService, "role", "read"), roleHandler.GetRole)
 				roles.PUT("/:id", middleware.RequirePermission(userService, "role", "update"), roleHandler.UpdateRole)
+				roles.PATCH("/:id", middleware.RequirePermission(userService, "role", "update"), roleHandler.PatchRole)
 				roles.DELETE("/:id", middleware.RequirePermission(userService, "role", "delete"), roleHandler.DeleteRole)
+				roles.POST("/:id/restore", middleware.RequirePermission(userService, "role", "delete"), roleHandler.RestoreRole)
+				roles.DELETE("/:id/purge", middleware.RequirePermission(userService, "role", "purge"), roleHandler.PurgeRole)
 				// 角色权限管理
 				roles.POST("/:id/permissions", middleware.RequirePermission(userService, "role", "update"), roleHandler.AssignPermissions)
 				roles.DELETE("/:id/permissions", middleware.RequirePermission(userService, "role", "update"), roleHandler.RemovePermissions)
@@ -105,10 +159,52 @@ func SetupRouter(params RouterParams) *gin.Engine {
 				permissions.GET("", middleware.RequirePermission(userService, "permission", "read"), permissionHandler.ListPermissions)
 				permissions.GET("/:id", middleware.RequirePermission(userService, "permission", "read"), permissionHandler.GetPermission)
 				permissions.PUT("/:id", middleware.RequirePermission(userService, "permission", "update"), permissionHandler.UpdatePermission)
+				permissions.PATCH("/:id", middleware.RequirePermission(userService, "permission", "update"), permissionHandler.PatchPermission)
 				permissions.DELETE("/:id", middleware.RequirePermission(userService, "permission", "delete"), permissionHandler.DeletePermission)
+				permissions.POST("/:id/restore", middleware.RequirePermission(userService, "permission", "delete"), permissionHandler.RestorePermission)
+				permissions.DELETE("/:id/purge", middleware.RequirePermission(userService, "permission", "purge"), permissionHandler.PurgePermission)
+			}
+
+			// 批量操作（POST /users:batch 等）
+			auth.POST("/:action", collectionActions(map[string]gin.HandlersChain{
+				"users:batch":       {middleware.RequireBatchPermission(configManager, userService, "user"), userHandler.BatchUsers},
+				"roles:batch":       {middleware.RequireBatchPermission(configManager, userService, "role"), roleHandler.BatchRoles},
+				"permissions:batch": {middleware.RequireBatchPermission(configManager, userService, "permission"), permissionHandler.BatchPermissions},
+			}))
+
+			// 审计日志相关路由
+			auditLogs := auth.Group("/audit-logs")
+			{
+				auditLogs.GET("/export", middleware.RequirePermission(userService, "audit", "read"), auditLogHandler.ExportAuditLogs)
+				auditLogs.GET("/requests/:request_id", middleware.RequirePermission(userService, "audit", "read"), auditLogHandler.GetRequestAudit)
+				auditLogs.POST("/:id/revert", middleware.RequirePermission(userService, "audit", "revert"), auditLogHandler.RevertAuditLog)
 			}
+
+			// 安全事件相关路由
+			auth.GET("/security-events", middleware.RequirePermission(userService, "security", "read"), securityEventHandler.ListSecurityEvents)
 		}
 	}
 
 	return r
 }
+
+// collectionActions 分发集合上的自定义方法（如 users:batch）
+// gin 只在 engine.Run 中处理路由里转义的冒号，服务使用自己的 http.Server 启动，因此以路径参数匹配后按名称分发；
+// 匹配后将解析出的路由（如 /api/v1/users:batch）写入 route，审计中间件以此代替路由模板 /api/v1/:action
+func collectionActions(actions map[string]gin.HandlersChain) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		action := c.Param("action")
+		handlers, ok := actions[action]
+		if !ok {
+			util.Fail(c, apperr.ErrNotFound.WithMessage(i18n.MsgRouteNotFound))
+			return
+		}
+		c.Set("route", strings.Replace(c.FullPath(), ":action", action, 1))
+		for _, handler := range handlers {
+			handler(c)
+			if c.IsAborted() {
+				return
+			}
+		}
+	}
+}
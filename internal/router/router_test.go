@@ -0,0 +1,45 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCollectionActions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantRoute  string
+	}{
+		{name: "known action", path: "/api/v1/users:batch", wantStatus: http.StatusOK, wantRoute: "/api/v1/users:batch"},
+		{name: "unknown action", path: "/api/v1/users:drop", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var route string
+			r := gin.New()
+			r.POST("/api/v1/:action", func(c *gin.Context) {
+				c.Next()
+				route = c.GetString("route")
+			}, collectionActions(map[string]gin.HandlersChain{
+				"users:batch": {func(c *gin.Context) { c.Status(http.StatusOK) }},
+			}))
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if route != tt.wantRoute {
+				t.Errorf("route = %q, want %q", route, tt.wantRoute)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"go_web/internal/apperr"
+	"go_web/internal/repository"
+)
+
+// 批量操作类型
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+// BatchResult 批量操作中单个操作的结果
+type BatchResult struct {
+	ID   uint        // 操作的记录ID（创建成功后为新记录的ID），回滚时为 0
+	Data interface{} // 创建或更新后的记录
+	Err  error       // 为 nil 表示成功
+}
+
+// runBatch 执行 n 个操作，返回每个操作的结果
+// atomic 为 true 时所有操作在同一个事务中执行，遇到失败即停止并回滚，其余操作的错误为 apperr.ErrBatchAborted；
+// 否则逐个执行，互不影响
+func runBatch(ctx context.Context, transactor repository.Transactor, n int, atomic bool, run func(ctx context.Context, i int) BatchResult) []BatchResult {
+	results := make([]BatchResult, n)
+	if !atomic {
+		for i := range results {
+			results[i] = run(ctx, i)
+		}
+		return results
+	}
+
+	failed := -1
+	err := transactor.Transaction(ctx, func(ctx context.Context) error {
+		for i := range results {
+			results[i] = run(ctx, i)
+			if results[i].Err != nil {
+				failed = i
+				return results[i].Err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		return results
+	}
+
+	// 已回滚的操作不返回记录和新建记录的ID
+	for i := range results {
+		switch {
+		case failed < 0:
+			// 全部执行成功但提交失败
+			results[i] = BatchResult{Err: fmt.Errorf("提交事务失败: %w", err)}
+		case i != failed:
+			results[i] = BatchResult{Err: apperr.ErrBatchAborted}
+		}
+	}
+	return results
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"go_web/internal/apperr"
+	"go_web/internal/config"
+	"go_web/internal/database"
+	"go_web/internal/model"
+	"go_web/internal/repository"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB 在临时目录中创建 SQLite 数据库并执行全部迁移
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "service.db")), &gorm.Config{
+		Logger:         logger.Default.LogMode(logger.Silent),
+		TranslateError: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := database.NewMigrator(db, &config.Config{Database: config.DatabaseConfig{Driver: "sqlite"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// TestBatchDeleteMissing 删除不存在的记录时该操作返回资源的 404 错误，atomic 模式下整批回滚
+func TestBatchDeleteMissing(t *testing.T) {
+	resources := []struct {
+		name    string
+		model   interface{}
+		wantErr *apperr.Error
+		run     func(db *gorm.DB, atomic bool) []BatchResult
+	}{
+		{
+			name:    "users",
+			model:   &model.User{},
+			wantErr: apperr.ErrUserNotFound,
+			run: func(db *gorm.DB, atomic bool) []BatchResult {
+				s := NewUserService(repository.NewUserRepository(db), repository.NewTransactor(db), NewUserLanguageCache())
+				return s.BatchUsers(context.Background(), []UserBatchOp{
+					{Op: BatchCreate, Name: "alice", Email: "alice@example.com", Password: "secret123"},
+					{Op: BatchDelete, ID: 999},
+				}, atomic)
+			},
+		},
+		{
+			name:    "roles",
+			model:   &model.Role{},
+			wantErr: apperr.ErrRoleNotFound,
+			run: func(db *gorm.DB, atomic bool) []BatchResult {
+				s := NewRoleService(repository.NewRoleRepository(db), repository.NewTransactor(db))
+				return s.BatchRoles(context.Background(), []RoleBatchOp{
+					{Op: BatchCreate, Name: "editor", DisplayName: "Editor"},
+					{Op: BatchDelete, ID: 999},
+				}, atomic)
+			},
+		},
+		{
+			name:    "permissions",
+			model:   &model.Permission{},
+			wantErr: apperr.ErrPermissionNotFound,
+			run: func(db *gorm.DB, atomic bool) []BatchResult {
+				s := NewPermissionService(repository.NewPermissionRepository(db), repository.NewTransactor(db))
+				return s.BatchPermissions(context.Background(), []PermissionBatchOp{
+					{Op: BatchCreate, Name: "post:read", DisplayName: "Read posts", Resource: "post", Action: "read"},
+					{Op: BatchDelete, ID: 999},
+				}, atomic)
+			},
+		},
+	}
+
+	for _, r := range resources {
+		for _, atomic := range []bool{true, false} {
+			name := r.name + "/best-effort"
+			if atomic {
+				name = r.name + "/atomic"
+			}
+			t.Run(name, func(t *testing.T) {
+				db := newTestDB(t)
+				results := r.run(db, atomic)
+				if len(results) != 2 {
+					t.Fatalf("got %d results, want 2", len(results))
+				}
+
+				deleteErr := apperr.As(results[1].Err)
+				if deleteErr == nil || !errors.Is(deleteErr, r.wantErr) || deleteErr.Status != r.wantErr.Status {
+					t.Fatalf("delete error = %v, want %s", results[1].Err, r.wantErr.Code)
+				}
+
+				var count int64
+				if err := db.Model(r.model).Count(&count).Error; err != nil {
+					t.Fatal(err)
+				}
+				if atomic {
+					if !errors.Is(results[0].Err, apperr.ErrBatchAborted) {
+						t.Errorf("create error = %v, want %s", results[0].Err, apperr.ErrBatchAborted.Code)
+					}
+					if count != 0 {
+						t.Errorf("rows after rollback = %d, want 0", count)
+					}
+				} else {
+					if results[0].Err != nil {
+						t.Errorf("create error = %v, want nil", results[0].Err)
+					}
+					if count != 1 {
+						t.Errorf("rows = %d, want 1", count)
+					}
+				}
+			})
+		}
+	}
+}
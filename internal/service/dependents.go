@@ -0,0 +1,38 @@
+package service
+
+import (
+	"go_web/internal/model"
+)
+
+// maxDependents 删除仍被引用的记录时最多列出的引用记录数
+const maxDependents = 20
+
+// Dependent 引用待删除记录的用户或角色
+type Dependent struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`            // 用户名或角色名称
+	Email string `json:"email,omitempty"` // 用户的邮箱
+}
+
+// Dependents 删除仍被引用的角色或权限时返回的引用记录，作为 409 响应的 data
+type Dependents struct {
+	Total int64       `json:"total"`           // 引用的记录总数
+	Users []Dependent `json:"users,omitempty"` // 拥有该角色的用户，最多列出 maxDependents 个
+	Roles []Dependent `json:"roles,omitempty"` // 使用该权限的角色，最多列出 maxDependents 个
+}
+
+func userDependents(users []*model.User, total int64) *Dependents {
+	result := &Dependents{Total: total, Users: make([]Dependent, 0, len(users))}
+	for _, user := range users {
+		result.Users = append(result.Users, Dependent{ID: user.ID, Name: user.Name, Email: user.Email})
+	}
+	return result
+}
+
+func roleDependents(roles []*model.Role, total int64) *Dependents {
+	result := &Dependents{Total: total, Roles: make([]Dependent, 0, len(roles))}
+	for _, role := range roles {
+		result.Roles = append(result.Roles, Dependent{ID: role.ID, Name: role.Name})
+	}
+	return result
+}
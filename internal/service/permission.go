@@ -1,37 +1,61 @@
 package service
 
 import (
+	"context"
 	"errors"
 
+	"go_web/internal/apperr"
+	"go_web/internal/i18n"
 	"go_web/internal/model"
+	"go_web/internal/query"
 	"go_web/internal/repository"
 
 	"gorm.io/gorm"
 )
 
 type PermissionService interface {
-	CreatePermission(name, displayName, description, resource, action string) (*model.Permission, error)
-	GetPermissionByID(id uint) (*model.Permission, error)
-	GetPermissionByName(name string) (*model.Permission, error)
-	UpdatePermission(id uint, displayName, description string, status int) (*model.Permission, error)
-	DeletePermission(id uint) error
-	ListPermissions(page, pageSize int) ([]*model.Permission, int64, error)
-	GetPermissionByResourceAndAction(resource, action string) (*model.Permission, error)
+	CreatePermission(ctx context.Context, name, displayName, description, resource, action string) (*model.Permission, error)
+	GetPermissionByID(ctx context.Context, id uint) (*model.Permission, error)
+	GetPermissionProjected(ctx context.Context, id uint, proj *query.Projection) (*model.Permission, error) // 按 fields 和 include 查询，proj 为 nil 时同 GetPermissionByID
+	GetPermissionByName(ctx context.Context, name string) (*model.Permission, error)
+	UpdatePermission(ctx context.Context, id uint, version uint, displayName, description string, status int) (*model.Permission, error)   // version 为客户端持有的版本号，0 表示不校验
+	PatchPermission(ctx context.Context, id uint, version uint, apply func(permission *model.Permission) error) (*model.Permission, error) // apply 合并修改并校验结果，只写入有变化的字段
+	DeletePermission(ctx context.Context, id uint, version uint, force bool) error                                                         // 权限仍被角色使用时返回 apperr.ErrPermissionInUse（data 中列出这些角色），force 为 true 时同时从这些角色中移除
+	RestorePermission(ctx context.Context, id uint, version uint) (*model.Permission, error)                                               // 恢复已软删除的权限，名称已被其他权限使用时返回 apperr.ErrPermissionNameTaken
+	PurgePermission(ctx context.Context, id uint) error                                                                                    // 彻底删除，只能删除已软删除的记录
+	ListPermissions(ctx context.Context, spec *query.Spec, page query.Page) ([]*model.Permission, *query.PageInfo, error)
+	BatchPermissions(ctx context.Context, ops []PermissionBatchOp, atomic bool) []BatchResult
+	GetPermissionByResourceAndAction(ctx context.Context, resource, action string) (*model.Permission, error)
+}
+
+// PermissionBatchOp 权限批量操作：create 使用 Name、DisplayName、Description、Resource、Action，update 使用 ID、Version、DisplayName、Description、Status，delete 使用 ID、Version
+type PermissionBatchOp struct {
+	Op          string
+	ID          uint
+	Version     uint // 0 表示不校验版本号
+	Name        string
+	DisplayName string
+	Description string
+	Resource    string
+	Action      string
+	Status      int // -1 表示不更新
 }
 
 type permissionService struct {
 	permissionRepo repository.PermissionRepository
+	transactor     repository.Transactor
 }
 
-func NewPermissionService(permissionRepo repository.PermissionRepository) PermissionService {
-	return &permissionService{permissionRepo: permissionRepo}
+func NewPermissionService(permissionRepo repository.PermissionRepository, transactor repository.Transactor) PermissionService {
+	return &permissionService{permissionRepo: permissionRepo, transactor: transactor}
 }
 
-func (s *permissionService) CreatePermission(name, displayName, description, resource, action string) (*model.Permission, error) {
+func (s *permissionService) CreatePermission(ctx context.Context, name, displayName, description, resource, action string) (*model.Permission, error) {
+	ctx = forWrite(ctx)
 	// 检查权限名称是否已存在
-	existingPermission, err := s.permissionRepo.GetByName(name)
+	existingPermission, err := s.permissionRepo.GetByName(ctx, name)
 	if err == nil && existingPermission != nil {
-		return nil, errors.New("权限名称已存在")
+		return nil, apperr.ErrPermissionNameTaken
 	}
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
@@ -46,27 +70,38 @@ func (s *permissionService) CreatePermission(name, displayName, description, res
 		Status:      1,
 	}
 
-	err = s.permissionRepo.Create(permission)
+	err = s.permissionRepo.Create(ctx, permission)
 	if err != nil {
-		return nil, err
+		return nil, duplicated(err, apperr.ErrPermissionNameTaken)
 	}
 
 	return permission, nil
 }
 
-func (s *permissionService) GetPermissionByID(id uint) (*model.Permission, error) {
-	return s.permissionRepo.GetByID(id)
+func (s *permissionService) GetPermissionByID(ctx context.Context, id uint) (*model.Permission, error) {
+	permission, err := s.permissionRepo.GetByID(ctx, id)
+	return permission, notFound(err, apperr.ErrPermissionNotFound)
 }
 
-func (s *permissionService) GetPermissionByName(name string) (*model.Permission, error) {
-	return s.permissionRepo.GetByName(name)
+func (s *permissionService) GetPermissionProjected(ctx context.Context, id uint, proj *query.Projection) (*model.Permission, error) {
+	permission, err := s.permissionRepo.GetProjected(ctx, id, proj)
+	return permission, notFound(err, apperr.ErrPermissionNotFound)
 }
 
-func (s *permissionService) UpdatePermission(id uint, displayName, description string, status int) (*model.Permission, error) {
-	permission, err := s.permissionRepo.GetByID(id)
+func (s *permissionService) GetPermissionByName(ctx context.Context, name string) (*model.Permission, error) {
+	permission, err := s.permissionRepo.GetByName(ctx, name)
+	return permission, notFound(err, apperr.ErrPermissionNotFound)
+}
+
+func (s *permissionService) UpdatePermission(ctx context.Context, id uint, version uint, displayName, description string, status int) (*model.Permission, error) {
+	ctx = forWrite(ctx)
+	permission, err := s.GetPermissionByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkVersion(version, permission.Version); err != nil {
+		return nil, err
+	}
 
 	if displayName != "" {
 		permission.DisplayName = displayName
@@ -78,7 +113,7 @@ func (s *permissionService) UpdatePermission(id uint, displayName, description s
 		permission.Status = status
 	}
 
-	err = s.permissionRepo.Update(permission)
+	err = s.permissionRepo.Update(ctx, permission)
 	if err != nil {
 		return nil, err
 	}
@@ -86,15 +121,136 @@ func (s *permissionService) UpdatePermission(id uint, displayName, description s
 	return permission, nil
 }
 
-func (s *permissionService) DeletePermission(id uint) error {
-	return s.permissionRepo.Delete(id)
+// PatchPermission 由 apply 在当前记录上合并修改，只更新显示名称、描述、状态中有变化的字段，审计日志只记录这些字段
+// 没有变化时不写入，版本号保持不变
+func (s *permissionService) PatchPermission(ctx context.Context, id uint, version uint, apply func(permission *model.Permission) error) (*model.Permission, error) {
+	ctx = forWrite(ctx)
+	permission, err := s.GetPermissionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(version, permission.Version); err != nil {
+		return nil, err
+	}
+
+	before := *permission
+	if err := apply(permission); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]interface{})
+	if permission.DisplayName != before.DisplayName {
+		fields["display_name"] = permission.DisplayName
+	}
+	if permission.Description != before.Description {
+		fields["description"] = permission.Description
+	}
+	if permission.Status != before.Status {
+		fields["status"] = permission.Status
+	}
+	if len(fields) == 0 {
+		return &before, nil
+	}
+
+	if err := s.permissionRepo.Patch(ctx, permission, fields); err != nil {
+		return nil, err
+	}
+	return permission, nil
+}
+
+func (s *permissionService) DeletePermission(ctx context.Context, id uint, version uint, force bool) error {
+	err := s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		roles, total, err := s.permissionRepo.GetUsingRoles(ctx, id, maxDependents)
+		if err != nil {
+			return err
+		}
+		if total > 0 {
+			if !force {
+				return apperr.ErrPermissionInUse.WithMessage(i18n.MsgPermissionInUse, total).WithData(roleDependents(roles, total))
+			}
+			if err := s.permissionRepo.UnlinkRoles(ctx, id); err != nil {
+				return err
+			}
+		}
+		return s.permissionRepo.Delete(ctx, id, version)
+	})
+	return notFound(deleteVersionError(err), apperr.ErrPermissionNotFound)
+}
+
+func (s *permissionService) RestorePermission(ctx context.Context, id uint, version uint) (*model.Permission, error) {
+	ctx = forWrite(ctx)
+	permission, err := s.permissionRepo.GetWithDeleted(ctx, id)
+	if err != nil {
+		return nil, notFound(err, apperr.ErrPermissionNotFound)
+	}
+	if !permission.DeletedAt.Valid {
+		return nil, apperr.ErrNotDeleted
+	}
+	if err := checkVersion(version, permission.Version); err != nil {
+		return nil, err
+	}
+
+	// 唯一索引只约束未删除的记录，删除期间可能已有同名记录
+	existing, err := s.permissionRepo.GetByName(ctx, permission.Name)
+	if err == nil && existing != nil {
+		return nil, apperr.ErrPermissionNameTaken
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := s.permissionRepo.Restore(ctx, permission); err != nil {
+		return nil, duplicated(err, apperr.ErrPermissionNameTaken)
+	}
+	return s.GetPermissionByID(ctx, id)
+}
+
+func (s *permissionService) PurgePermission(ctx context.Context, id uint) error {
+	ctx = forWrite(ctx)
+	permission, err := s.permissionRepo.GetWithDeleted(ctx, id)
+	if err != nil {
+		return notFound(err, apperr.ErrPermissionNotFound)
+	}
+	if !permission.DeletedAt.Valid {
+		return apperr.ErrNotDeleted
+	}
+	// 在事务中执行，关联行的审计日志在提交后写入
+	err = s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		return s.permissionRepo.Purge(ctx, id)
+	})
+	return notFound(err, apperr.ErrPermissionNotFound)
+}
+
+func (s *permissionService) ListPermissions(ctx context.Context, spec *query.Spec, page query.Page) ([]*model.Permission, *query.PageInfo, error) {
+	return s.permissionRepo.List(ctx, spec, page)
 }
 
-func (s *permissionService) ListPermissions(page, pageSize int) ([]*model.Permission, int64, error) {
-	offset := (page - 1) * pageSize
-	return s.permissionRepo.List(offset, pageSize)
+// BatchPermissions 批量创建、更新、删除权限，atomic 为 true 时全部成功或全部回滚
+func (s *permissionService) BatchPermissions(ctx context.Context, ops []PermissionBatchOp, atomic bool) []BatchResult {
+	return runBatch(ctx, s.transactor, len(ops), atomic, func(ctx context.Context, i int) BatchResult {
+		op := ops[i]
+		switch op.Op {
+		case BatchCreate:
+			permission, err := s.CreatePermission(ctx, op.Name, op.DisplayName, op.Description, op.Resource, op.Action)
+			if err != nil {
+				return BatchResult{Err: err}
+			}
+			return BatchResult{ID: permission.ID, Data: permission}
+		case BatchUpdate:
+			permission, err := s.UpdatePermission(ctx, op.ID, op.Version, op.DisplayName, op.Description, op.Status)
+			if err != nil {
+				return BatchResult{ID: op.ID, Err: err}
+			}
+			return BatchResult{ID: op.ID, Data: permission}
+		case BatchDelete:
+			return BatchResult{ID: op.ID, Err: s.DeletePermission(ctx, op.ID, op.Version, false)}
+		default:
+			return BatchResult{ID: op.ID, Err: apperr.ErrBadRequest.WithMessage(i18n.MsgBatchOpUnknown, op.Op)}
+		}
+	})
 }
 
-func (s *permissionService) GetPermissionByResourceAndAction(resource, action string) (*model.Permission, error) {
-	return s.permissionRepo.GetByResourceAndAction(resource, action)
+func (s *permissionService) GetPermissionByResourceAndAction(ctx context.Context, resource, action string) (*model.Permission, error) {
+	permission, err := s.permissionRepo.GetByResourceAndAction(ctx, resource, action)
+	return permission, notFound(err, apperr.ErrPermissionNotFound)
 }
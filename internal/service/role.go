@@ -1,44 +1,66 @@
 package service
 
 import (
+	"context"
 	"errors"
 
+	"go_web/internal/apperr"
+	"go_web/internal/i18n"
 	"go_web/internal/model"
+	"go_web/internal/query"
 	"go_web/internal/repository"
 
 	"gorm.io/gorm"
 )
 
 type RoleService interface {
-	CreateRole(name, displayName, description string) (*model.Role, error)
-	GetRoleByID(id uint) (*model.Role, error)
-	GetRoleByName(name string) (*model.Role, error)
-	UpdateRole(id uint, displayName, description string, status int) (*model.Role, error)
-	DeleteRole(id uint) error
-	ListRoles(page, pageSize int) ([]*model.Role, int64, error)
+	CreateRole(ctx context.Context, name, displayName, description string) (*model.Role, error)
+	GetRoleByID(ctx context.Context, id uint) (*model.Role, error)
+	GetRoleProjected(ctx context.Context, id uint, proj *query.Projection) (*model.Role, error) // 按 fields 和 include 查询，proj 为 nil 时同 GetRoleByID
+	GetRoleByName(ctx context.Context, name string) (*model.Role, error)
+	UpdateRole(ctx context.Context, id uint, version uint, displayName, description string, status int) (*model.Role, error) // version 为客户端持有的版本号，0 表示不校验
+	PatchRole(ctx context.Context, id uint, version uint, apply func(role *model.Role) error) (*model.Role, error)           // apply 合并修改并校验结果，只写入有变化的字段
+	DeleteRole(ctx context.Context, id uint, version uint, force bool) error                                                 // 角色仍有用户时返回 apperr.ErrRoleInUse（data 中列出这些用户），force 为 true 时同时解除角色与所有用户、权限的关联
+	RestoreRole(ctx context.Context, id uint, version uint) (*model.Role, error)                                             // 恢复已软删除的角色，名称已被其他角色使用时返回 apperr.ErrRoleNameTaken
+	PurgeRole(ctx context.Context, id uint) error                                                                            // 彻底删除，只能删除已软删除的记录
+	ListRoles(ctx context.Context, spec *query.Spec, page query.Page) ([]*model.Role, *query.PageInfo, error)
+	BatchRoles(ctx context.Context, ops []RoleBatchOp, atomic bool) []BatchResult
 	// 角色权限管理
-	AssignPermissions(roleID uint, permissionIDs []uint) error
-	RemovePermissions(roleID uint, permissionIDs []uint) error
-	GetRolePermissions(roleID uint) ([]*model.Permission, error)
+	AssignPermissions(ctx context.Context, roleID uint, permissionIDs []uint) error
+	RemovePermissions(ctx context.Context, roleID uint, permissionIDs []uint) error
+	GetRolePermissions(ctx context.Context, roleID uint) ([]*model.Permission, error)
 	// 用户角色管理
-	AssignUsers(roleID uint, userIDs []uint) error
-	RemoveUsers(roleID uint, userIDs []uint) error
-	GetRoleUsers(roleID uint) ([]*model.User, error)
+	AssignUsers(ctx context.Context, roleID uint, userIDs []uint) error
+	RemoveUsers(ctx context.Context, roleID uint, userIDs []uint) error
+	GetRoleUsers(ctx context.Context, roleID uint) ([]*model.User, error)
+}
+
+// RoleBatchOp 角色批量操作：create 使用 Name、DisplayName、Description，update 使用 ID、Version、DisplayName、Description、Status，delete 使用 ID、Version
+type RoleBatchOp struct {
+	Op          string
+	ID          uint
+	Version     uint // 0 表示不校验版本号
+	Name        string
+	DisplayName string
+	Description string
+	Status      int // -1 表示不更新
 }
 
 type roleService struct {
-	roleRepo repository.RoleRepository
+	roleRepo   repository.RoleRepository
+	transactor repository.Transactor
 }
 
-func NewRoleService(roleRepo repository.RoleRepository) RoleService {
-	return &roleService{roleRepo: roleRepo}
+func NewRoleService(roleRepo repository.RoleRepository, transactor repository.Transactor) RoleService {
+	return &roleService{roleRepo: roleRepo, transactor: transactor}
 }
 
-func (s *roleService) CreateRole(name, displayName, description string) (*model.Role, error) {
+func (s *roleService) CreateRole(ctx context.Context, name, displayName, description string) (*model.Role, error) {
+	ctx = forWrite(ctx)
 	// 检查角色名称是否已存在
-	existingRole, err := s.roleRepo.GetByName(name)
+	existingRole, err := s.roleRepo.GetByName(ctx, name)
 	if err == nil && existingRole != nil {
-		return nil, errors.New("角色名称已存在")
+		return nil, apperr.ErrRoleNameTaken
 	}
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
@@ -51,27 +73,38 @@ func (s *roleService) CreateRole(name, displayName, description string) (*model.
 		Status:      1,
 	}
 
-	err = s.roleRepo.Create(role)
+	err = s.roleRepo.Create(ctx, role)
 	if err != nil {
-		return nil, err
+		return nil, duplicated(err, apperr.ErrRoleNameTaken)
 	}
 
 	return role, nil
 }
 
-func (s *roleService) GetRoleByID(id uint) (*model.Role, error) {
-	return s.roleRepo.GetByID(id)
+func (s *roleService) GetRoleByID(ctx context.Context, id uint) (*model.Role, error) {
+	role, err := s.roleRepo.GetByID(ctx, id)
+	return role, notFound(err, apperr.ErrRoleNotFound)
 }
 
-func (s *roleService) GetRoleByName(name string) (*model.Role, error) {
-	return s.roleRepo.GetByName(name)
+func (s *roleService) GetRoleProjected(ctx context.Context, id uint, proj *query.Projection) (*model.Role, error) {
+	role, err := s.roleRepo.GetProjected(ctx, id, proj)
+	return role, notFound(err, apperr.ErrRoleNotFound)
 }
 
-func (s *roleService) UpdateRole(id uint, displayName, description string, status int) (*model.Role, error) {
-	role, err := s.roleRepo.GetByID(id)
+func (s *roleService) GetRoleByName(ctx context.Context, name string) (*model.Role, error) {
+	role, err := s.roleRepo.GetByName(ctx, name)
+	return role, notFound(err, apperr.ErrRoleNotFound)
+}
+
+func (s *roleService) UpdateRole(ctx context.Context, id uint, version uint, displayName, description string, status int) (*model.Role, error) {
+	ctx = forWrite(ctx)
+	role, err := s.GetRoleByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkVersion(version, role.Version); err != nil {
+		return nil, err
+	}
 
 	if displayName != "" {
 		role.DisplayName = displayName
@@ -83,7 +116,7 @@ func (s *roleService) UpdateRole(id uint, displayName, description string, statu
 		role.Status = status
 	}
 
-	err = s.roleRepo.Update(role)
+	err = s.roleRepo.Update(ctx, role)
 	if err != nil {
 		return nil, err
 	}
@@ -91,35 +124,158 @@ func (s *roleService) UpdateRole(id uint, displayName, description string, statu
 	return role, nil
 }
 
-func (s *roleService) DeleteRole(id uint) error {
-	return s.roleRepo.Delete(id)
+// PatchRole 由 apply 在当前记录上合并修改，只更新显示名称、描述、状态中有变化的字段，审计日志只记录这些字段
+// 没有变化时不写入，版本号保持不变
+func (s *roleService) PatchRole(ctx context.Context, id uint, version uint, apply func(role *model.Role) error) (*model.Role, error) {
+	ctx = forWrite(ctx)
+	role, err := s.GetRoleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(version, role.Version); err != nil {
+		return nil, err
+	}
+
+	before := *role
+	if err := apply(role); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]interface{})
+	if role.DisplayName != before.DisplayName {
+		fields["display_name"] = role.DisplayName
+	}
+	if role.Description != before.Description {
+		fields["description"] = role.Description
+	}
+	if role.Status != before.Status {
+		fields["status"] = role.Status
+	}
+	if len(fields) == 0 {
+		return &before, nil
+	}
+
+	if err := s.roleRepo.Patch(ctx, role, fields); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+func (s *roleService) DeleteRole(ctx context.Context, id uint, version uint, force bool) error {
+	err := s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		users, total, err := s.roleRepo.GetHolders(ctx, id, maxDependents)
+		if err != nil {
+			return err
+		}
+		if total > 0 && !force {
+			return apperr.ErrRoleInUse.WithMessage(i18n.MsgRoleInUse, total).WithData(userDependents(users, total))
+		}
+		// 强制删除时同时解除角色的用户和权限，不保留任何关联
+		if force {
+			if err := s.roleRepo.UnlinkAll(ctx, id); err != nil {
+				return err
+			}
+		}
+		return s.roleRepo.Delete(ctx, id, version)
+	})
+	return notFound(deleteVersionError(err), apperr.ErrRoleNotFound)
+}
+
+func (s *roleService) RestoreRole(ctx context.Context, id uint, version uint) (*model.Role, error) {
+	ctx = forWrite(ctx)
+	role, err := s.roleRepo.GetWithDeleted(ctx, id)
+	if err != nil {
+		return nil, notFound(err, apperr.ErrRoleNotFound)
+	}
+	if !role.DeletedAt.Valid {
+		return nil, apperr.ErrNotDeleted
+	}
+	if err := checkVersion(version, role.Version); err != nil {
+		return nil, err
+	}
+
+	// 唯一索引只约束未删除的记录，删除期间可能已有同名记录
+	existing, err := s.roleRepo.GetByName(ctx, role.Name)
+	if err == nil && existing != nil {
+		return nil, apperr.ErrRoleNameTaken
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := s.roleRepo.Restore(ctx, role); err != nil {
+		return nil, duplicated(err, apperr.ErrRoleNameTaken)
+	}
+	return s.GetRoleByID(ctx, id)
+}
+
+func (s *roleService) PurgeRole(ctx context.Context, id uint) error {
+	ctx = forWrite(ctx)
+	role, err := s.roleRepo.GetWithDeleted(ctx, id)
+	if err != nil {
+		return notFound(err, apperr.ErrRoleNotFound)
+	}
+	if !role.DeletedAt.Valid {
+		return apperr.ErrNotDeleted
+	}
+	// 在事务中执行，关联行的审计日志在提交后写入
+	err = s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		return s.roleRepo.Purge(ctx, id)
+	})
+	return notFound(err, apperr.ErrRoleNotFound)
+}
+
+func (s *roleService) ListRoles(ctx context.Context, spec *query.Spec, page query.Page) ([]*model.Role, *query.PageInfo, error) {
+	return s.roleRepo.List(ctx, spec, page)
 }
 
-func (s *roleService) ListRoles(page, pageSize int) ([]*model.Role, int64, error) {
-	offset := (page - 1) * pageSize
-	return s.roleRepo.List(offset, pageSize)
+// BatchRoles 批量创建、更新、删除角色，atomic 为 true 时全部成功或全部回滚
+func (s *roleService) BatchRoles(ctx context.Context, ops []RoleBatchOp, atomic bool) []BatchResult {
+	return runBatch(ctx, s.transactor, len(ops), atomic, func(ctx context.Context, i int) BatchResult {
+		op := ops[i]
+		switch op.Op {
+		case BatchCreate:
+			role, err := s.CreateRole(ctx, op.Name, op.DisplayName, op.Description)
+			if err != nil {
+				return BatchResult{Err: err}
+			}
+			return BatchResult{ID: role.ID, Data: role}
+		case BatchUpdate:
+			role, err := s.UpdateRole(ctx, op.ID, op.Version, op.DisplayName, op.Description, op.Status)
+			if err != nil {
+				return BatchResult{ID: op.ID, Err: err}
+			}
+			return BatchResult{ID: op.ID, Data: role}
+		case BatchDelete:
+			return BatchResult{ID: op.ID, Err: s.DeleteRole(ctx, op.ID, op.Version, false)}
+		default:
+			return BatchResult{ID: op.ID, Err: apperr.ErrBadRequest.WithMessage(i18n.MsgBatchOpUnknown, op.Op)}
+		}
+	})
 }
 
-func (s *roleService) AssignPermissions(roleID uint, permissionIDs []uint) error {
-	return s.roleRepo.AssignPermissions(roleID, permissionIDs)
+func (s *roleService) AssignPermissions(ctx context.Context, roleID uint, permissionIDs []uint) error {
+	return notFound(s.roleRepo.AssignPermissions(forWrite(ctx), roleID, permissionIDs), apperr.ErrRoleNotFound)
 }
 
-func (s *roleService) RemovePermissions(roleID uint, permissionIDs []uint) error {
-	return s.roleRepo.RemovePermissions(roleID, permissionIDs)
+func (s *roleService) RemovePermissions(ctx context.Context, roleID uint, permissionIDs []uint) error {
+	return notFound(s.roleRepo.RemovePermissions(forWrite(ctx), roleID, permissionIDs), apperr.ErrRoleNotFound)
 }
 
-func (s *roleService) GetRolePermissions(roleID uint) ([]*model.Permission, error) {
-	return s.roleRepo.GetPermissions(roleID)
+func (s *roleService) GetRolePermissions(ctx context.Context, roleID uint) ([]*model.Permission, error) {
+	permissions, err := s.roleRepo.GetPermissions(ctx, roleID)
+	return permissions, notFound(err, apperr.ErrRoleNotFound)
 }
 
-func (s *roleService) AssignUsers(roleID uint, userIDs []uint) error {
-	return s.roleRepo.AssignUsers(roleID, userIDs)
+func (s *roleService) AssignUsers(ctx context.Context, roleID uint, userIDs []uint) error {
+	return notFound(s.roleRepo.AssignUsers(forWrite(ctx), roleID, userIDs), apperr.ErrRoleNotFound)
 }
 
-func (s *roleService) RemoveUsers(roleID uint, userIDs []uint) error {
-	return s.roleRepo.RemoveUsers(roleID, userIDs)
+func (s *roleService) RemoveUsers(ctx context.Context, roleID uint, userIDs []uint) error {
+	return notFound(s.roleRepo.RemoveUsers(forWrite(ctx), roleID, userIDs), apperr.ErrRoleNotFound)
 }
 
-func (s *roleService) GetRoleUsers(roleID uint) ([]*model.User, error) {
-	return s.roleRepo.GetUsers(roleID)
+func (s *roleService) GetRoleUsers(ctx context.Context, roleID uint) ([]*model.User, error) {
+	users, err := s.roleRepo.GetUsers(ctx, roleID)
+	return users, notFound(err, apperr.ErrRoleNotFound)
 }
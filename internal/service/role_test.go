@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"go_web/internal/database"
+	"go_web/internal/model"
+	"go_web/internal/repository"
+
+	"gorm.io/gorm/clause"
+)
+
+// TestDeleteRoleForce 强制删除角色时解除其全部用户和权限关联，并为每一条关联记录审计日志
+func TestDeleteRoleForce(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.Use(database.NewAuditPlugin(db, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	role := &model.Role{Name: "editor", DisplayName: "Editor"}
+	user := &model.User{Name: "alice", Email: "alice@example.com", Password: "x"}
+	permission := &model.Permission{Name: "post:read", DisplayName: "Read posts", Resource: "post", Action: "read"}
+	for _, record := range []interface{}{role, user, permission} {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := []interface{}{
+		&model.UserRole{UserID: user.ID, RoleID: role.ID},
+		&model.RolePermission{RoleID: role.ID, PermissionID: permission.ID},
+	}
+	for _, link := range links {
+		if err := db.Omit(clause.Associations).Create(link).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := NewRoleService(repository.NewRoleRepository(db), repository.NewTransactor(db))
+	if err := s.DeleteRole(context.Background(), role.ID, 0, true); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, table := range []string{"user_roles", "role_permissions"} {
+		var rows, purged int64
+		if err := db.Table(table).Where("role_id = ?", role.ID).Count(&rows).Error; err != nil {
+			t.Fatal(err)
+		}
+		if rows != 0 {
+			t.Errorf("%s rows = %d, want 0", table, rows)
+		}
+		if err := db.Model(&database.AuditLog{}).Where("table_name = ? AND action = ?", table, "purge").Count(&purged).Error; err != nil {
+			t.Fatal(err)
+		}
+		if purged != 1 {
+			t.Errorf("%s purge audit logs = %d, want 1", table, purged)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go_web/internal/config"
+	"go_web/internal/logger"
+	"go_web/internal/model"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 告警规则的统计维度
+const (
+	AlertGroupByUser  = "user"  // 按用户ID统计
+	AlertGroupByEmail = "email" // 按邮箱统计
+	AlertGroupByIP    = "ip"    // 按客户端IP统计
+)
+
+// AlertRule 安全告警阈值规则：同一统计对象在窗口内出现 Threshold 次指定事件时触发告警
+type AlertRule struct {
+	Name      string
+	EventType string
+	GroupBy   string
+	Threshold int
+	Window    time.Duration
+}
+
+// SecurityAlert 安全告警
+type SecurityAlert struct {
+	Rule        string               `json:"rule"`         // 规则名称
+	EventType   string               `json:"event_type"`   // 事件类型
+	Subject     string               `json:"subject"`      // 统计对象，如 user:1、ip:127.0.0.1
+	Count       int                  `json:"count"`        // 窗口内的事件次数
+	Window      string               `json:"window"`       // 统计窗口
+	Event       *model.SecurityEvent `json:"event"`        // 触发告警的事件
+	TriggeredAt time.Time            `json:"triggered_at"` // 触发时间
+}
+
+// AlertNotifier 告警通知接口，可替换为邮件、IM 等实现
+type AlertNotifier interface {
+	Notify(ctx context.Context, alert *SecurityAlert) error
+}
+
+// DefaultAlertRules 根据配置生成默认告警规则，阈值为0的规则不启用
+func DefaultAlertRules(cfg *config.Config) []AlertRule {
+	window := time.Duration(cfg.Security.AlertWindow) * time.Second
+	if window <= 0 {
+		window = time.Minute
+	}
+
+	candidates := []AlertRule{
+		{Name: "forbidden_per_user", EventType: model.SecurityEventForbidden, GroupBy: AlertGroupByUser, Threshold: cfg.Security.ForbiddenThreshold, Window: window},
+		{Name: "login_failed_per_email", EventType: model.SecurityEventLoginFailed, GroupBy: AlertGroupByEmail, Threshold: cfg.Security.LoginFailureThreshold, Window: window},
+		{Name: "invalid_token_per_ip", EventType: model.SecurityEventInvalidToken, GroupBy: AlertGroupByIP, Threshold: cfg.Security.InvalidTokenThreshold, Window: window},
+	}
+
+	var rules []AlertRule
+	for _, rule := range candidates {
+		if rule.Threshold > 0 {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// NewAlertNotifier 创建告警通知器：始终写入日志，配置了 Webhook 时同时推送
+func NewAlertNotifier(cfg *config.Config, log *logger.Logger) AlertNotifier {
+	notifiers := []AlertNotifier{&LogAlertNotifier{log: log}}
+	if cfg.Security.AlertWebhook != "" {
+		notifiers = append(notifiers, NewWebhookAlertNotifier(cfg.Security.AlertWebhook.Value()))
+	}
+	return multiAlertNotifier(notifiers)
+}
+
+// LogAlertNotifier 将告警写入日志
+type LogAlertNotifier struct {
+	log *logger.Logger
+}
+
+func (n *LogAlertNotifier) Notify(ctx context.Context, alert *SecurityAlert) error {
+	n.log.WithFields(logrus.Fields{
+		"rule":       alert.Rule,
+		"event_type": alert.EventType,
+		"subject":    alert.Subject,
+		"count":      alert.Count,
+		"window":     alert.Window,
+	}).Warn("安全告警")
+	return nil
+}
+
+// WebhookAlertNotifier 以 JSON 格式将告警 POST 到指定地址
+type WebhookAlertNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookAlertNotifier(url string) *WebhookAlertNotifier {
+	return &WebhookAlertNotifier{
+		url:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (n *WebhookAlertNotifier) Notify(ctx context.Context, alert *SecurityAlert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("告警 Webhook 返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// multiAlertNotifier 依次调用多个通知器
+type multiAlertNotifier []AlertNotifier
+
+func (m multiAlertNotifier) Notify(ctx context.Context, alert *SecurityAlert) error {
+	var firstErr error
+	for _, n := range m {
+		if err := n.Notify(ctx, alert); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// 告警计数器最多跟踪的统计对象数量，以及后台清理过期计数的间隔
+const (
+	alertCounterMaxKeys       = 10000
+	alertCounterPruneInterval = time.Minute
+)
+
+// alertCounter 滑动窗口计数器（进程内，多实例部署时各实例独立计数）
+// 后台定时清理过期的计数；统计对象达到上限时先清理过期计数，仍然已满则淘汰最久未出现的对象
+type alertCounter struct {
+	mu   sync.Mutex
+	hits map[string]*alertHits
+}
+
+// alertHits 一个统计对象在窗口内的事件时间，window 为所属规则的统计窗口
+type alertHits struct {
+	times  []time.Time
+	window time.Duration
+}
+
+// expired 判断最近一次事件是否已超出统计窗口
+func (h *alertHits) expired(now time.Time) bool {
+	return len(h.times) == 0 || now.Sub(h.times[len(h.times)-1]) > h.window
+}
+
+func newAlertCounter() *alertCounter {
+	c := &alertCounter{hits: make(map[string]*alertHits)}
+	go c.pruneLoop(alertCounterPruneInterval)
+	return c
+}
+
+// pruneLoop 定时清理过期计数，计数器随服务进程存活，不需要停止
+func (c *alertCounter) pruneLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		c.mu.Lock()
+		c.prune(now)
+		c.mu.Unlock()
+	}
+}
+
+// prune 删除已过期的计数，调用方需持有锁
+func (c *alertCounter) prune(now time.Time) {
+	for k, h := range c.hits {
+		if h.expired(now) {
+			delete(c.hits, k)
+		}
+	}
+}
+
+// evictOldest 淘汰最近一次事件最早的统计对象，调用方需持有锁
+func (c *alertCounter) evictOldest() {
+	var oldestKey string
+	var oldest time.Time
+	for k, h := range c.hits {
+		if len(h.times) == 0 {
+			delete(c.hits, k)
+			return
+		}
+		last := h.times[len(h.times)-1]
+		if oldestKey == "" || last.Before(oldest) {
+			oldestKey, oldest = k, last
+		}
+	}
+	delete(c.hits, oldestKey)
+}
+
+// hit 记录一次事件，返回窗口内的次数以及是否达到阈值；达到阈值后清零，避免重复告警
+func (c *alertCounter) hit(key string, now time.Time, rule AlertRule) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	h, ok := c.hits[key]
+	if !ok {
+		if len(c.hits) >= alertCounterMaxKeys {
+			c.prune(now)
+			if len(c.hits) >= alertCounterMaxKeys {
+				c.evictOldest()
+			}
+		}
+		h = &alertHits{}
+	}
+	h.window = rule.Window
+
+	valid := h.times[:0]
+	for _, t := range h.times {
+		if now.Sub(t) <= rule.Window {
+			valid = append(valid, t)
+		}
+	}
+	valid = append(valid, now)
+
+	count := len(valid)
+	if count >= rule.Threshold {
+		delete(c.hits, key)
+		return count, true
+	}
+	h.times = valid
+	c.hits[key] = h
+	return count, false
+}
+
+// alertSubject 根据规则的统计维度获取事件的统计对象
+func alertSubject(rule AlertRule, event *model.SecurityEvent) string {
+	switch rule.GroupBy {
+	case AlertGroupByUser:
+		if event.UserID > 0 {
+			return "user:" + strconv.FormatUint(uint64(event.UserID), 10)
+		}
+	case AlertGroupByEmail:
+		if event.Email != "" {
+			return "email:" + event.Email
+		}
+	case AlertGroupByIP:
+		if event.IP != "" {
+			return "ip:" + event.IP
+		}
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go_web/internal/config"
+	"go_web/internal/database"
+	"go_web/internal/logger"
+	"go_web/internal/model"
+	"go_web/internal/query"
+	"go_web/internal/repository"
+	"go_web/internal/util"
+)
+
+type SecurityEventService interface {
+	// RecordEvent 记录安全事件并检查告警规则，事件通过审计写入器异步写入
+	RecordEvent(ctx context.Context, event *model.SecurityEvent) error
+	ListEvents(ctx context.Context, filter repository.SecurityEventFilter, page query.Page) ([]*model.SecurityEvent, *query.PageInfo, error)
+}
+
+type securityEventService struct {
+	securityEventRepo repository.SecurityEventRepository
+	writer            *database.AuditWriter
+	notifier          AlertNotifier
+	log               *logger.Logger
+	rules             []AlertRule
+	counter           *alertCounter
+}
+
+func NewSecurityEventService(securityEventRepo repository.SecurityEventRepository, writer *database.AuditWriter, notifier AlertNotifier, cfg *config.Config, log *logger.Logger) SecurityEventService {
+	return &securityEventService{
+		securityEventRepo: securityEventRepo,
+		writer:            writer,
+		notifier:          notifier,
+		log:               log,
+		rules:             DefaultAlertRules(cfg),
+		counter:           newAlertCounter(),
+	}
+}
+
+func (s *securityEventService) RecordEvent(ctx context.Context, event *model.SecurityEvent) error {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	// 客户端可控的字段可能超出列宽，超长的行在 MySQL、PostgreSQL 上写入失败会被丢弃
+	truncateEvent(event)
+
+	// 认证和授权失败都会调用，不在请求路径上同步写库；队列满时按审计写入器的策略丢弃并计数
+	// 告警规则在内存中统计，不受写入结果影响
+	s.writer.WriteSecurityEvent(event)
+	s.checkRules(event)
+	return nil
+}
+
+func (s *securityEventService) ListEvents(ctx context.Context, filter repository.SecurityEventFilter, page query.Page) ([]*model.SecurityEvent, *query.PageInfo, error) {
+	return s.securityEventRepo.List(ctx, filter, page)
+}
+
+// truncateEvent 将事件的字符串字段截断到数据库列宽
+func truncateEvent(event *model.SecurityEvent) {
+	event.EventType = util.Truncate(event.EventType, 50)
+	event.Email = util.Truncate(event.Email, 100)
+	event.IP = util.Truncate(event.IP, 50)
+	event.UserAgent = util.Truncate(event.UserAgent, 255)
+	event.Method = util.Truncate(event.Method, 10)
+	event.Path = util.Truncate(event.Path, 255)
+	event.Reason = util.Truncate(event.Reason, 255)
+	event.RequestID = util.Truncate(event.RequestID, 64)
+}
+
+// checkRules 检查事件是否触发告警规则，触发时异步通知
+func (s *securityEventService) checkRules(event *model.SecurityEvent) {
+	for _, rule := range s.rules {
+		if rule.EventType != event.EventType {
+			continue
+		}
+		subject := alertSubject(rule, event)
+		if subject == "" {
+			continue
+		}
+
+		count, triggered := s.counter.hit(rule.Name+"|"+subject, event.CreatedAt, rule)
+		if !triggered {
+			continue
+		}
+
+		alert := &SecurityAlert{
+			Rule:        rule.Name,
+			EventType:   rule.EventType,
+			Subject:     subject,
+			Count:       count,
+			Window:      rule.Window.String(),
+			Event:       event,
+			TriggeredAt: time.Now(),
+		}
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := s.notifier.Notify(ctx, alert); err != nil {
+				s.log.Errorf("发送安全告警失败: %v", err)
+			}
+		}()
+	}
+}
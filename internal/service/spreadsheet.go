@@ -0,0 +1,22 @@
+package service
+
+import "strings"
+
+// formulaPrefixes 表格软件（Excel 等）会把以这些字符开头的单元格当作公式执行
+const formulaPrefixes = "=+-@\t\r"
+
+// escapeCell 在可能被当作公式的文本前加单引号，避免导出文件被打开时执行用户填写的公式（CSV/公式注入）
+func escapeCell(value string) string {
+	if value != "" && strings.IndexByte(formulaPrefixes, value[0]) >= 0 {
+		return "'" + value
+	}
+	return value
+}
+
+// unescapeCell 去掉 escapeCell 添加的单引号，导出的文件可以直接导入
+func unescapeCell(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.IndexByte(formulaPrefixes, value[1]) >= 0 {
+		return value[1:]
+	}
+	return value
+}
@@ -0,0 +1,29 @@
+package service
+
+import "testing"
+
+func TestEscapeCell(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: ""},
+		{value: "alice", want: "alice"},
+		{value: "=HYPERLINK(\"http://evil\")", want: "'=HYPERLINK(\"http://evil\")"},
+		{value: "+1", want: "'+1"},
+		{value: "-1", want: "'-1"},
+		{value: "@SUM(A1)", want: "'@SUM(A1)"},
+		{value: "\tx", want: "'\tx"},
+		{value: "'quoted", want: "'quoted"},
+		{value: `{"name":"=1"}`, want: `{"name":"=1"}`},
+	}
+	for _, tt := range tests {
+		got := escapeCell(tt.value)
+		if got != tt.want {
+			t.Errorf("escapeCell(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+		if back := unescapeCell(got); back != tt.value {
+			t.Errorf("unescapeCell(%q) = %q, want %q", got, back, tt.value)
+		}
+	}
+}
@@ -1,9 +1,15 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"runtime"
+	"sync"
 
+	"go_web/internal/apperr"
+	"go_web/internal/i18n"
 	"go_web/internal/model"
+	"go_web/internal/query"
 	"go_web/internal/repository"
 
 	"golang.org/x/crypto/bcrypt"
@@ -11,69 +17,140 @@ import (
 )
 
 type UserService interface {
-	CreateUser(name, email, password string) (*model.User, error)
-	GetUserByID(id uint) (*model.User, error)
-	GetUserByEmail(email string) (*model.User, error)
-	UpdateUser(id uint, name string, status int) (*model.User, error)
-	DeleteUser(id uint) error
-	ListUsers(page, pageSize int) ([]*model.User, int64, error)
+	CreateUser(ctx context.Context, name, email, password string) (*model.User, error)
+	CreateUserWithHash(ctx context.Context, name, email, hashedPassword string) (*model.User, error) // 使用已计算的密码哈希（见 HashPasswords）创建用户，避免在事务中计算哈希
+	GetUserByID(ctx context.Context, id uint) (*model.User, error)
+	GetUserProjected(ctx context.Context, id uint, proj *query.Projection) (*model.User, error) // 按 fields 和 include 查询，proj 为 nil 时同 GetUserByID
+	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
+	UpdateUser(ctx context.Context, id uint, version uint, name string, status int, language string) (*model.User, error) // version 为客户端持有的版本号，0 表示不校验
+	PatchUser(ctx context.Context, id uint, version uint, apply func(user *model.User) error) (*model.User, error)        // apply 合并修改并校验结果，只写入有变化的字段
+	DeleteUser(ctx context.Context, id uint, version uint) error
+	RestoreUser(ctx context.Context, id uint, version uint) (*model.User, error) // 恢复已软删除的用户，邮箱已被其他用户使用时返回 apperr.ErrUserEmailTaken
+	PurgeUser(ctx context.Context, id uint) error                                // 彻底删除，只能删除已软删除的记录
+	ListUsers(ctx context.Context, spec *query.Spec, page query.Page) ([]*model.User, *query.PageInfo, error)
+	BatchUsers(ctx context.Context, ops []UserBatchOp, atomic bool) []BatchResult
+	ResetPassword(ctx context.Context, id uint, password string) error
+	GetUserLanguage(ctx context.Context, id uint) (string, error) // 用户的语言偏好，未设置时为空
+	GetUserRoles(ctx context.Context, id uint) ([]*model.Role, error)
 	// 权限检查
-	HasPermission(userID uint, resource, action string) (bool, error)
+	HasPermission(ctx context.Context, userID uint, resource, action string) (bool, error)
+}
+
+// UserBatchOp 用户批量操作：create 使用 Name、Email、Password，update 使用 ID、Version、Name、Status、Language，delete 使用 ID、Version
+type UserBatchOp struct {
+	Op       string
+	ID       uint
+	Version  uint // 0 表示不校验版本号
+	Name     string
+	Email    string
+	Password string
+	Status   int    // -1 表示不更新
+	Language string // 空字符串表示不更新
 }
 
 type userService struct {
-	userRepo repository.UserRepository
+	userRepo   repository.UserRepository
+	transactor repository.Transactor
+	languages  *UserLanguageCache
 }
 
-func NewUserService(userRepo repository.UserRepository) UserService {
-	return &userService{userRepo: userRepo}
+func NewUserService(userRepo repository.UserRepository, transactor repository.Transactor, languages *UserLanguageCache) UserService {
+	return &userService{userRepo: userRepo, transactor: transactor, languages: languages}
 }
 
-func (s *userService) CreateUser(name, email, password string) (*model.User, error) {
+func (s *userService) CreateUser(ctx context.Context, name, email, password string) (*model.User, error) {
+	// 使用 bcrypt 对密码进行哈希
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, apperr.ErrInternal.WithMessage(i18n.MsgPasswordHashFailed).Wrap(err)
+	}
+	return s.createUser(ctx, name, email, string(hashedPassword))
+}
+
+func (s *userService) CreateUserWithHash(ctx context.Context, name, email, hashedPassword string) (*model.User, error) {
+	return s.createUser(ctx, name, email, hashedPassword)
+}
+
+// HashPasswords 并发计算密码哈希（并发数为 CPU 数），include 返回 false 的位置不计算
+// bcrypt 计算较慢，批量创建用户时应在开启事务前计算，避免长时间持有事务和锁
+func HashPasswords(passwords []string, include func(i int) bool) ([]string, []error) {
+	hashed := make([]string, len(passwords))
+	errs := make([]error, len(passwords))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	for i, password := range passwords {
+		if !include(i) {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, password string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+			if err != nil {
+				errs[i] = apperr.ErrInternal.WithMessage(i18n.MsgPasswordHashFailed).Wrap(err)
+				return
+			}
+			hashed[i] = string(hash)
+		}(i, password)
+	}
+	wg.Wait()
+	return hashed, errs
+}
+
+// createUser 使用已计算的密码哈希创建用户
+func (s *userService) createUser(ctx context.Context, name, email, hashedPassword string) (*model.User, error) {
+	ctx = forWrite(ctx)
 	// 检查邮箱是否已存在
-	existingUser, err := s.userRepo.GetByEmail(email)
+	existingUser, err := s.userRepo.GetByEmail(ctx, email)
 	if err == nil && existingUser != nil {
-		return nil, errors.New("邮箱已存在")
+		return nil, apperr.ErrUserEmailTaken
 	}
 	// 如果查询出错但不是"记录不存在"的错误，应该返回错误
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
 	}
 
-	// 使用 bcrypt 对密码进行哈希
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return nil, errors.New("密码加密失败")
-	}
-
 	user := &model.User{
 		Name:     name,
 		Email:    email,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
 		Status:   1,
 	}
 
-	err = s.userRepo.Create(user)
+	err = s.userRepo.Create(ctx, user)
 	if err != nil {
-		return nil, err
+		return nil, duplicated(err, apperr.ErrUserEmailTaken)
 	}
 
 	return user, nil
 }
 
-func (s *userService) GetUserByID(id uint) (*model.User, error) {
-	return s.userRepo.GetByID(id)
+func (s *userService) GetUserByID(ctx context.Context, id uint) (*model.User, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
+	return user, notFound(err, apperr.ErrUserNotFound)
+}
+
+func (s *userService) GetUserProjected(ctx context.Context, id uint, proj *query.Projection) (*model.User, error) {
+	user, err := s.userRepo.GetProjected(ctx, id, proj)
+	return user, notFound(err, apperr.ErrUserNotFound)
 }
 
-func (s *userService) GetUserByEmail(email string) (*model.User, error) {
-	return s.userRepo.GetByEmail(email)
+func (s *userService) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	return user, notFound(err, apperr.ErrUserNotFound)
 }
 
-func (s *userService) UpdateUser(id uint, name string, status int) (*model.User, error) {
-	user, err := s.userRepo.GetByID(id)
+func (s *userService) UpdateUser(ctx context.Context, id uint, version uint, name string, status int, language string) (*model.User, error) {
+	ctx = forWrite(ctx)
+	user, err := s.GetUserByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkVersion(version, user.Version); err != nil {
+		return nil, err
+	}
 
 	// 只有当name不为空时才更新
 	if name != "" {
@@ -83,25 +160,188 @@ func (s *userService) UpdateUser(id uint, name string, status int) (*model.User,
 	if status >= 0 {
 		user.Status = status
 	}
+	if language != "" {
+		user.Language = language
+	}
 
-	err = s.userRepo.Update(user)
+	err = s.userRepo.Update(ctx, user)
 	if err != nil {
 		return nil, err
 	}
+	s.languages.invalidate(id)
+
+	return user, nil
+}
 
+// PatchUser 由 apply 在当前记录上合并修改，只更新姓名、状态、语言偏好中有变化的字段，审计日志只记录这些字段
+// 没有变化时不写入，版本号保持不变
+func (s *userService) PatchUser(ctx context.Context, id uint, version uint, apply func(user *model.User) error) (*model.User, error) {
+	ctx = forWrite(ctx)
+	user, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(version, user.Version); err != nil {
+		return nil, err
+	}
+
+	before := *user
+	if err := apply(user); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]interface{})
+	if user.Name != before.Name {
+		fields["name"] = user.Name
+	}
+	if user.Status != before.Status {
+		fields["status"] = user.Status
+	}
+	if user.Language != before.Language {
+		fields["language"] = user.Language
+	}
+	if len(fields) == 0 {
+		return &before, nil
+	}
+
+	if err := s.userRepo.Patch(ctx, user, fields); err != nil {
+		return nil, err
+	}
+	s.languages.invalidate(id)
 	return user, nil
 }
 
-func (s *userService) DeleteUser(id uint) error {
-	return s.userRepo.Delete(id)
+// GetUserLanguage 优先使用缓存，修改用户时缓存失效
+func (s *userService) GetUserLanguage(ctx context.Context, id uint) (string, error) {
+	if language, ok := s.languages.get(id); ok {
+		return language, nil
+	}
+	language, err := s.userRepo.GetLanguage(ctx, id)
+	if err != nil {
+		return "", notFound(err, apperr.ErrUserNotFound)
+	}
+	s.languages.set(id, language)
+	return language, nil
+}
+
+func (s *userService) DeleteUser(ctx context.Context, id uint, version uint) error {
+	return notFound(deleteVersionError(s.userRepo.Delete(ctx, id, version)), apperr.ErrUserNotFound)
+}
+
+func (s *userService) RestoreUser(ctx context.Context, id uint, version uint) (*model.User, error) {
+	ctx = forWrite(ctx)
+	user, err := s.userRepo.GetWithDeleted(ctx, id)
+	if err != nil {
+		return nil, notFound(err, apperr.ErrUserNotFound)
+	}
+	if !user.DeletedAt.Valid {
+		return nil, apperr.ErrNotDeleted
+	}
+	if err := checkVersion(version, user.Version); err != nil {
+		return nil, err
+	}
+
+	// 唯一索引只约束未删除的记录，删除期间可能已有同名记录
+	existing, err := s.userRepo.GetByEmail(ctx, user.Email)
+	if err == nil && existing != nil {
+		return nil, apperr.ErrUserEmailTaken
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := s.userRepo.Restore(ctx, user); err != nil {
+		return nil, duplicated(err, apperr.ErrUserEmailTaken)
+	}
+	return s.GetUserByID(ctx, id)
+}
+
+func (s *userService) PurgeUser(ctx context.Context, id uint) error {
+	ctx = forWrite(ctx)
+	user, err := s.userRepo.GetWithDeleted(ctx, id)
+	if err != nil {
+		return notFound(err, apperr.ErrUserNotFound)
+	}
+	if !user.DeletedAt.Valid {
+		return apperr.ErrNotDeleted
+	}
+	// 在事务中执行，关联行的审计日志在提交后写入
+	err = s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		return s.userRepo.Purge(ctx, id)
+	})
+	return notFound(err, apperr.ErrUserNotFound)
+}
+
+func (s *userService) ListUsers(ctx context.Context, spec *query.Spec, page query.Page) ([]*model.User, *query.PageInfo, error) {
+	return s.userRepo.List(ctx, spec, page)
+}
+
+// BatchUsers 批量创建、更新、删除用户，atomic 为 true 时全部成功或全部回滚
+// 创建操作的密码哈希在开启事务之前并发计算，避免 bcrypt 长时间占用事务
+func (s *userService) BatchUsers(ctx context.Context, ops []UserBatchOp, atomic bool) []BatchResult {
+	passwords := make([]string, len(ops))
+	for i, op := range ops {
+		passwords[i] = op.Password
+	}
+	hashed, hashErrs := HashPasswords(passwords, func(i int) bool { return ops[i].Op == BatchCreate })
+
+	results := runBatch(ctx, s.transactor, len(ops), atomic, func(ctx context.Context, i int) BatchResult {
+		op := ops[i]
+		switch op.Op {
+		case BatchCreate:
+			if hashErrs[i] != nil {
+				return BatchResult{Err: hashErrs[i]}
+			}
+			user, err := s.createUser(ctx, op.Name, op.Email, hashed[i])
+			if err != nil {
+				return BatchResult{Err: err}
+			}
+			return BatchResult{ID: user.ID, Data: user}
+		case BatchUpdate:
+			user, err := s.UpdateUser(ctx, op.ID, op.Version, op.Name, op.Status, op.Language)
+			if err != nil {
+				return BatchResult{ID: op.ID, Err: err}
+			}
+			return BatchResult{ID: op.ID, Data: user}
+		case BatchDelete:
+			return BatchResult{ID: op.ID, Err: s.DeleteUser(ctx, op.ID, op.Version)}
+		default:
+			return BatchResult{ID: op.ID, Err: apperr.ErrBadRequest.WithMessage(i18n.MsgBatchOpUnknown, op.Op)}
+		}
+	})
+
+	// 事务中更新时已使缓存失效，提交前其他请求可能又缓存了旧值，提交后再失效一次
+	for _, op := range ops {
+		if op.Op == BatchUpdate {
+			s.languages.invalidate(op.ID)
+		}
+	}
+	return results
+}
+
+// ResetPassword 重置用户密码
+func (s *userService) ResetPassword(ctx context.Context, id uint, password string) error {
+	ctx = forWrite(ctx)
+	user, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return apperr.ErrInternal.WithMessage(i18n.MsgPasswordHashFailed).Wrap(err)
+	}
+	user.Password = string(hashedPassword)
+
+	return s.userRepo.Update(ctx, user)
 }
 
-func (s *userService) ListUsers(page, pageSize int) ([]*model.User, int64, error) {
-	offset := (page - 1) * pageSize
-	return s.userRepo.List(offset, pageSize)
+func (s *userService) GetUserRoles(ctx context.Context, id uint) ([]*model.Role, error) {
+	roles, err := s.userRepo.GetRoles(ctx, id)
+	return roles, notFound(err, apperr.ErrUserNotFound)
 }
 
 // HasPermission 检查用户是否拥有指定资源与操作的权限
-func (s *userService) HasPermission(userID uint, resource, action string) (bool, error) {
-	return s.userRepo.HasPermission(userID, resource, action)
+func (s *userService) HasPermission(ctx context.Context, userID uint, resource, action string) (bool, error) {
+	return s.userRepo.HasPermission(ctx, userID, resource, action)
 }
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// userLanguageTTL 语言偏好缓存的有效期，限制多实例部署时其他实例上的修改延迟生效的时间
+const userLanguageTTL = time.Minute
+
+// UserLanguageCache 用户语言偏好的进程内缓存
+// 已登录用户的每个请求都需要语言偏好，缓存避免每次查询数据库；修改用户或回滚用户的审计日志时失效
+type UserLanguageCache struct {
+	entries sync.Map // 用户ID -> userLanguage
+}
+
+// userLanguage 缓存的语言偏好及过期时间
+type userLanguage struct {
+	language string
+	expires  time.Time
+}
+
+func NewUserLanguageCache() *UserLanguageCache {
+	return &UserLanguageCache{}
+}
+
+// get 返回未过期的语言偏好
+func (c *UserLanguageCache) get(id uint) (string, bool) {
+	v, ok := c.entries.Load(id)
+	if !ok {
+		return "", false
+	}
+	entry := v.(userLanguage)
+	if time.Now().After(entry.expires) {
+		c.entries.Delete(id)
+		return "", false
+	}
+	return entry.language, true
+}
+
+func (c *UserLanguageCache) set(id uint, language string) {
+	c.entries.Store(id, userLanguage{language: language, expires: time.Now().Add(userLanguageTTL)})
+}
+
+func (c *UserLanguageCache) invalidate(id uint) {
+	c.entries.Delete(id)
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"go_web/internal/model"
+	"go_web/internal/repository"
+)
+
+// TestUserLanguageCache 语言偏好在缓存中读取，修改用户后读取到新的值
+func TestUserLanguageCache(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	s := NewUserService(repository.NewUserRepository(db), repository.NewTransactor(db), NewUserLanguageCache())
+
+	user, err := s.CreateUserWithHash(ctx, "alice", "alice@example.com", "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantLanguage := func(want string) {
+		t.Helper()
+		language, err := s.GetUserLanguage(ctx, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if language != want {
+			t.Errorf("language = %q, want %q", language, want)
+		}
+	}
+	wantLanguage("")
+
+	if user, err = s.UpdateUser(ctx, user.ID, user.Version, "", -1, "en"); err != nil {
+		t.Fatal(err)
+	}
+	wantLanguage("en")
+
+	if user, err = s.PatchUser(ctx, user.ID, user.Version, func(u *model.User) error {
+		u.Language = ""
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	wantLanguage("")
+
+	results := s.BatchUsers(ctx, []UserBatchOp{{Op: BatchUpdate, ID: user.ID, Version: user.Version, Status: -1, Language: "zh"}}, true)
+	if results[0].Err != nil {
+		t.Fatal(results[0].Err)
+	}
+	wantLanguage("zh")
+}
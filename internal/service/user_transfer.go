@@ -0,0 +1,496 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go_web/internal/apperr"
+	"go_web/internal/i18n"
+	"go_web/internal/model"
+	"go_web/internal/query"
+	"go_web/internal/repository"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+// 用户导入导出的文件格式
+const (
+	ExportFormatXLSX = "xlsx"
+)
+
+const (
+	userExportBatchSize = 500     // 导出时每批读取的用户数
+	userImportMaxRows   = 1000    // 单次导入的最大数据行数
+	userSheetName       = "users" // 导出 xlsx 的工作表名称
+)
+
+// userExportHeader 导出文件的表头，导入时按表头名称识别列，其他列忽略
+var userExportHeader = []string{"id", "name", "email", "status", "roles", "created_at"}
+
+// userImportColumns 导入文件的列，roles 可选
+var userImportColumns = []string{"name", "email", "password", "roles"}
+
+// importValidator 校验导入行的邮箱格式，规则与接口请求体的 email 校验相同
+var importValidator = validator.New()
+
+// UserImportRow 导入报告中的一行
+type UserImportRow struct {
+	Row    int      `json:"row" example:"2"`                      // 文件中的行号（表头为第 1 行）
+	Name   string   `json:"name" example:"张三"`                    // 用户姓名
+	Email  string   `json:"email" example:"zhangsan@example.com"` // 用户邮箱
+	Roles  []string `json:"roles"`                                // 分配的角色名称
+	UserID uint     `json:"user_id,omitempty" example:"10"`       // 导入后的用户ID，预览或失败时为空
+	Errors []string `json:"errors,omitempty"`                     // 该行的错误，为空表示校验通过
+}
+
+// UserImportReport 用户导入报告
+type UserImportReport struct {
+	DryRun   bool            `json:"dry_run" example:"false"` // 是否仅预览
+	Total    int             `json:"total" example:"2"`       // 数据行数
+	Valid    int             `json:"valid" example:"2"`       // 校验通过的行数
+	Invalid  int             `json:"invalid" example:"0"`     // 校验失败的行数
+	Imported int             `json:"imported" example:"2"`    // 实际导入的用户数，预览时为 0
+	Rows     []UserImportRow `json:"rows"`                    // 每行的结果，顺序与文件一致
+}
+
+// UserTransferService 用户导入导出
+type UserTransferService interface {
+	// ExportUsers 按列表的过滤、排序、搜索条件流式导出用户及其角色名称
+	// 查询条件无效时返回 query.ErrInvalid，此时不会向 w 写入任何数据
+	ExportUsers(ctx context.Context, spec *query.Spec, format string, w io.Writer) error
+	// ImportUsers 逐行校验导入文件后在一个事务中创建用户并分配角色，dryRun 为 true 时只校验不写入
+	// 有行校验或写入失败时返回报告和 apperr.ErrImportInvalid/apperr.ErrImportFailed，文件无法解析时返回 apperr.ErrImportFile
+	ImportUsers(ctx context.Context, format string, r io.Reader, dryRun bool) (*UserImportReport, error)
+}
+
+type userTransferService struct {
+	userService UserService
+	roleService RoleService
+	transactor  repository.Transactor
+}
+
+func NewUserTransferService(userService UserService, roleService RoleService, transactor repository.Transactor) UserTransferService {
+	return &userTransferService{userService: userService, roleService: roleService, transactor: transactor}
+}
+
+func (s *userTransferService) ExportUsers(ctx context.Context, spec *query.Spec, format string, w io.Writer) error {
+	switch format {
+	case ExportFormatCSV:
+		return s.exportCSV(ctx, spec, w)
+	case ExportFormatXLSX:
+		return s.exportXLSX(ctx, spec, w)
+	default:
+		return apperr.ErrUnsupportedFormat.WithMessage(i18n.MsgExportFormat, format)
+	}
+}
+
+// eachUserBatch 以游标分页逐批读取用户，首批读取失败时直接返回，不调用 fn
+func (s *userTransferService) eachUserBatch(ctx context.Context, spec *query.Spec, fn func(batch []*model.User) error) error {
+	page := query.Page{Size: userExportBatchSize, Keyset: true}
+	// 导出的列固定，忽略 fields 和 include，按默认方式加载角色
+	if spec != nil && spec.Projection != nil {
+		copied := *spec
+		copied.Projection = nil
+		spec = &copied
+	}
+	for {
+		users, info, err := s.userService.ListUsers(ctx, spec, page)
+		if err != nil {
+			return err
+		}
+		if err := fn(users); err != nil {
+			return err
+		}
+		if info.NextCursor == "" {
+			return nil
+		}
+		page.Cursor = info.NextCursor
+	}
+}
+
+// userExportRecord 导出文件中的一行，用户填写的文本列经过 escapeCell 处理
+func userExportRecord(user *model.User) []string {
+	roles := make([]string, 0, len(user.Roles))
+	for _, role := range user.Roles {
+		roles = append(roles, role.Name)
+	}
+	return []string{
+		strconv.FormatUint(uint64(user.ID), 10),
+		escapeCell(user.Name),
+		escapeCell(user.Email),
+		strconv.Itoa(user.Status),
+		escapeCell(strings.Join(roles, ";")),
+		user.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// exportCSV 以 CSV 格式导出，表头在首批数据读取成功后写出
+func (s *userTransferService) exportCSV(ctx context.Context, spec *query.Spec, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	headerWritten := false
+	err := s.eachUserBatch(ctx, spec, func(batch []*model.User) error {
+		if !headerWritten {
+			if err := writer.Write(userExportHeader); err != nil {
+				return err
+			}
+			headerWritten = true
+		}
+		for _, user := range batch {
+			if err := writer.Write(userExportRecord(user)); err != nil {
+				return err
+			}
+		}
+		// 每批写完后刷新，保证数据及时发送给客户端
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// exportXLSX 以 xlsx 格式导出，逐行写入工作表，xlsx 为 zip 格式，全部写完后才输出
+func (s *userTransferService) exportXLSX(ctx context.Context, spec *query.Spec, w io.Writer) error {
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := f.SetSheetName("Sheet1", userSheetName); err != nil {
+		return err
+	}
+	sw, err := f.NewStreamWriter(userSheetName)
+	if err != nil {
+		return err
+	}
+
+	row := 1
+	writeRow := func(record []string) error {
+		cells := make([]interface{}, len(record))
+		for i, value := range record {
+			cells[i] = value
+		}
+		cell, err := excelize.CoordinatesToCellName(1, row)
+		if err != nil {
+			return err
+		}
+		row++
+		return sw.SetRow(cell, cells)
+	}
+
+	if err := writeRow(userExportHeader); err != nil {
+		return err
+	}
+	err = s.eachUserBatch(ctx, spec, func(batch []*model.User) error {
+		for _, user := range batch {
+			if err := writeRow(userExportRecord(user)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+	return f.Write(w)
+}
+
+// userImportEntry 导入行解析后的内容
+type userImportEntry struct {
+	report   *UserImportRow
+	password string
+	roleIDs  []uint
+}
+
+func (s *userTransferService) ImportUsers(ctx context.Context, format string, r io.Reader, dryRun bool) (*UserImportReport, error) {
+	records, err := readImportRecords(format, r)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, apperr.ErrImportFile.WithMessage(i18n.MsgImportFileEmpty)
+	}
+
+	columns, err := importColumnIndex(records[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if !dryRun {
+		ctx = forWrite(ctx)
+	}
+	report := &UserImportReport{DryRun: dryRun, Rows: []UserImportRow{}}
+	var entries []*userImportEntry
+	for i, record := range records[1:] {
+		if blankRecord(record) {
+			continue
+		}
+		if len(entries) == userImportMaxRows {
+			return nil, apperr.ErrImportFile.WithMessage(i18n.MsgImportTooManyRows, userImportMaxRows)
+		}
+		cell := func(name string) string {
+			if idx, ok := columns[name]; ok && idx < len(record) {
+				return strings.TrimSpace(record[idx])
+			}
+			return ""
+		}
+		entries = append(entries, &userImportEntry{
+			report: &UserImportRow{
+				Row:   i + 2,
+				Name:  unescapeCell(cell("name")),
+				Email: unescapeCell(cell("email")),
+				Roles: splitRoleNames(unescapeCell(cell("roles"))),
+			},
+			password: cell("password"),
+		})
+	}
+	if len(entries) == 0 {
+		return nil, apperr.ErrImportFile.WithMessage(i18n.MsgImportNoRows)
+	}
+
+	if err := s.validateImport(ctx, entries); err != nil {
+		return nil, err
+	}
+
+	report.Total = len(entries)
+	for _, entry := range entries {
+		if len(entry.report.Errors) == 0 {
+			report.Valid++
+		} else {
+			report.Invalid++
+		}
+	}
+	collect := func() {
+		for _, entry := range entries {
+			report.Rows = append(report.Rows, *entry.report)
+		}
+	}
+	if report.Invalid > 0 {
+		collect()
+		return report, apperr.ErrImportInvalid
+	}
+	if dryRun {
+		collect()
+		return report, nil
+	}
+
+	if err := s.applyImport(ctx, entries); err != nil {
+		for _, entry := range entries {
+			entry.report.UserID = 0
+		}
+		collect()
+		return report, err
+	}
+	report.Imported = len(entries)
+	collect()
+	return report, nil
+}
+
+// validateImport 逐行校验：必填项、邮箱格式、密码长度、文件内及已有用户的邮箱重复、角色是否存在
+func (s *userTransferService) validateImport(ctx context.Context, entries []*userImportEntry) error {
+	firstRow := make(map[string]int) // 小写邮箱 -> 首次出现的行号
+	roleIDs := make(map[string]uint) // 角色名称 -> ID，0 表示不存在
+	lang := i18n.FromContext(ctx)
+	for _, entry := range entries {
+		row := entry.report
+		addError := func(key string, args ...interface{}) {
+			row.Errors = append(row.Errors, i18n.T(lang, key, args...))
+		}
+
+		if row.Name == "" {
+			addError(i18n.MsgImportNameRequired)
+		} else if utf8.RuneCountInString(row.Name) > 100 {
+			addError(i18n.MsgImportNameTooLong)
+		}
+
+		switch {
+		case row.Email == "":
+			addError(i18n.MsgImportEmailRequired)
+		case importValidator.Var(row.Email, "email") != nil:
+			addError(i18n.MsgImportEmailInvalid, row.Email)
+		default:
+			key := strings.ToLower(row.Email)
+			if first, ok := firstRow[key]; ok {
+				addError(i18n.MsgImportEmailDuplicate, first)
+				break
+			}
+			firstRow[key] = row.Row
+			_, err := s.userService.GetUserByEmail(ctx, row.Email)
+			if err == nil {
+				addError(i18n.MsgImportEmailTaken)
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		}
+
+		if entry.password == "" {
+			addError(i18n.MsgImportPasswordRequired)
+		} else if utf8.RuneCountInString(entry.password) < 6 {
+			addError(i18n.MsgImportPasswordTooShort)
+		}
+
+		for _, name := range row.Roles {
+			id, ok := roleIDs[name]
+			if !ok {
+				role, err := s.roleService.GetRoleByName(ctx, name)
+				switch {
+				case err == nil:
+					id = role.ID
+				case !errors.Is(err, gorm.ErrRecordNotFound):
+					return err
+				}
+				roleIDs[name] = id
+			}
+			if id == 0 {
+				addError(i18n.MsgImportRoleNotFound, name)
+				continue
+			}
+			entry.roleIDs = append(entry.roleIDs, id)
+		}
+	}
+	return nil
+}
+
+// applyImport 在一个事务中逐行创建用户，再按角色批量分配，任一行失败时全部回滚并在该行记录错误
+// 密码哈希在开启事务前并发计算
+func (s *userTransferService) applyImport(ctx context.Context, entries []*userImportEntry) error {
+	passwords := make([]string, len(entries))
+	for i, entry := range entries {
+		passwords[i] = entry.password
+	}
+	hashed, hashErrs := HashPasswords(passwords, func(int) bool { return true })
+	for i, err := range hashErrs {
+		if err != nil {
+			entries[i].report.Errors = append(entries[i].report.Errors, apperr.Localize(err, i18n.FromContext(ctx)))
+			return apperr.ErrImportFailed.WithMessage(i18n.MsgImportRowFailed, entries[i].report.Row, err).Wrap(err)
+		}
+	}
+
+	return s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		var roleOrder []uint
+		roleUsers := make(map[uint][]uint)
+		for i, entry := range entries {
+			user, err := s.userService.CreateUserWithHash(ctx, entry.report.Name, entry.report.Email, hashed[i])
+			if err != nil {
+				entry.report.Errors = append(entry.report.Errors, apperr.Localize(err, i18n.FromContext(ctx)))
+				return apperr.ErrImportFailed.WithMessage(i18n.MsgImportRowFailed, entry.report.Row, err).Wrap(err)
+			}
+			entry.report.UserID = user.ID
+			for _, roleID := range entry.roleIDs {
+				if _, ok := roleUsers[roleID]; !ok {
+					roleOrder = append(roleOrder, roleID)
+				}
+				roleUsers[roleID] = append(roleUsers[roleID], user.ID)
+			}
+		}
+		for _, roleID := range roleOrder {
+			if err := s.roleService.AssignUsers(ctx, roleID, roleUsers[roleID]); err != nil {
+				return apperr.ErrImportFailed.WithMessage(i18n.MsgImportAssignFailed, err).Wrap(err)
+			}
+		}
+		return nil
+	})
+}
+
+// readImportRecords 读取导入文件的全部行（xlsx 读取第一个工作表）
+func readImportRecords(format string, r io.Reader) ([][]string, error) {
+	switch format {
+	case ExportFormatCSV:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		// 去掉 Excel 另存为 CSV 时带有的 UTF-8 BOM
+		data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, apperr.ErrImportFile.WithMessage(i18n.MsgImportFileParse, err).Wrap(err)
+		}
+		return records, nil
+	case ExportFormatXLSX:
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, apperr.ErrImportFile.WithMessage(i18n.MsgImportFileParse, err).Wrap(err)
+		}
+		defer f.Close()
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, apperr.ErrImportFile.WithMessage(i18n.MsgImportNoSheet)
+		}
+		records, err := f.GetRows(sheets[0])
+		if err != nil {
+			return nil, apperr.ErrImportFile.WithMessage(i18n.MsgImportFileParse, err).Wrap(err)
+		}
+		return records, nil
+	default:
+		return nil, apperr.ErrUnsupportedFormat.WithMessage(i18n.MsgImportFormat, format)
+	}
+}
+
+// importColumnIndex 按表头名称（不区分大小写）定位导入列，name、email、password 为必需列
+func importColumnIndex(header []string) (map[string]int, error) {
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		for _, column := range userImportColumns {
+			if name == column {
+				if _, ok := columns[name]; ok {
+					return nil, apperr.ErrImportFile.WithMessage(i18n.MsgImportHeaderDuplicate, name)
+				}
+				columns[name] = i
+			}
+		}
+	}
+	var missing []string
+	for _, column := range userImportColumns[:3] {
+		if _, ok := columns[column]; !ok {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, apperr.ErrImportFile.WithMessage(i18n.MsgImportHeaderMissing, strings.Join(missing, ", "))
+	}
+	return columns, nil
+}
+
+// blankRecord 判断是否为空行
+func blankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// splitRoleNames 拆分角色名称，支持 ; 和 , 分隔（包括中文标点），去掉空项和重复项
+func splitRoleNames(raw string) []string {
+	names := []string{}
+	seen := make(map[string]bool)
+	for _, name := range strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ';' || r == ',' || r == '；' || r == '，'
+	}) {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
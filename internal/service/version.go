@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go_web/internal/apperr"
+	"go_web/internal/database"
+	"go_web/internal/i18n"
+
+	"gorm.io/gorm"
+)
+
+// forWrite 返回使用主库读取的 context：读取结果决定后续写入（唯一性检查、读取-修改-写入）时，
+// 不能读到只读副本上落后的数据，否则检查通过后写入才被主库的唯一索引或版本号拒绝
+func forWrite(ctx context.Context) context.Context {
+	return database.UsePrimary(ctx)
+}
+
+// checkVersion 校验客户端持有的版本号，expected 为 0 表示不校验
+func checkVersion(expected, current uint) error {
+	if expected != 0 && expected != current {
+		return apperr.ErrVersionMismatch.WithMessage(i18n.MsgVersionMismatchAt, current)
+	}
+	return nil
+}
+
+// deleteVersionError 按版本号删除时版本不一致说明客户端持有的版本号已过期
+func deleteVersionError(err error) error {
+	if errors.Is(err, apperr.ErrVersionConflict) {
+		return apperr.ErrVersionMismatch
+	}
+	return err
+}
+
+// duplicated 将唯一索引冲突转换为 target：先查询再写入的检查在并发时可能都通过，由唯一索引拦截后一个写入
+func duplicated(err error, target *apperr.Error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return target.Wrap(err)
+	}
+	return err
+}
+
+// notFound 将记录不存在的错误转换为 target，原错误作为内部原因保留，仍可以用 errors.Is 判断 gorm.ErrRecordNotFound
+func notFound(err error, target *apperr.Error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return target.Wrap(err)
+	}
+	return err
+}
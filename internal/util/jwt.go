@@ -1,6 +1,8 @@
 package util
 
 import (
+	"errors"
+	"sync"
 	"time"
 
 	"go_web/internal/config"
@@ -15,10 +17,15 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
-// GenerateToken 生成 JWT token
+// GenerateToken 生成 JWT token，有效期为配置的 jwt.expire_time
 func GenerateToken(cfg *config.Config, userID uint, email string) (string, error) {
+	return GenerateTokenWithTTL(cfg, userID, email, time.Duration(cfg.JWT.ExpireTime)*time.Minute)
+}
+
+// GenerateTokenWithTTL 生成指定有效期的 JWT token
+func GenerateTokenWithTTL(cfg *config.Config, userID uint, email string, ttl time.Duration) (string, error) {
 	nowTime := time.Now()
-	expireTime := nowTime.Add(time.Duration(cfg.JWT.ExpireTime) * time.Minute)
+	expireTime := nowTime.Add(ttl)
 
 	claims := Claims{
 		UserID: userID,
@@ -31,14 +38,19 @@ func GenerateToken(cfg *config.Config, userID uint, email string) (string, error
 	}
 
 	tokenClaims := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	token, err := tokenClaims.SignedString([]byte(cfg.JWT.Secret))
+	token, err := tokenClaims.SignedString([]byte(cfg.JWT.Secret.Value()))
 	return token, err
 }
 
 // ParseToken 解析 JWT token
 func ParseToken(cfg *config.Config, token string) (*Claims, error) {
+	return parseTokenWithSecret(token, cfg.JWT.Secret.Value())
+}
+
+// parseTokenWithSecret 使用指定密钥解析 JWT token
+func parseTokenWithSecret(token, secret string) (*Claims, error) {
 	tokenClaims, err := jwt.ParseWithClaims(token, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(cfg.JWT.Secret), nil
+		return []byte(secret), nil
 	})
 
 	if tokenClaims != nil {
@@ -49,3 +61,58 @@ func ParseToken(cfg *config.Config, token string) (*Claims, error) {
 
 	return nil, err
 }
+
+// JWTKeyring 跟踪 JWT 密钥的轮换
+// jwt.secret 热更新后，旧密钥在一个 jwt.expire_time 内仍可用于校验，使轮换前签发的 token 自然过期，而不是同时失效
+type JWTKeyring struct {
+	configManager *config.Manager
+
+	mu            sync.RWMutex
+	current       string
+	currentTTL    time.Duration
+	previous      string
+	previousUntil time.Time
+}
+
+// NewJWTKeyring 创建密钥环并订阅配置变更
+func NewJWTKeyring(configManager *config.Manager) *JWTKeyring {
+	cfg := configManager.Current()
+	k := &JWTKeyring{
+		configManager: configManager,
+		current:       cfg.JWT.Secret.Value(),
+		currentTTL:    time.Duration(cfg.JWT.ExpireTime) * time.Minute,
+	}
+	configManager.Subscribe(k.rotate)
+	return k
+}
+
+// rotate 配置变更时记录被替换的密钥，保留时长为旧配置的 token 有效期
+func (k *JWTKeyring) rotate(cfg *config.Config) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	secret := cfg.JWT.Secret.Value()
+	if secret != k.current {
+		k.previous = k.current
+		k.previousUntil = time.Now().Add(k.currentTTL)
+		k.current = secret
+	}
+	k.currentTTL = time.Duration(cfg.JWT.ExpireTime) * time.Minute
+}
+
+// Parse 使用当前密钥解析 JWT token，签名不匹配时再尝试仍在保留期内的旧密钥
+func (k *JWTKeyring) Parse(token string) (*Claims, error) {
+	cfg := k.configManager.Current()
+	claims, err := ParseToken(cfg, token)
+	if err == nil || !errors.Is(err, jwt.ErrSignatureInvalid) {
+		return claims, err
+	}
+
+	k.mu.RLock()
+	previous, until := k.previous, k.previousUntil
+	k.mu.RUnlock()
+	if previous == "" || previous == cfg.JWT.Secret.Value() || time.Now().After(until) {
+		return nil, err
+	}
+	return parseTokenWithSecret(token, previous)
+}
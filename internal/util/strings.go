@@ -0,0 +1,14 @@
+package util
+
+import "unicode/utf8"
+
+// Truncate 将字符串截断到 max 字节以内，用于写入有长度限制的数据库列；不拆分多字节字符，避免产生 PostgreSQL 拒绝的非法 UTF-8
+func Truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
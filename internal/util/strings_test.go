@@ -0,0 +1,34 @@
+package util
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		max   int
+		want  string
+	}{
+		{name: "short", value: "/api/v1/users", max: 255, want: "/api/v1/users"},
+		{name: "exact", value: "abc", max: 3, want: "abc"},
+		{name: "ascii", value: strings.Repeat("x", 300), max: 255, want: strings.Repeat("x", 255)},
+		{name: "multi-byte boundary", value: strings.Repeat("浏览器", 100), max: 255, want: strings.Repeat("浏览器", 28) + "浏"},
+		{name: "inside multi-byte", value: "a浏览器", max: 3, want: "a"},
+		{name: "zero", value: "浏", max: 0, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Truncate(tt.value, tt.max)
+			if got != tt.want {
+				t.Errorf("Truncate() = %q, want %q", got, tt.want)
+			}
+			if len(got) > tt.max || !utf8.ValidString(got) {
+				t.Errorf("Truncate() = %q, want valid UTF-8 within %d bytes", got, tt.max)
+			}
+		})
+	}
+}
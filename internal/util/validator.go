@@ -0,0 +1,16 @@
+package util
+
+import (
+	"go_web/internal/apperr"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// UseJSONFieldNames 让请求体校验错误使用 JSON 字段名（如 email）而不是结构体字段名（如 Email），
+// 使 details 中的 field 与请求体一致，需要在注册路由前调用
+func UseJSONFieldNames() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(apperr.JSONFieldName)
+	}
+}
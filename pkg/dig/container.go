@@ -6,14 +6,12 @@ import (
 	"go_web/internal/handler"
 	"go_web/internal/logger"
 	"go_web/internal/middleware"
-	"go_web/internal/model"
 	"go_web/internal/repository"
 	"go_web/internal/router"
 	"go_web/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/dig"
-	"gorm.io/gorm"
 )
 
 // Container 依赖注入容器
@@ -22,78 +20,123 @@ type Container struct {
 }
 
 // NewContainer 创建新的依赖注入容器
-func NewContainer() *Container {
+// 配置在创建容器前加载并校验，保证启动时即可发现全部配置问题
+func NewContainer(configManager *config.Manager) *Container {
 	c := dig.New()
 
-	// 提供配置
-	c.Provide(config.LoadConfig)
+	// 提供配置管理器和启动时的配置
+	// 需要热更新的组件应依赖 *config.Manager 读取最新配置
+	c.Provide(func() *config.Manager { return configManager })
+	c.Provide(configManager.Current)
 
-	// 提供请求日志Logger
-	c.Provide(logger.NewLogger)
+	// 提供请求日志Logger（订阅配置变更以热更新日志级别和格式）
+	c.Provide(func(configManager *config.Manager) *logger.Logger {
+		log := logger.NewLogger(configManager.Current())
+		configManager.Subscribe(log.ApplyConfig)
+		return log
+	})
 
 	// 提供审计日志Logger（使用命名参数区分）
-	c.Provide(func(cfg *config.Config) *logger.Logger {
-		return logger.NewAuditLogger(cfg)
+	c.Provide(func(configManager *config.Manager) *logger.Logger {
+		log := logger.NewAuditLogger(configManager.Current())
+		configManager.Subscribe(log.ApplyConfig)
+		return log
 	}, dig.Name("auditLogger"))
 
-	// 提供数据库
+	// 提供审计日志写入器
+	c.Provide(database.NewAuditWriter)
+
+	// 提供数据库（含只读副本）、迁移执行器和种子数据同步器
+	c.Provide(database.NewReplicaResolver)
 	c.Provide(database.NewDatabase)
+	c.Provide(database.NewMigrator)
+	c.Provide(database.NewSeeder)
 
 	// 提供Repository
 	c.Provide(repository.NewUserRepository)
 	c.Provide(repository.NewRoleRepository)
 	c.Provide(repository.NewPermissionRepository)
+	c.Provide(repository.NewAuditLogRepository)
+	c.Provide(repository.NewSecurityEventRepository)
+	c.Provide(repository.NewTransactor)
 
 	// 提供Service
+	c.Provide(service.NewUserLanguageCache)
 	c.Provide(service.NewUserService)
 	c.Provide(service.NewRoleService)
+	c.Provide(service.NewUserTransferService)
 	c.Provide(service.NewPermissionService)
+	c.Provide(service.NewAuditLogService)
+	c.Provide(service.NewSecurityEventService)
+
+	// 提供安全告警通知器（可替换为其他 AlertNotifier 实现）
+	c.Provide(service.NewAlertNotifier)
 
 	// 提供Handler
 	c.Provide(handler.NewUserHandler)
 	c.Provide(handler.NewRoleHandler)
 	c.Provide(handler.NewPermissionHandler)
 	c.Provide(handler.NewAuthHandler)
+	c.Provide(handler.NewAuditLogHandler)
+	c.Provide(handler.NewSecurityEventHandler)
 
 	// 提供中间件（使用命名参数区分）
 	c.Provide(func(log *logger.Logger) gin.HandlerFunc {
 		return middleware.LoggerMiddleware(log)
 	}, dig.Name("logger"))
 
+	// 请求ID中间件
+	c.Provide(func() gin.HandlerFunc {
+		return middleware.RequestIDMiddleware()
+	}, dig.Name("requestID"))
+
+	// 语言协商中间件
+	c.Provide(func() gin.HandlerFunc {
+		return middleware.LocaleMiddleware()
+	}, dig.Name("locale"))
+
+	// 读己之写中间件
+	c.Provide(func() gin.HandlerFunc {
+		return middleware.ReadYourWritesMiddleware()
+	}, dig.Name("readYourWrites"))
+
 	// 审计中间件参数结构体
 	type AuditMiddlewareParams struct {
 		dig.In
 		AuditLogger *logger.Logger `name:"auditLogger"`
+		AuditWriter *database.AuditWriter
 	}
 	c.Provide(func(params AuditMiddlewareParams) gin.HandlerFunc {
-		return middleware.AuditMiddleware(params.AuditLogger)
+		return middleware.AuditMiddleware(params.AuditLogger, params.AuditWriter)
 	}, dig.Name("audit"))
 
+	// 安全事件中间件
+	c.Provide(func(securityEventService service.SecurityEventService) gin.HandlerFunc {
+		return middleware.SecurityEventMiddleware(securityEventService)
+	}, dig.Name("security"))
+
+	// 跨域中间件
+	c.Provide(func(configManager *config.Manager) gin.HandlerFunc {
+		return middleware.CORSMiddleware(configManager)
+	}, dig.Name("cors"))
+
+	// 限流中间件
+	c.Provide(func(configManager *config.Manager) gin.HandlerFunc {
+		return middleware.RateLimitMiddleware(configManager)
+	}, dig.Name("rateLimit"))
+
 	// JWT 认证中间件
-	c.Provide(func(cfg *config.Config) gin.HandlerFunc {
-		return middleware.JWTAuthMiddleware(cfg)
+	c.Provide(func(configManager *config.Manager) gin.HandlerFunc {
+		return middleware.JWTAuthMiddleware(configManager)
 	}, dig.Name("jwt"))
 
+	// 用户语言偏好中间件
+	c.Provide(func(userService service.UserService) gin.HandlerFunc {
+		return middleware.UserLocaleMiddleware(userService)
+	}, dig.Name("userLocale"))
+
 	// 提供路由
 	c.Provide(router.SetupRouter)
 
 	return &Container{Container: c}
 }
-
-// InitializeDatabase 初始化数据库表
-// 注意：生产环境应禁用自动迁移，使用专门的迁移工具（如 golang-migrate）
-func InitializeDatabase(db *gorm.DB, cfg *config.Config) error {
-	// 只在配置允许时执行迁移
-	if !cfg.Database.AutoMigrate {
-		return nil
-	}
-
-	return db.AutoMigrate(
-		&model.User{},
-		&model.Role{},
-		&model.Permission{},
-		&model.UserRole{},
-		&model.RolePermission{},
-		&database.AuditLog{},
-	)
-}